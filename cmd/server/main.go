@@ -41,7 +41,12 @@ func main() {
 		return
 	}
 
-	srv := server.NewServer(cfg, s)
+	srv, err := server.NewServer(cfg, s)
+	if err != nil {
+		slog.Error("error init server", slog.String("error", err.Error()))
+		return
+	}
+
 	err = srv.Start(ctx)
 	if err != nil {
 		if errors.Is(err, http.ErrServerClosed) {
@@ -64,9 +69,10 @@ func initializeStore(ctx context.Context, cfg *config.Config) (store.Store, erro
 		path = cfg.DatabaseDSN
 	}
 	newStore, err := store.NewStore(ctx, &storage.Config{
-		Path:     path,
-		Type:     storeType,
-		Interval: cfg.StoreInternal,
+		Path:             path,
+		Type:             storeType,
+		Interval:         cfg.StoreInternal,
+		HistoryRetention: cfg.HistoryRetention,
 	})
 	if err != nil {
 		return nil, err
@@ -14,6 +14,7 @@ import (
 	"github.com/e1m0re/grdn/internal/service/apiclient"
 	"github.com/e1m0re/grdn/internal/service/encryption"
 	"github.com/e1m0re/grdn/internal/service/monitor"
+	"github.com/e1m0re/grdn/internal/service/spool"
 	"github.com/e1m0re/grdn/internal/utils"
 )
 
@@ -30,6 +31,7 @@ type app struct {
 	cfg       *config.Config
 	monitor   monitor.Monitor
 	encryptor encryption.Encryptor
+	spool     spool.Spool
 }
 
 // Start runs client application.
@@ -37,11 +39,7 @@ func (app *app) Start(ctx context.Context) error {
 	grp, ctx := errgroup.WithContext(ctx)
 
 	grp.Go(func() error {
-		return app.updateDataWorker(ctx)
-	})
-
-	grp.Go(func() error {
-		return app.updateGOPSDataWorker(ctx)
+		return app.monitor.Start(ctx)
 	})
 
 	tasksQueue := make(chan contentType, 10)
@@ -65,32 +63,14 @@ func (app *app) Start(ctx context.Context) error {
 		})
 	}
 
-	return grp.Wait()
-}
-
-func (app *app) updateDataWorker(ctx context.Context) error {
-	for {
-		select {
-		case <-ctx.Done():
+	if app.spool != nil {
+		grp.Go(func() error {
+			app.replaySpool(ctx)
 			return nil
-		case <-time.After(app.cfg.PollInterval):
-			app.monitor.UpdateData()
-		}
+		})
 	}
-}
 
-func (app *app) updateGOPSDataWorker(ctx context.Context) error {
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		case <-time.After(app.cfg.PollInterval):
-			err := app.monitor.UpdateGOPS(ctx)
-			if err != nil {
-				slog.Error("error update GOPS data", slog.String("error", err.Error()))
-			}
-		}
-	}
+	return grp.Wait()
 }
 
 func (app *app) sendDataToServer(outChan chan<- contentType) {
@@ -122,17 +102,63 @@ func (app *app) sendDataToServerWorker(ctx context.Context, tasksQueue <-chan co
 			if !ok {
 				return nil
 			}
-			err := utils.RetryFunc(ctx, func() error {
-				return app.apiClient.SendMetricsData(&c)
-			})
+			app.sendPayload(ctx, c)
+		}
+	}
+}
+
+// sendPayload sends the payload to the server. If the spool is enabled, the payload failed to send is queued to be
+// replayed later. While the spool is not empty, payloads are queued behind the ones queued before, so the server
+// receives them in order.
+func (app *app) sendPayload(ctx context.Context, c contentType) {
+	if app.spool != nil && app.spool.Len() > 0 {
+		app.pushPayload(c)
+		return
+	}
+
+	err := utils.RetryFunc(ctx, func() error {
+		return app.apiClient.SendMetricsData(&c)
+	})
+
+	if err != nil {
+		slog.Error("send metrics data failed", slog.String("error", err.Error()))
+		app.pushPayload(c)
+	}
+}
 
+// replaySpool replays queued payloads on the report interval until the context is done. The spool is replayed from
+// this goroutine only, so payloads are not sent concurrently by several workers. Payloads contain increments of
+// counters since the previous report, so the server adds replayed ones without double counting.
+func (app *app) replaySpool(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(app.cfg.ReportInterval):
+			if app.spool.Len() == 0 {
+				continue
+			}
+
+			err := app.spool.Replay(ctx, func(payload []byte) error {
+				return app.apiClient.SendMetricsData(&payload)
+			})
 			if err != nil {
-				slog.Error("send metrics data failed", slog.String("error", err.Error()))
+				slog.Error("replay of queued metrics data failed", slog.String("error", err.Error()))
 			}
 		}
 	}
 }
 
+func (app *app) pushPayload(c contentType) {
+	if app.spool == nil {
+		return
+	}
+
+	if err := app.spool.Push(c); err != nil {
+		slog.Error("queueing metrics data failed", slog.String("error", err.Error()))
+	}
+}
+
 // NewApp is app constructor.
 func NewApp(cfg *config.Config, services *service.AgentServices) App {
 	return &app{
@@ -140,5 +166,6 @@ func NewApp(cfg *config.Config, services *service.AgentServices) App {
 		cfg:       cfg,
 		monitor:   services.Monitor,
 		encryptor: services.Encryptor,
+		spool:     services.Spool,
 	}
 }
@@ -16,6 +16,7 @@ import (
 	mocks3 "github.com/e1m0re/grdn/internal/service/apiclient/mocks"
 	mocks2 "github.com/e1m0re/grdn/internal/service/encryption/mocks"
 	"github.com/e1m0re/grdn/internal/service/monitor/mocks"
+	mocks4 "github.com/e1m0re/grdn/internal/service/spool/mocks"
 )
 
 func TestApp_updateDataWorker(t *testing.T) {
@@ -39,8 +40,7 @@ func TestApp_updateDataWorker(t *testing.T) {
 				mockAPIClient.On("SendMetricsData", &[]byte{}).Return(nil).Maybe()
 
 				mockMonitor := mocks.NewMonitor(t)
-				mockMonitor.On("UpdateData", mock.Anything).Return(nil)
-				mockMonitor.On("UpdateGOPS", mock.Anything).Return(nil)
+				mockMonitor.On("Start", mock.Anything).Return(nil)
 				mockMonitor.On("GetMetricsList").Return(make(models.MetricsList, 0))
 
 				mockEncryptor := mocks2.NewEncryptor(t)
@@ -67,14 +67,13 @@ func TestApp_updateDataWorker(t *testing.T) {
 			},
 		},
 		{
-			name: "UpdateGOPS failed",
+			name: "default rate limit",
 			mockApp: func() *app {
 				mockAPIClient := mocks3.NewAPIClient(t)
 				mockAPIClient.On("SendMetricsData", &[]byte{}).Return(nil).Maybe()
 
 				mockMonitor := mocks.NewMonitor(t)
-				mockMonitor.On("UpdateData", mock.Anything).Return(nil)
-				mockMonitor.On("UpdateGOPS", mock.Anything).Return(errors.New("something wrong"))
+				mockMonitor.On("Start", mock.Anything).Return(nil)
 				mockMonitor.On("GetMetricsList").Return(make(models.MetricsList, 0))
 
 				mockEncryptor := mocks2.NewEncryptor(t)
@@ -104,8 +103,7 @@ func TestApp_updateDataWorker(t *testing.T) {
 				mockAPIClient.On("SendMetricsData", &[]byte{}).Return(nil).Maybe()
 
 				mockMonitor := mocks.NewMonitor(t)
-				mockMonitor.On("UpdateData", mock.Anything).Return(nil)
-				mockMonitor.On("UpdateGOPS", mock.Anything).Return(nil)
+				mockMonitor.On("Start", mock.Anything).Return(nil)
 				mockMonitor.On("GetMetricsList").Return(make(models.MetricsList, 0))
 
 				mockEncryptor := mocks2.NewEncryptor(t)
@@ -148,10 +146,122 @@ func TestApp_updateDataWorker(t *testing.T) {
 	}
 }
 
+func TestApp_sendPayload(t *testing.T) {
+	payload := []byte("payload")
+	errSomethingWrong := errors.New("something wrong")
+
+	type args struct {
+		ctx     context.Context
+		payload []byte
+	}
+	tests := []struct {
+		mockApp func() *app
+		name    string
+		args    args
+	}{
+		{
+			name: "spool is disabled",
+			mockApp: func() *app {
+				mockAPIClient := mocks3.NewAPIClient(t)
+				mockAPIClient.On("SendMetricsData", &payload).Return(nil).Once()
+
+				return &app{apiClient: mockAPIClient}
+			},
+			args: args{ctx: context.Background(), payload: payload},
+		},
+		{
+			name: "spool is empty",
+			mockApp: func() *app {
+				mockAPIClient := mocks3.NewAPIClient(t)
+				mockAPIClient.On("SendMetricsData", &payload).Return(nil).Once()
+
+				mockSpool := mocks4.NewSpool(t)
+				mockSpool.On("Len").Return(0)
+
+				return &app{apiClient: mockAPIClient, spool: mockSpool}
+			},
+			args: args{ctx: context.Background(), payload: payload},
+		},
+		{
+			name: "spool is not empty, the payload is queued behind queued payloads",
+			mockApp: func() *app {
+				mockAPIClient := mocks3.NewAPIClient(t)
+
+				mockSpool := mocks4.NewSpool(t)
+				mockSpool.On("Len").Return(2)
+				mockSpool.On("Push", payload).Return(nil).Once()
+
+				return &app{apiClient: mockAPIClient, spool: mockSpool}
+			},
+			args: args{ctx: context.Background(), payload: payload},
+		},
+		{
+			name: "sending failed, the payload is queued",
+			mockApp: func() *app {
+				mockAPIClient := mocks3.NewAPIClient(t)
+				mockAPIClient.On("SendMetricsData", &payload).Return(errSomethingWrong).Maybe()
+
+				mockSpool := mocks4.NewSpool(t)
+				mockSpool.On("Len").Return(0)
+				mockSpool.On("Push", payload).Return(errSomethingWrong).Once()
+
+				return &app{apiClient: mockAPIClient, spool: mockSpool}
+			},
+			args: args{
+				ctx: func() context.Context {
+					ctx, cancel := context.WithCancel(context.Background())
+					cancel()
+					return ctx
+				}(),
+				payload: payload,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := test.mockApp()
+			app.sendPayload(test.args.ctx, test.args.payload)
+		})
+	}
+}
+
+func TestApp_replaySpool(t *testing.T) {
+	payload := []byte("payload")
+
+	mockAPIClient := mocks3.NewAPIClient(t)
+	mockAPIClient.On("SendMetricsData", &payload).Return(nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mockSpool := mocks4.NewSpool(t)
+	mockSpool.On("Len").Return(0).Once()
+	mockSpool.On("Len").Return(1).Once()
+	mockSpool.
+		On("Replay", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			send := args.Get(1).(func(payload []byte) error)
+			require.NoError(t, send(payload))
+			cancel()
+		}).
+		Return(nil).
+		Once()
+
+	app := &app{
+		apiClient: mockAPIClient,
+		cfg:       &config.Config{ReportInterval: time.Millisecond},
+		spool:     mockSpool,
+	}
+	app.replaySpool(ctx)
+}
+
 func TestNewApp(t *testing.T) {
 	cfg := &config.Config{}
 	services, err := service.NewAgentServices(cfg)
 	require.Nil(t, err)
 	app := NewApp(cfg, services)
 	assert.Implements(t, (*App)(nil), app)
+
+	cfg = &config.Config{SpoolDir: t.TempDir()}
+	services, err = service.NewAgentServices(cfg)
+	require.Nil(t, err)
+	require.NotNil(t, services.Spool)
 }
@@ -4,8 +4,10 @@ package config
 import (
 	"encoding/json"
 	"flag"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,25 +16,118 @@ const (
 	defaultReportInterval = 10
 	defaultPollInterval   = 2
 	defaultKey            = ""
+	defaultKeyID          = ""
+	defaultAgentID        = ""
+	defaultToken          = ""
+	defaultVerifyResponse = false
 	defaultRateLimit      = 1
 	defaultPublicKey      = ""
+	defaultSpoolDir       = ""
+	defaultSpoolMaxSize   = 64 << 20
+	defaultSpoolMaxAge    = 24 * time.Hour
+	defaultTLSCAFile      = ""
+	defaultTLSCertFile    = ""
+	defaultTLSKeyFile     = ""
+	defaultTransport      = TransportHTTP
 
 	envConfigFileName     = "CONFIG"
 	envServerAddrName     = "ADDRESS"
 	envReportIntervalName = "REPORT_INTERVAL"
 	envPollInterval       = "POLL_INTERVAL"
 	envKeyName            = "KEY"
+	envKeyIDName          = "KEY_ID"
+	envAgentIDName        = "AGENT_ID"
+	envTokenName          = "TOKEN"
+	envVerifyResponses    = "VERIFY_RESPONSES"
 	envRateLimit          = "RATE_LIMIT"
 	envCryptoKeyName      = "CRYPTO_KEY"
+	envCollectorPrefix    = "COLLECTOR_"
+	envSpoolDirName       = "SPOOL_DIR"
+	envSpoolMaxSizeName   = "SPOOL_MAX_SIZE"
+	envSpoolMaxAgeName    = "SPOOL_MAX_AGE"
+	envTLSCAFileName      = "TLS_CA"
+	envTLSCertFileName    = "TLS_CERT"
+	envTLSKeyFileName     = "TLS_KEY"
+	envTransportName      = "TRANSPORT"
 )
 
+const (
+	// TransportHTTP is the transport sending metrics as JSON to the /updates/ endpoint of the server.
+	TransportHTTP = "http"
+	// TransportGRPC is the transport sending metrics to gRPC Metrics service of the server.
+	TransportGRPC = "grpc"
+)
+
+const (
+	// RuntimeCollector is the name of the collector of Go runtime memory statistics.
+	RuntimeCollector = "runtime"
+	// GOPSCollector is the name of the collector of host memory and CPU utilization.
+	GOPSCollector = "gopsutil"
+	// DiskCollector is the name of the collector of disk usage per mount point.
+	DiskCollector = "disk"
+	// DiskIOCollector is the name of the collector of disk IO counters per device.
+	DiskIOCollector = "diskio"
+	// NetworkCollector is the name of the collector of network counters per interface.
+	NetworkCollector = "network"
+	// LoadCollector is the name of the collector of load average and processes count.
+	LoadCollector = "load"
+	// ExecCollector is the name of the collector which runs configured commands and parses their output.
+	ExecCollector = "exec"
+)
+
+// Collectors is the list of all collectors supported by the agent.
+var Collectors = []string{RuntimeCollector, GOPSCollector, DiskCollector, DiskIOCollector, NetworkCollector, LoadCollector, ExecCollector}
+
+// defaultCollectors is the list of collectors enabled if they are not configured.
+var defaultCollectors = map[string]bool{RuntimeCollector: true, GOPSCollector: true}
+
+// CollectorConfig contains settings of the metrics collector.
+type CollectorConfig struct {
+	// Commands are shell commands run by the exec collector.
+	Commands []string `yaml:"commands"`
+	// Buckets are upper bounds of buckets of the GC pause times histogram of the runtime collector in seconds.
+	Buckets []float64 `yaml:"buckets"`
+	// PollInterval overrides poll interval of the agent for the collector if it is not zero.
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// Timeout limits execution time of each command of the exec collector.
+	Timeout time.Duration `yaml:"timeout"`
+	Enabled bool          `yaml:"enabled"`
+}
+
 type Config struct {
-	Key            string
-	PublicKeyFile  string        `yaml:"crypto_key"`
-	ServerAddr     string        `yaml:"address"`
+	Collectors    map[string]CollectorConfig `yaml:"collectors"`
+	Key           string
+	KeyID         string `yaml:"key_id"`
+	PublicKeyFile string `yaml:"crypto_key"`
+	ServerAddr    string `yaml:"address"`
+	// AgentID identifies the agent on the server which has per-agent credentials.
+	AgentID string `yaml:"agent_id"`
+	// Token is the bearer token of the agent.
+	Token string `yaml:"token"`
+	// SpoolDir is the directory of the on-disk queue of payloads failed to send. Empty value disables the queue.
+	SpoolDir string `yaml:"spool_dir"`
+	// TLSCAFile is PEM file of CA of the server certificate (empty - system roots). The agent connects to the server via
+	// HTTPS if any of TLS files is set.
+	TLSCAFile string `yaml:"tls_ca"`
+	// TLSCertFile and TLSKeyFile are PEM files of the client certificate presented to the server with mutual TLS.
+	TLSCertFile string `yaml:"tls_cert"`
+	TLSKeyFile  string `yaml:"tls_key"`
+	// Transport is the transport of metrics delivery: TransportHTTP or TransportGRPC.
+	Transport      string        `yaml:"transport"`
 	PollInterval   time.Duration `yaml:"poll_interval"`
 	ReportInterval time.Duration `yaml:"report_interval"`
 	RateLimit      int
+	// SpoolMaxSize limits total size of the queued payloads in bytes, the oldest payloads are dropped.
+	SpoolMaxSize int64 `yaml:"spool_max_size"`
+	// SpoolMaxAge limits age of the queued payloads.
+	SpoolMaxAge time.Duration `yaml:"spool_max_age"`
+	// VerifyResponses enables check of the server responses signed with the key.
+	VerifyResponses bool `yaml:"verify_responses"`
+}
+
+// TLSEnabled reports whether the agent connects to the server via HTTPS.
+func (c *Config) TLSEnabled() bool {
+	return len(c.TLSCAFile) > 0 || len(c.TLSCertFile) > 0 || len(c.TLSKeyFile) > 0
 }
 
 // InitConfig initializes the clients application configuration.
@@ -60,8 +155,20 @@ func InitConfig() (*Config, error) {
 	flag.UintVar(&reportInterval, "r", defaultReportInterval, "frequency of sending metrics to the server")
 	flag.UintVar(&pollInterval, "p", defaultPollInterval, "frequency of polling metrics from the package")
 	flag.StringVar(&config.Key, "k", defaultKey, "key to use for encryption")
+	flag.StringVar(&config.KeyID, "key-id", defaultKeyID, "ID of the sign key (empty - legacy key of the server)")
+	flag.StringVar(&config.AgentID, "agent-id", defaultAgentID, "ID of the agent (empty - anonymous agent)")
+	flag.StringVar(&config.Token, "token", defaultToken, "bearer token of the agent")
+	flag.BoolVar(&config.VerifyResponses, "verify-responses", defaultVerifyResponse, "verify sign of the server responses")
 	flag.IntVar(&config.RateLimit, "l", defaultRateLimit, "limit of threads count")
 	flag.StringVar(&config.PublicKeyFile, "crypto-key", defaultPublicKey, "public key file path")
+	flag.StringVar(&config.SpoolDir, "spool-dir", defaultSpoolDir, "directory of the queue of payloads failed to send (empty - disabled)")
+	flag.Int64Var(&config.SpoolMaxSize, "spool-max-size", defaultSpoolMaxSize, "max size of the queue of payloads failed to send in bytes")
+	flag.DurationVar(&config.SpoolMaxAge, "spool-max-age", defaultSpoolMaxAge, "max age of payloads in the queue of payloads failed to send")
+	flag.StringVar(&config.TLSCAFile, "tls-ca", defaultTLSCAFile, "CA file path of the server certificate (empty - system roots)")
+	flag.StringVar(&config.TLSCertFile, "tls-cert", defaultTLSCertFile, "client certificate file path for mutual TLS")
+	flag.StringVar(&config.TLSKeyFile, "tls-key", defaultTLSKeyFile, "client certificate key file path for mutual TLS")
+	flag.StringVar(&config.Transport, "transport", defaultTransport, fmt.Sprintf("transport of metrics delivery (%s or %s)", TransportHTTP, TransportGRPC))
+	collectors := collectorsFlags(config.Collectors)
 	flag.Parse()
 
 	if envServerAddr := os.Getenv(envServerAddrName); envServerAddr != "" {
@@ -88,6 +195,22 @@ func InitConfig() (*Config, error) {
 		config.Key = envKey
 	}
 
+	if envKeyID := os.Getenv(envKeyIDName); envKeyID != "" {
+		config.KeyID = envKeyID
+	}
+
+	if envAgentID := os.Getenv(envAgentIDName); envAgentID != "" {
+		config.AgentID = envAgentID
+	}
+
+	if envToken := os.Getenv(envTokenName); envToken != "" {
+		config.Token = envToken
+	}
+
+	if envVerify := os.Getenv(envVerifyResponses); envVerify != "" {
+		config.VerifyResponses = envVerify == "true"
+	}
+
 	if envRateLimit := os.Getenv(envRateLimit); envRateLimit != "" {
 		envValue, err := strconv.Atoi(envRateLimit)
 		if err == nil {
@@ -102,9 +225,150 @@ func InitConfig() (*Config, error) {
 		config.PublicKeyFile = envCryptoKey
 	}
 
+	if envSpoolDir := os.Getenv(envSpoolDirName); envSpoolDir != "" {
+		config.SpoolDir = envSpoolDir
+	}
+
+	if envSpoolMaxSize := os.Getenv(envSpoolMaxSizeName); envSpoolMaxSize != "" {
+		value, err := strconv.ParseInt(envSpoolMaxSize, 10, 64)
+		if err == nil {
+			config.SpoolMaxSize = value
+		}
+	}
+
+	if envSpoolMaxAge := os.Getenv(envSpoolMaxAgeName); envSpoolMaxAge != "" {
+		value, err := time.ParseDuration(envSpoolMaxAge)
+		if err == nil {
+			config.SpoolMaxAge = value
+		}
+	}
+
+	if envTLSCAFile := os.Getenv(envTLSCAFileName); envTLSCAFile != "" {
+		config.TLSCAFile = envTLSCAFile
+	}
+
+	if envTLSCertFile := os.Getenv(envTLSCertFileName); envTLSCertFile != "" {
+		config.TLSCertFile = envTLSCertFile
+	}
+
+	if envTLSKeyFile := os.Getenv(envTLSKeyFileName); envTLSKeyFile != "" {
+		config.TLSKeyFile = envTLSKeyFile
+	}
+
+	if envTransport := os.Getenv(envTransportName); envTransport != "" {
+		config.Transport = envTransport
+	}
+
+	config.Collectors = make(map[string]CollectorConfig, len(collectors))
+	for name, c := range collectors {
+		envName := envCollectorPrefix + strings.ToUpper(name)
+		if envEnabled := os.Getenv(envName); envEnabled != "" {
+			c.Enabled = envEnabled == "true"
+		}
+
+		if envInterval := os.Getenv(envName + "_INTERVAL"); envInterval != "" {
+			value, err := time.ParseDuration(envInterval)
+			if err == nil {
+				c.PollInterval = value
+			}
+		}
+
+		if envCommands := os.Getenv(envName + "_COMMANDS"); envCommands != "" {
+			c.Commands = splitCommands(envCommands)
+		}
+
+		if envBuckets := os.Getenv(envName + "_BUCKETS"); envBuckets != "" {
+			value, err := parseBuckets(envBuckets)
+			if err == nil {
+				c.Buckets = value
+			}
+		}
+
+		if envTimeout := os.Getenv(envName + "_TIMEOUT"); envTimeout != "" {
+			value, err := time.ParseDuration(envTimeout)
+			if err == nil {
+				c.Timeout = value
+			}
+		}
+
+		config.Collectors[name] = *c
+	}
+
 	return &config, nil
 }
 
+// collectorsFlags defines enable flag and poll interval flag for each supported collector.
+// Values from the config file are used as defaults, collectors missing in the file are enabled if they are default.
+func collectorsFlags(fileConfig map[string]CollectorConfig) map[string]*CollectorConfig {
+	result := make(map[string]*CollectorConfig, len(Collectors))
+	for _, name := range Collectors {
+		c, ok := fileConfig[name]
+		if !ok {
+			c = CollectorConfig{Enabled: defaultCollectors[name]}
+		}
+
+		result[name] = &c
+		flag.BoolVar(&c.Enabled, "collector."+name, c.Enabled, fmt.Sprintf("enable %s collector", name))
+		flag.DurationVar(&c.PollInterval, "collector."+name+".interval", c.PollInterval, fmt.Sprintf("poll interval of %s collector (0 - use poll interval of agent)", name))
+	}
+
+	execConfig := result[ExecCollector]
+	commandsFromFlags := false
+	flag.Func("collector.exec.command", "command run by exec collector (can be repeated)", func(value string) error {
+		if !commandsFromFlags {
+			execConfig.Commands = nil
+			commandsFromFlags = true
+		}
+		execConfig.Commands = append(execConfig.Commands, value)
+		return nil
+	})
+	flag.DurationVar(&execConfig.Timeout, "collector.exec.timeout", execConfig.Timeout, "timeout of each command run by exec collector (0 - default timeout)")
+
+	runtimeConfig := result[RuntimeCollector]
+	flag.Func("collector.runtime.buckets", "comma separated upper bounds of GC pause histogram buckets in seconds", func(value string) error {
+		buckets, err := parseBuckets(value)
+		if err != nil {
+			return err
+		}
+		runtimeConfig.Buckets = buckets
+		return nil
+	})
+
+	return result
+}
+
+// splitCommands splits semicolon separated list of commands skipping empty items.
+func splitCommands(value string) []string {
+	result := make([]string, 0)
+	for _, command := range strings.Split(value, ";") {
+		command = strings.TrimSpace(command)
+		if command != "" {
+			result = append(result, command)
+		}
+	}
+
+	return result
+}
+
+// parseBuckets parses comma separated list of bucket bounds skipping empty items.
+func parseBuckets(value string) ([]float64, error) {
+	result := make([]float64, 0)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		bound, err := strconv.ParseFloat(item, 64)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, bound)
+	}
+
+	return result, nil
+}
+
 func updateConfigFromFile(c *Config, path string) error {
 	f, err := os.Open(path)
 	if err != nil {
@@ -24,19 +24,61 @@ func TestInitConfig(t *testing.T) {
 			mock: func() {
 				os.Setenv(envServerAddrName, "127.0.0.1:8081")
 				os.Setenv(envKeyName, "key")
+				os.Setenv(envKeyIDName, "2024-05")
+				os.Setenv(envAgentIDName, "web-1")
+				os.Setenv(envTokenName, "token")
+				os.Setenv(envVerifyResponses, "true")
 				os.Setenv(envCryptoKeyName, "public key")
 				os.Setenv(envPollInterval, "100")
 				os.Setenv(envReportIntervalName, "100")
 				os.Setenv(envRateLimit, "100")
+				os.Setenv("COLLECTOR_GOPSUTIL", "false")
+				os.Setenv("COLLECTOR_RUNTIME_INTERVAL", "5s")
+				os.Setenv("COLLECTOR_RUNTIME_BUCKETS", "0.0001, 0.001,,0.01")
+				os.Setenv("COLLECTOR_DISK", "true")
+				os.Setenv("COLLECTOR_EXEC", "true")
+				os.Setenv("COLLECTOR_EXEC_COMMANDS", "echo 'Users gauge 5'; ;/opt/checks/queue.sh")
+				os.Setenv("COLLECTOR_EXEC_TIMEOUT", "3s")
+				os.Setenv(envSpoolDirName, "/var/spool/agent")
+				os.Setenv(envSpoolMaxSizeName, "1024")
+				os.Setenv(envSpoolMaxAgeName, "1h")
+				os.Setenv(envTLSCAFileName, "/tmp/ca.crt")
+				os.Setenv(envTLSCertFileName, "/tmp/agent.crt")
+				os.Setenv(envTLSKeyFileName, "/tmp/agent.key")
+				os.Setenv(envTransportName, "grpc")
 			},
 			want: want{
 				cfg: &Config{
-					Key:            "key",
-					PublicKeyFile:  "public key",
-					ServerAddr:     "127.0.0.1:8081",
-					PollInterval:   time.Duration(100) * time.Second,
-					ReportInterval: time.Duration(100) * time.Second,
-					RateLimit:      100,
+					Key:             "key",
+					KeyID:           "2024-05",
+					AgentID:         "web-1",
+					Token:           "token",
+					VerifyResponses: true,
+					PublicKeyFile:   "public key",
+					ServerAddr:      "127.0.0.1:8081",
+					PollInterval:    time.Duration(100) * time.Second,
+					ReportInterval:  time.Duration(100) * time.Second,
+					RateLimit:       100,
+					SpoolDir:        "/var/spool/agent",
+					SpoolMaxSize:    1024,
+					SpoolMaxAge:     time.Hour,
+					TLSCAFile:       "/tmp/ca.crt",
+					TLSCertFile:     "/tmp/agent.crt",
+					TLSKeyFile:      "/tmp/agent.key",
+					Transport:       TransportGRPC,
+					Collectors: map[string]CollectorConfig{
+						RuntimeCollector: {Enabled: true, PollInterval: 5 * time.Second, Buckets: []float64{0.0001, 0.001, 0.01}},
+						GOPSCollector:    {Enabled: false},
+						DiskCollector:    {Enabled: true},
+						DiskIOCollector:  {Enabled: false},
+						NetworkCollector: {Enabled: false},
+						LoadCollector:    {Enabled: false},
+						ExecCollector: {
+							Enabled:  true,
+							Commands: []string{"echo 'Users gauge 5'", "/opt/checks/queue.sh"},
+							Timeout:  3 * time.Second,
+						},
+					},
 				},
 				err: nil,
 			},
@@ -51,3 +93,18 @@ func TestInitConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_TLSEnabled(t *testing.T) {
+	assert.False(t, (&Config{}).TLSEnabled())
+	assert.True(t, (&Config{TLSCAFile: "/tmp/ca.crt"}).TLSEnabled())
+	assert.True(t, (&Config{TLSCertFile: "/tmp/agent.crt", TLSKeyFile: "/tmp/agent.key"}).TLSEnabled())
+}
+
+func Test_parseBuckets(t *testing.T) {
+	got, err := parseBuckets(" 0.0001, 0.001,,0.01 ")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.0001, 0.001, 0.01}, got)
+
+	_, err = parseBuckets("0.001,1ms")
+	require.Error(t, err)
+}
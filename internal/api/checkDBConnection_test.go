@@ -87,8 +87,8 @@ func TestHandler_checkDBConnection(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			_, services := test.mockServices()
-			handler := NewHandler(services)
-			router := handler.NewRouter("", "")
+			handler := NewHandler(services, 0, nil)
+			router := handler.NewRouter(nil, 0, "", nil, false)
 
 			req, err := http.NewRequestWithContext(test.args.ctx, test.args.method, "/ping", nil)
 			require.NoError(t, err)
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service"
+	"github.com/e1m0re/grdn/internal/service/alerting/mocks"
+)
+
+func TestHandler_getAlerts(t *testing.T) {
+	activeAt := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+	value := float64(6e8)
+	type want struct {
+		expectedHeaders      map[string]string
+		expectedResponseBody string
+		expectedStatusCode   int
+	}
+	tests := []struct {
+		name         string
+		mockServices func() *service.ServerServices
+		want         want
+	}{
+		{
+			name: "No rules",
+			mockServices: func() *service.ServerServices {
+				mockAlertsManager := mocks.NewManager(t)
+				mockAlertsManager.
+					On("GetAlerts").
+					Return([]models.Alert{})
+
+				return &service.ServerServices{
+					AlertsManager: mockAlertsManager,
+				}
+			},
+			want: want{
+				expectedHeaders:      map[string]string{"Content-Type": "application/json"},
+				expectedStatusCode:   http.StatusOK,
+				expectedResponseBody: `[]`,
+			},
+		},
+		{
+			name: "Successfully case",
+			mockServices: func() *service.ServerServices {
+				mockAlertsManager := mocks.NewManager(t)
+				mockAlertsManager.
+					On("GetAlerts").
+					Return([]models.Alert{
+						{
+							Rule:  "counter PollCount > 100",
+							State: models.AlertStateInactive,
+						},
+						{
+							ActiveAt: &activeAt,
+							Value:    &value,
+							Rule:     "gauge HeapAlloc > 5e8 for 2m",
+							State:    models.AlertStatePending,
+						},
+					})
+
+				return &service.ServerServices{
+					AlertsManager: mockAlertsManager,
+				}
+			},
+			want: want{
+				expectedHeaders:    map[string]string{"Content-Type": "application/json"},
+				expectedStatusCode: http.StatusOK,
+				expectedResponseBody: `[{"rule":"counter PollCount > 100","state":"inactive"},` +
+					`{"active_at":"2024-04-11T13:00:00Z","value":600000000,"rule":"gauge HeapAlloc > 5e8 for 2m","state":"pending"}]`,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			services := test.mockServices()
+			handler := NewHandler(services, 0, nil)
+			router := handler.NewRouter(nil, 0, "", nil, false)
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/alerts", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, test.want.expectedStatusCode, rr.Code)
+			for key, value := range test.want.expectedHeaders {
+				require.Equal(t, value, rr.Header().Get(key))
+			}
+			require.JSONEq(t, test.want.expectedResponseBody, rr.Body.String())
+		})
+	}
+}
@@ -4,9 +4,17 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 )
 
+// getMainPage lists metrics having all labels passed as label query parameters.
 func (h *Handler) getMainPage(response http.ResponseWriter, request *http.Request) {
+	filter, err := parseLabels(request)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	response.Header().Set("Content-Type", "text/html")
 
 	metrics, err := h.services.MetricsManager.GetAllMetrics(request.Context())
@@ -16,8 +24,14 @@ func (h *Handler) getMainPage(response http.ResponseWriter, request *http.Reques
 		return
 	}
 
-	for _, metric := range *metrics {
-		_, err := fmt.Fprintf(response, "%s\r\n", metric.String())
+	now := time.Now()
+	for _, metric := range filterMetrics(*metrics, filter) {
+		line := metric.String()
+		if metric.IsStale(now, h.staleThreshold) {
+			line += " (stale)"
+		}
+
+		_, err := fmt.Fprintf(response, "%s\r\n", line)
 		if err != nil {
 			slog.Error(err.Error())
 			response.WriteHeader(http.StatusInternalServerError)
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -17,8 +18,10 @@ import (
 
 func TestHandler_getMainPage(t *testing.T) {
 	type args struct {
-		ctx    context.Context
-		method string
+		ctx            context.Context
+		method         string
+		query          string
+		staleThreshold time.Duration
 	}
 	type want struct {
 		expectedHeaders      map[string]string
@@ -129,14 +132,122 @@ func TestHandler_getMainPage(t *testing.T) {
 				expectedResponseBody: "metric1: 100.1\r\nmetric2: 100\r\n",
 			},
 		},
+		{
+			name: "Histograms and summaries",
+			mockServices: func() *service.ServerServices {
+				metric1 := &models.Metric{
+					Histogram: &models.Histogram{Bounds: []float64{0.001, 0.01}, Counts: []uint64{2, 1, 0}, Sum: 0.0075},
+					MType:     models.HistogramType,
+					ID:        "GCPause",
+				}
+				metric2 := &models.Metric{
+					Summary: &models.Summary{Quantiles: []models.Quantile{{Quantile: 0.5, Value: 0.2}}, Count: 3, Sum: 0.7},
+					MType:   models.SummaryType,
+					ID:      "RequestDuration",
+				}
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("GetAllMetrics", mock.Anything).
+					Return(&models.MetricsList{metric1, metric2}, nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx:    context.Background(),
+				method: http.MethodGet,
+			},
+			want: want{
+				expectedHeaders:    map[string]string{"Content-Type": "text/html"},
+				expectedStatusCode: http.StatusOK,
+				expectedResponseBody: "GCPause: count=3 sum=0.0075 buckets=0.001:2,0.01:3,+Inf:3\r\n" +
+					"RequestDuration: count=3 sum=0.7 quantiles=0.5:0.2\r\n",
+			},
+		},
+		{
+			name: "Label filter",
+			mockServices: func() *service.ServerServices {
+				value := float64(100.100)
+				metric1 := &models.Metric{
+					Value:  &value,
+					Labels: models.Labels{"cpu": "0", "host": "web1"},
+					MType:  models.GaugeType,
+					ID:     "CPUutilization",
+				}
+				metric2 := &models.Metric{
+					Value:  &value,
+					Labels: models.Labels{"cpu": "0", "host": "web2"},
+					MType:  models.GaugeType,
+					ID:     "CPUutilization",
+				}
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("GetAllMetrics", mock.Anything).
+					Return(&models.MetricsList{metric1, metric2}, nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx:    context.Background(),
+				method: http.MethodGet,
+				query:  "?label=host:web2",
+			},
+			want: want{
+				expectedHeaders:      map[string]string{"Content-Type": "text/html"},
+				expectedStatusCode:   http.StatusOK,
+				expectedResponseBody: "CPUutilization{cpu=\"0\",host=\"web2\"}: 100.1\r\n",
+			},
+		},
+		{
+			name: "Stale metrics are flagged",
+			mockServices: func() *service.ServerServices {
+				value := float64(100.100)
+				updatedAt := time.Now()
+				metric1 := &models.Metric{
+					Value:     &value,
+					Timestamp: &updatedAt,
+					MType:     models.GaugeType,
+					ID:        "metric1",
+				}
+				delta := int64(100)
+				staleAt := updatedAt.Add(-time.Hour)
+				metric2 := &models.Metric{
+					Delta:     &delta,
+					Timestamp: &staleAt,
+					MType:     models.CounterType,
+					ID:        "metric2",
+				}
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("GetAllMetrics", mock.Anything).
+					Return(&models.MetricsList{metric1, metric2}, nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx:            context.Background(),
+				method:         http.MethodGet,
+				staleThreshold: time.Minute,
+			},
+			want: want{
+				expectedHeaders:      map[string]string{"Content-Type": "text/html"},
+				expectedStatusCode:   http.StatusOK,
+				expectedResponseBody: "metric1: 100.1\r\nmetric2: 100 (stale)\r\n",
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			services := test.mockServices()
-			handler := NewHandler(services)
-			router := handler.NewRouter("", "")
+			handler := NewHandler(services, test.args.staleThreshold, nil)
+			router := handler.NewRouter(nil, 0, "", nil, false)
 
-			req, err := http.NewRequestWithContext(test.args.ctx, test.args.method, "/", nil)
+			req, err := http.NewRequestWithContext(test.args.ctx, test.args.method, "/"+test.args.query, nil)
 			require.NoError(t, err)
 
 			rr := httptest.NewRecorder()
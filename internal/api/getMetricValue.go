@@ -3,18 +3,27 @@ package api
 import (
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
 
+// getMetricValue returns the value of the metric identified by its type, name and labels passed as label query
+// parameters.
 func (h *Handler) getMetricValue(response http.ResponseWriter, request *http.Request) {
-	metric, err := h.services.MetricsManager.GetMetric(request.Context(), chi.URLParam(request, "mType"), chi.URLParam(request, "mName"))
+	labels, err := parseLabels(request)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	metric, err := h.services.MetricsManager.GetMetric(request.Context(), chi.URLParam(request, "mType"), chi.URLParam(request, "mName"), labels)
 	if err != nil {
 		slog.Error(err.Error())
 		http.Error(response, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	if metric == nil {
+	if metric == nil || metric.IsStale(time.Now(), h.staleThreshold) {
 		http.Error(response, "Not found.", http.StatusNotFound)
 		return
 	}
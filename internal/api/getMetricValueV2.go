@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/e1m0re/grdn/internal/models"
 )
@@ -24,13 +25,13 @@ func (h *Handler) getMetricValueV2(response http.ResponseWriter, request *http.R
 		return
 	}
 
-	metric, err := h.services.MetricsManager.GetMetric(request.Context(), reqData.MType, reqData.ID)
+	metric, err := h.services.MetricsManager.GetMetric(request.Context(), reqData.MType, reqData.ID, reqData.Labels)
 	if err != nil {
 		slog.Error(err.Error())
 		http.Error(response, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	if metric == nil {
+	if metric == nil || metric.IsStale(time.Now(), h.staleThreshold) {
 		http.Error(response, "Not found.", http.StatusNotFound)
 		return
 	}
@@ -79,7 +79,7 @@ func TestHandler_getMetricValueV2(t *testing.T) {
 			mockServices: func() *service.ServerServices {
 				mockMetricsManager := mocks.NewManager(t)
 				mockMetricsManager.
-					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 					Return(nil, nil)
 
 				return &service.ServerServices{
@@ -102,7 +102,7 @@ func TestHandler_getMetricValueV2(t *testing.T) {
 			mockServices: func() *service.ServerServices {
 				mockMetricsManager := mocks.NewManager(t)
 				mockMetricsManager.
-					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 					Return(nil, errors.New("something wrong"))
 
 				return &service.ServerServices{
@@ -125,7 +125,7 @@ func TestHandler_getMetricValueV2(t *testing.T) {
 			mockServices: func() *service.ServerServices {
 				mockMetricsManager := mocks.NewManager(t)
 				mockMetricsManager.
-					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 					Return(&models.Metric{
 						ID:    "metricId",
 						MType: models.CounterType,
@@ -153,7 +153,7 @@ func TestHandler_getMetricValueV2(t *testing.T) {
 			mockServices: func() *service.ServerServices {
 				mockMetricsManager := mocks.NewManager(t)
 				mockMetricsManager.
-					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 					Return(&models.Metric{
 						ID:    "metricId",
 						MType: models.GaugeType,
@@ -176,12 +176,67 @@ func TestHandler_getMetricValueV2(t *testing.T) {
 				expectedResponseBody: fmt.Sprintf("{\"value\":%f,\"type\":\"%s\",\"id\":\"metricId\"}", value, models.GaugeType),
 			},
 		},
+		{
+			name: "Successfully test (Summary metric)",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("GetMetric", mock.Anything, models.SummaryType, "RequestDuration", models.Labels(nil)).
+					Return(&models.Metric{
+						Summary: &models.Summary{Quantiles: []models.Quantile{{Quantile: 0.5, Value: 0.2}}, Count: 3, Sum: 0.7},
+						ID:      "RequestDuration",
+						MType:   models.SummaryType,
+					}, nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx:    context.Background(),
+				body:   fmt.Sprintf("{\"id\":\"RequestDuration\",\"type\":\"%s\"}", models.SummaryType),
+				method: http.MethodPost,
+			},
+			want: want{
+				expectedStatusCode:   http.StatusOK,
+				expectedHeaders:      map[string]string{"Content-Type": "application/json"},
+				expectedResponseBody: "{\"summary\":{\"quantiles\":[{\"quantile\":0.5,\"value\":0.2}],\"count\":3,\"sum\":0.7},\"type\":\"summary\",\"id\":\"RequestDuration\"}",
+			},
+		},
+		{
+			name: "Successfully test (metric with labels)",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("GetMetric", mock.Anything, models.GaugeType, "CPUutilization", models.Labels{"cpu": "3"}).
+					Return(&models.Metric{
+						ID:     "CPUutilization",
+						MType:  models.GaugeType,
+						Labels: models.Labels{"cpu": "3"},
+						Value:  &value,
+					}, nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx:    context.Background(),
+				body:   fmt.Sprintf("{\"id\":\"CPUutilization\",\"type\":\"%s\",\"labels\":{\"cpu\":\"3\"}}", models.GaugeType),
+				method: http.MethodPost,
+			},
+			want: want{
+				expectedStatusCode:   http.StatusOK,
+				expectedHeaders:      map[string]string{"Content-Type": "application/json"},
+				expectedResponseBody: fmt.Sprintf("{\"value\":%f,\"labels\":{\"cpu\":\"3\"},\"type\":\"%s\",\"id\":\"CPUutilization\"}", value, models.GaugeType),
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			services := test.mockServices()
-			handler := NewHandler(services)
-			router := handler.NewRouter("", "")
+			handler := NewHandler(services, 0, nil)
+			router := handler.NewRouter(nil, 0, "", nil, false)
 
 			req, err := http.NewRequestWithContext(test.args.ctx, test.args.method, "/value", bytes.NewReader([]byte(test.args.body)))
 			require.NoError(t, err)
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -20,8 +21,10 @@ func TestHandler_getMetricValue(t *testing.T) {
 	delta := int64(100)
 	value := 100.123456
 	type args struct {
-		ctx    context.Context
-		method string
+		ctx            context.Context
+		method         string
+		query          string
+		staleThreshold time.Duration
 	}
 	type want struct {
 		expectedHeaders      map[string]string
@@ -58,7 +61,7 @@ func TestHandler_getMetricValue(t *testing.T) {
 			mockServices: func() *service.ServerServices {
 				mockMetricsManager := mocks.NewManager(t)
 				mockMetricsManager.
-					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 					Return(nil, nil)
 
 				return &service.ServerServices{
@@ -80,7 +83,7 @@ func TestHandler_getMetricValue(t *testing.T) {
 			mockServices: func() *service.ServerServices {
 				mockMetricsManager := mocks.NewManager(t)
 				mockMetricsManager.
-					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 					Return(nil, errors.New("something wrong"))
 
 				return &service.ServerServices{
@@ -102,7 +105,7 @@ func TestHandler_getMetricValue(t *testing.T) {
 			mockServices: func() *service.ServerServices {
 				mockMetricsManager := mocks.NewManager(t)
 				mockMetricsManager.
-					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 					Return(&models.Metric{
 						ID:    "metricId",
 						MType: models.CounterType,
@@ -129,7 +132,7 @@ func TestHandler_getMetricValue(t *testing.T) {
 			mockServices: func() *service.ServerServices {
 				mockMetricsManager := mocks.NewManager(t)
 				mockMetricsManager.
-					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 					Return(&models.Metric{
 						ID:    "metricId",
 						MType: models.GaugeType,
@@ -151,14 +154,115 @@ func TestHandler_getMetricValue(t *testing.T) {
 				expectedResponseBody: fmt.Sprintf("%f", value),
 			},
 		},
+		{
+			name: "Successfully test (Histogram metric)",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
+					Return(&models.Metric{
+						Histogram: &models.Histogram{Bounds: []float64{0.001, 0.01}, Counts: []uint64{2, 1, 0}, Sum: 0.0075},
+						ID:        "GCPause",
+						MType:     models.HistogramType,
+					}, nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx:    context.Background(),
+				method: http.MethodGet,
+			},
+			want: want{
+				expectedStatusCode:   http.StatusOK,
+				expectedHeaders:      map[string]string{"Content-Type": "text/html"},
+				expectedResponseBody: "count=3 sum=0.0075 buckets=0.001:2,0.01:3,+Inf:3",
+			},
+		},
+		{
+			name: "Invalid label",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				ctx:    context.Background(),
+				method: http.MethodGet,
+				query:  "?label=cpu",
+			},
+			want: want{
+				expectedStatusCode:   http.StatusBadRequest,
+				expectedHeaders:      make(map[string]string),
+				expectedResponseBody: "invalid label \"cpu\", expected name:value\n",
+			},
+		},
+		{
+			name: "Successfully test (metric with labels)",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("GetMetric", mock.Anything, "mType", "mName", models.Labels{"cpu": "3", "host": "web1"}).
+					Return(&models.Metric{
+						ID:     "mName",
+						MType:  models.GaugeType,
+						Labels: models.Labels{"cpu": "3", "host": "web1"},
+						Value:  &value,
+					}, nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx:    context.Background(),
+				method: http.MethodGet,
+				query:  "?label=cpu:3&label=host:web1",
+			},
+			want: want{
+				expectedStatusCode:   http.StatusOK,
+				expectedHeaders:      map[string]string{"Content-Type": "text/html"},
+				expectedResponseBody: fmt.Sprintf("%f", value),
+			},
+		},
+		{
+			name: "Stale metric",
+			mockServices: func() *service.ServerServices {
+				updatedAt := time.Now().Add(-time.Hour)
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
+					Return(&models.Metric{
+						ID:        "metricId",
+						MType:     models.GaugeType,
+						Value:     &value,
+						Timestamp: &updatedAt,
+					}, nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx:            context.Background(),
+				method:         http.MethodGet,
+				staleThreshold: time.Minute,
+			},
+			want: want{
+				expectedStatusCode:   http.StatusNotFound,
+				expectedHeaders:      make(map[string]string),
+				expectedResponseBody: "Not found.\n",
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			services := test.mockServices()
-			handler := NewHandler(services)
-			router := handler.NewRouter("", "")
+			handler := NewHandler(services, test.args.staleThreshold, nil)
+			router := handler.NewRouter(nil, 0, "", nil, false)
 
-			req, err := http.NewRequestWithContext(test.args.ctx, test.args.method, "/value/mType/mName", nil)
+			req, err := http.NewRequestWithContext(test.args.ctx, test.args.method, "/value/mType/mName"+test.args.query, nil)
 			require.NoError(t, err)
 
 			rr := httptest.NewRecorder()
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+const (
+	prometheusContentType  = "text/plain; version=0.0.4; charset=utf-8"
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// getPrometheusMetrics exposes metrics having all labels passed as label query parameters.
+func (h *Handler) getPrometheusMetrics(response http.ResponseWriter, request *http.Request) {
+	filter, err := parseLabels(request)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	metrics, err := h.services.MetricsManager.GetAllMetrics(request.Context())
+	if err != nil {
+		slog.Error(err.Error())
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	openMetrics := strings.Contains(request.Header.Get("Accept"), "application/openmetrics-text")
+
+	contentType := prometheusContentType
+	if openMetrics {
+		contentType = openMetricsContentType
+	}
+
+	response.Header().Set("Content-Type", contentType)
+	_, err = response.Write(formatPrometheusMetrics(filterMetrics(*metrics, filter), openMetrics))
+	if err != nil {
+		slog.Error(err.Error())
+		response.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// prometheusSeries is the metric together with the sanitized name of its family.
+type prometheusSeries struct {
+	metric *models.Metric
+	family string
+}
+
+// formatPrometheusMetrics renders metrics in the Prometheus text exposition format or in the OpenMetrics format.
+// Series are grouped by the sanitized family name under one TYPE line. IDs differing only in characters replaced by
+// underscores fall into one family, so series whose type differs from the family type or whose labels repeat an
+// already rendered series are skipped and logged. Histograms are exposed as cumulative buckets, summaries as quantiles, both are
+// followed by the sum and the count of observations.
+func formatPrometheusMetrics(metrics models.MetricsList, openMetrics bool) []byte {
+	series := make([]prometheusSeries, 0, len(metrics))
+	for _, metric := range metrics {
+		switch metric.MType {
+		case models.GaugeType, models.CounterType, models.HistogramType, models.SummaryType:
+			series = append(series, prometheusSeries{metric: metric, family: prometheusFamilyName(metric, openMetrics)})
+		}
+	}
+	sort.Slice(series, func(i, j int) bool {
+		a, b := series[i], series[j]
+		if a.family != b.family {
+			return a.family < b.family
+		}
+		if a.metric.MType != b.metric.MType {
+			return a.metric.MType < b.metric.MType
+		}
+		if a.metric.Labels.String() != b.metric.Labels.String() {
+			return a.metric.Labels.String() < b.metric.Labels.String()
+		}
+		return a.metric.ID < b.metric.ID
+	})
+
+	var buf bytes.Buffer
+	var family string
+	var mType models.MetricType
+	rendered := make(map[string]bool, len(series))
+	for _, s := range series {
+		metric, name := s.metric, s.family
+		if name != family {
+			family, mType = name, metric.MType
+			fmt.Fprintf(&buf, "# TYPE %s %s\n", name, metric.MType)
+		} else if metric.MType != mType {
+			slog.Warn("series of another type in the metric family is skipped",
+				slog.String("id", metric.ID), slog.String("family", name), slog.String("type", metric.MType))
+			continue
+		}
+
+		labels := prometheusLabels(metric.Labels, metric.MType)
+		key := name + labels.String()
+		if rendered[key] {
+			slog.Warn("series with labels of an already exposed series is skipped",
+				slog.String("id", metric.ID), slog.String("family", name), slog.String("labels", labels.String()))
+			continue
+		}
+		rendered[key] = true
+
+		switch metric.MType {
+		case models.HistogramType:
+			var cumulative uint64
+			for i, count := range metric.Histogram.Counts {
+				cumulative += count
+				labels["le"] = "+Inf"
+				if i < len(metric.Histogram.Bounds) {
+					labels["le"] = strconv.FormatFloat(metric.Histogram.Bounds[i], 'f', -1, 64)
+				}
+				fmt.Fprintf(&buf, "%s_bucket%s %d\n", name, labels, cumulative)
+			}
+			delete(labels, "le")
+			fmt.Fprintf(&buf, "%s_sum%s %s\n", name, labels, strconv.FormatFloat(metric.Histogram.Sum, 'f', -1, 64))
+			fmt.Fprintf(&buf, "%s_count%s %d\n", name, labels, metric.Histogram.Count())
+		case models.SummaryType:
+			for _, q := range metric.Summary.Quantiles {
+				labels["quantile"] = strconv.FormatFloat(q.Quantile, 'f', -1, 64)
+				fmt.Fprintf(&buf, "%s%s %s\n", name, labels, strconv.FormatFloat(q.Value, 'f', -1, 64))
+			}
+			delete(labels, "quantile")
+			fmt.Fprintf(&buf, "%s_sum%s %s\n", name, labels, strconv.FormatFloat(metric.Summary.Sum, 'f', -1, 64))
+			fmt.Fprintf(&buf, "%s_count%s %d\n", name, labels, metric.Summary.Count)
+		default:
+			sample := name
+			if openMetrics && metric.MType == models.CounterType {
+				sample += "_total"
+			}
+			fmt.Fprintf(&buf, "%s%s %s\n", sample, labels, metric.ValueToString())
+		}
+	}
+
+	if openMetrics {
+		buf.WriteString("# EOF\n")
+	}
+
+	return buf.Bytes()
+}
+
+// prometheusFamilyName returns the sanitized name of the metric family. OpenMetrics counter samples get the _total
+// suffix, so it is trimmed from the family name of counters whose ID already ends with it.
+func prometheusFamilyName(metric *models.Metric, openMetrics bool) string {
+	name := prometheusMetricName(metric.ID)
+	if openMetrics && metric.MType == models.CounterType {
+		return strings.TrimSuffix(name, "_total")
+	}
+
+	return name
+}
+
+// prometheusLabels returns copy of labels replacing characters that are not allowed in Prometheus label names with
+// underscores. The le label of histograms and the quantile label of summaries are renamed with the exported_ prefix,
+// so they are not overwritten by bucket bounds and quantiles.
+func prometheusLabels(labels models.Labels, mType models.MetricType) models.Labels {
+	var reserved string
+	switch mType {
+	case models.HistogramType:
+		reserved = "le"
+	case models.SummaryType:
+		reserved = "quantile"
+	}
+
+	result := make(models.Labels, len(labels))
+	for name, value := range labels {
+		name = strings.ReplaceAll(prometheusMetricName(name), ":", "_")
+		if name == reserved {
+			name = "exported_" + name
+		}
+		result[name] = value
+	}
+
+	return result
+}
+
+// prometheusMetricName replaces characters that are not allowed in Prometheus metric names with underscores.
+func prometheusMetricName(name models.MetricName) string {
+	var sb strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+			sb.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				sb.WriteRune('_')
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,373 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service"
+	"github.com/e1m0re/grdn/internal/service/metrics/mocks"
+)
+
+func TestHandler_getPrometheusMetrics(t *testing.T) {
+	value := float64(100.100)
+	delta := int64(100)
+	metricsList := models.MetricsList{
+		{
+			Delta: &delta,
+			MType: models.CounterType,
+			ID:    "PollCount",
+		},
+		{
+			Value: &value,
+			MType: models.GaugeType,
+			ID:    "HeapAlloc",
+		},
+	}
+	labeledMetricsList := models.MetricsList{
+		{
+			Value:  &value,
+			Labels: models.Labels{"cpu": "1", "host": "web1"},
+			MType:  models.GaugeType,
+			ID:     "CPUutilization",
+		},
+		{
+			Value:  &value,
+			Labels: models.Labels{"cpu": "0", "host": "web1", "k8s.pod": "api"},
+			MType:  models.GaugeType,
+			ID:     "CPUutilization",
+		},
+		{
+			Value:  &value,
+			Labels: models.Labels{"cpu": "0", "host": "web2"},
+			MType:  models.GaugeType,
+			ID:     "CPUutilization",
+		},
+	}
+	distributionsList := models.MetricsList{
+		{
+			Summary: &models.Summary{
+				Quantiles: []models.Quantile{{Quantile: 0.5, Value: 0.2}, {Quantile: 0.99, Value: 0.3}},
+				Count:     3,
+				Sum:       0.7,
+			},
+			MType: models.SummaryType,
+			ID:    "RequestDuration",
+		},
+		{
+			Histogram: &models.Histogram{Bounds: []float64{0.001, 0.01}, Counts: []uint64{2, 1, 0}, Sum: 0.0075},
+			Labels:    models.Labels{"host": "web1"},
+			MType:     models.HistogramType,
+			ID:        "GCPause",
+		},
+	}
+	type args struct {
+		ctx    context.Context
+		accept string
+		query  string
+	}
+	type want struct {
+		expectedHeaders      map[string]string
+		expectedResponseBody string
+		expectedStatusCode   int
+	}
+	tests := []struct {
+		name         string
+		mockServices func() *service.ServerServices
+		args         args
+		want         want
+	}{
+		{
+			name: "Request failed",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("GetAllMetrics", mock.Anything).
+					Return(nil, errors.New("something wrong"))
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want: want{
+				expectedStatusCode:   http.StatusInternalServerError,
+				expectedHeaders:      make(map[string]string),
+				expectedResponseBody: "",
+			},
+		},
+		{
+			name: "Prometheus text format",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("GetAllMetrics", mock.Anything).
+					Return(&metricsList, nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx:    context.Background(),
+				accept: "text/plain",
+			},
+			want: want{
+				expectedHeaders:      map[string]string{"Content-Type": prometheusContentType},
+				expectedStatusCode:   http.StatusOK,
+				expectedResponseBody: "# TYPE HeapAlloc gauge\nHeapAlloc 100.1\n# TYPE PollCount counter\nPollCount 100\n",
+			},
+		},
+		{
+			name: "OpenMetrics format",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("GetAllMetrics", mock.Anything).
+					Return(&metricsList, nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx:    context.Background(),
+				accept: "application/openmetrics-text; version=1.0.0",
+			},
+			want: want{
+				expectedHeaders:      map[string]string{"Content-Type": openMetricsContentType},
+				expectedStatusCode:   http.StatusOK,
+				expectedResponseBody: "# TYPE HeapAlloc gauge\nHeapAlloc 100.1\n# TYPE PollCount counter\nPollCount_total 100\n# EOF\n",
+			},
+		},
+		{
+			name: "Series with labels",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("GetAllMetrics", mock.Anything).
+					Return(&labeledMetricsList, nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want: want{
+				expectedHeaders:    map[string]string{"Content-Type": prometheusContentType},
+				expectedStatusCode: http.StatusOK,
+				expectedResponseBody: "# TYPE CPUutilization gauge\n" +
+					"CPUutilization{cpu=\"0\",host=\"web1\",k8s_pod=\"api\"} 100.1\n" +
+					"CPUutilization{cpu=\"0\",host=\"web2\"} 100.1\n" +
+					"CPUutilization{cpu=\"1\",host=\"web1\"} 100.1\n",
+			},
+		},
+		{
+			name: "Histograms and summaries",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("GetAllMetrics", mock.Anything).
+					Return(&distributionsList, nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want: want{
+				expectedHeaders:    map[string]string{"Content-Type": prometheusContentType},
+				expectedStatusCode: http.StatusOK,
+				expectedResponseBody: "# TYPE GCPause histogram\n" +
+					"GCPause_bucket{host=\"web1\",le=\"0.001\"} 2\n" +
+					"GCPause_bucket{host=\"web1\",le=\"0.01\"} 3\n" +
+					"GCPause_bucket{host=\"web1\",le=\"+Inf\"} 3\n" +
+					"GCPause_sum{host=\"web1\"} 0.0075\n" +
+					"GCPause_count{host=\"web1\"} 3\n" +
+					"# TYPE RequestDuration summary\n" +
+					"RequestDuration{quantile=\"0.5\"} 0.2\n" +
+					"RequestDuration{quantile=\"0.99\"} 0.3\n" +
+					"RequestDuration_sum 0.7\n" +
+					"RequestDuration_count 3\n",
+			},
+		},
+		{
+			name: "Label filter",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("GetAllMetrics", mock.Anything).
+					Return(&labeledMetricsList, nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx:   context.Background(),
+				query: "?label=host:web1&label=cpu:1",
+			},
+			want: want{
+				expectedHeaders:      map[string]string{"Content-Type": prometheusContentType},
+				expectedStatusCode:   http.StatusOK,
+				expectedResponseBody: "# TYPE CPUutilization gauge\nCPUutilization{cpu=\"1\",host=\"web1\"} 100.1\n",
+			},
+		},
+		{
+			name: "Invalid label filter",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				ctx:   context.Background(),
+				query: "?label=host",
+			},
+			want: want{
+				expectedHeaders:      make(map[string]string),
+				expectedStatusCode:   http.StatusBadRequest,
+				expectedResponseBody: "invalid label \"host\", expected name:value\n",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			services := test.mockServices()
+			handler := NewHandler(services, 0, nil)
+			router := handler.NewRouter(nil, 0, "", nil, false)
+
+			req, err := http.NewRequestWithContext(test.args.ctx, http.MethodGet, "/metrics"+test.args.query, nil)
+			require.NoError(t, err)
+			if len(test.args.accept) > 0 {
+				req.Header.Set("Accept", test.args.accept)
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, test.want.expectedStatusCode, rr.Code)
+			for key, value := range test.want.expectedHeaders {
+				require.Equal(t, value, rr.Header().Get(key))
+			}
+			require.Equal(t, test.want.expectedResponseBody, rr.Body.String())
+		})
+	}
+}
+
+func Test_prometheusMetricName(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  models.MetricName
+		want string
+	}{
+		{name: "valid name", arg: "CPUutilization0", want: "CPUutilization0"},
+		{name: "invalid characters", arg: "DiskUsedPercent_/var/log", want: "DiskUsedPercent__var_log"},
+		{name: "leading digit", arg: "1min", want: "_1min"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, prometheusMetricName(test.arg))
+		})
+	}
+}
+
+func Test_formatPrometheusMetrics(t *testing.T) {
+	value := float64(1.5)
+	delta := int64(3)
+	type args struct {
+		metrics     models.MetricsList
+		openMetrics bool
+	}
+	tests := []struct {
+		name string
+		want string
+		args args
+	}{
+		{
+			name: "IDs sanitized to the same name",
+			args: args{
+				metrics: models.MetricsList{
+					{Value: &value, Labels: models.Labels{"host": "web1"}, MType: models.GaugeType, ID: "a_b"},
+					{Value: &value, Labels: models.Labels{"host": "web2"}, MType: models.GaugeType, ID: "a.b"},
+					{Value: &value, Labels: models.Labels{"host": "web1"}, MType: models.GaugeType, ID: "a-b"},
+				},
+			},
+			want: "# TYPE a_b gauge\na_b{host=\"web1\"} 1.5\na_b{host=\"web2\"} 1.5\n",
+		},
+		{
+			name: "Type conflict of the same name",
+			args: args{
+				metrics: models.MetricsList{
+					{Value: &value, MType: models.GaugeType, ID: "a.b"},
+					{Delta: &delta, MType: models.CounterType, ID: "a_b"},
+				},
+			},
+			want: "# TYPE a_b counter\na_b 3\n",
+		},
+		{
+			name: "Histogram with le label",
+			args: args{
+				metrics: models.MetricsList{{
+					Histogram: &models.Histogram{Bounds: []float64{1}, Counts: []uint64{2, 1}, Sum: 2.5},
+					Labels:    models.Labels{"le": "user"},
+					MType:     models.HistogramType,
+					ID:        "latency",
+				}},
+			},
+			want: "# TYPE latency histogram\n" +
+				"latency_bucket{exported_le=\"user\",le=\"1\"} 2\n" +
+				"latency_bucket{exported_le=\"user\",le=\"+Inf\"} 3\n" +
+				"latency_sum{exported_le=\"user\"} 2.5\n" +
+				"latency_count{exported_le=\"user\"} 3\n",
+		},
+		{
+			name: "Summary with quantile label",
+			args: args{
+				metrics: models.MetricsList{{
+					Summary: &models.Summary{Quantiles: []models.Quantile{{Quantile: 0.5, Value: 0.2}}, Count: 2, Sum: 0.5},
+					Labels:  models.Labels{"quantile": "user", "le": "user"},
+					MType:   models.SummaryType,
+					ID:      "latency",
+				}},
+			},
+			want: "# TYPE latency summary\n" +
+				"latency{exported_quantile=\"user\",le=\"user\",quantile=\"0.5\"} 0.2\n" +
+				"latency_sum{exported_quantile=\"user\",le=\"user\"} 0.5\n" +
+				"latency_count{exported_quantile=\"user\",le=\"user\"} 2\n",
+		},
+		{
+			name: "Counter with _total suffix in Prometheus text format",
+			args: args{
+				metrics: models.MetricsList{{Delta: &delta, MType: models.CounterType, ID: "requests_total"}},
+			},
+			want: "# TYPE requests_total counter\nrequests_total 3\n",
+		},
+		{
+			name: "Counter with _total suffix in OpenMetrics format",
+			args: args{
+				metrics:     models.MetricsList{{Delta: &delta, MType: models.CounterType, ID: "requests_total"}},
+				openMetrics: true,
+			},
+			want: "# TYPE requests counter\nrequests_total 3\n# EOF\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, string(formatPrometheusMetrics(test.args.metrics, test.args.openMetrics)))
+		})
+	}
+}
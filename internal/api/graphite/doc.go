@@ -0,0 +1,3 @@
+// Package graphite implements the TCP listener of Graphite plaintext protocol. Metrics are gauges unless the
+// ingest.TypeRule says otherwise, tags of lines are ignored. History of metrics is recorded at timestamps of lines.
+package graphite
@@ -0,0 +1,53 @@
+package graphite
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrMalformedLine is the error returned when the line doesn't match the format "path value [timestamp]".
+	ErrMalformedLine = errors.New("malformed line")
+	// ErrInvalidValue is the error returned when the value is not a finite number.
+	ErrInvalidValue = errors.New("invalid value")
+	// ErrInvalidTimestamp is the error returned when the timestamp is not a number of seconds.
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+)
+
+// parseLine parses the line of Graphite plaintext protocol and returns the metric path without tags, the value and
+// the time of the sample. Zero time is returned for lines without timestamp, they are stored with the time of receipt.
+func parseLine(line string) (string, float64, time.Time, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || len(fields) > 3 {
+		return "", 0, time.Time{}, ErrMalformedLine
+	}
+
+	path, _, _ := strings.Cut(fields[0], ";")
+	if len(path) == 0 {
+		return "", 0, time.Time{}, ErrMalformedLine
+	}
+
+	value, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return "", 0, time.Time{}, ErrInvalidValue
+	}
+
+	var ts time.Time
+	if len(fields) == 3 {
+		// Some clients send fractional timestamps, -1 means the time of receipt.
+		var seconds float64
+		seconds, err = strconv.ParseFloat(fields[2], 64)
+		millis := seconds * 1000
+		if err != nil || math.IsNaN(millis) || millis <= math.MinInt64 || millis >= math.MaxInt64 {
+			return "", 0, time.Time{}, ErrInvalidTimestamp
+		}
+		if seconds > 0 {
+			ts = time.UnixMilli(int64(millis))
+		}
+	}
+
+	return path, value, ts, nil
+}
@@ -0,0 +1,75 @@
+package graphite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseLine(t *testing.T) {
+	type want struct {
+		ts    time.Time
+		err   error
+		path  string
+		value float64
+	}
+	tests := []struct {
+		line string
+		want want
+	}{
+		{
+			line: "servers.web1.load 0.75 1729150000",
+			want: want{path: "servers.web1.load", value: 0.75, ts: time.Unix(1729150000, 0)},
+		},
+		{
+			line: "servers.web1.load  1.5",
+			want: want{path: "servers.web1.load", value: 1.5},
+		},
+		{
+			line: "servers.load;host=web1;dc=eu -2 1729150000.5",
+			want: want{path: "servers.load", value: -2, ts: time.UnixMilli(1729150000500)},
+		},
+		{
+			line: "servers.web1.load 1 -1",
+			want: want{path: "servers.web1.load", value: 1},
+		},
+		{
+			line: "servers.web1.load",
+			want: want{err: ErrMalformedLine},
+		},
+		{
+			line: "servers.web1.load 1 1729150000 extra",
+			want: want{err: ErrMalformedLine},
+		},
+		{
+			line: ";host=web1 1",
+			want: want{err: ErrMalformedLine},
+		},
+		{
+			line: "servers.web1.load high 1729150000",
+			want: want{err: ErrInvalidValue},
+		},
+		{
+			line: "servers.web1.load +Inf 1729150000",
+			want: want{err: ErrInvalidValue},
+		},
+		{
+			line: "servers.web1.load 1 yesterday",
+			want: want{err: ErrInvalidTimestamp},
+		},
+		{
+			line: "servers.web1.load 1 1e300",
+			want: want{err: ErrInvalidTimestamp},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			path, value, ts, err := parseLine(tt.line)
+			assert.ErrorIs(t, err, tt.want.err)
+			assert.Equal(t, tt.want.path, path)
+			assert.Equal(t, tt.want.value, value)
+			assert.True(t, tt.want.ts.Equal(ts))
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package graphite
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service/ingest"
+)
+
+// Server is the listener of Graphite plaintext protocol.
+type Server struct {
+	batcher ingest.Batcher
+	// trustedSubnet is nil if connections are accepted from any address.
+	trustedSubnet *net.IPNet
+	rule          ingest.TypeRule
+}
+
+// NewServer is Server constructor. Connections from addresses outside the trusted subnet are rejected if it is not nil.
+func NewServer(batcher ingest.Batcher, rule ingest.TypeRule, trustedSubnet *net.IPNet) *Server {
+	return &Server{
+		batcher:       batcher,
+		trustedSubnet: trustedSubnet,
+		rule:          rule,
+	}
+}
+
+// Serve accepts connections until the context is done. The listener and open connections are closed on exit.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		if !s.trusted(conn.RemoteAddr()) {
+			slog.Warn("graphite connection from untrusted address is rejected", slog.String("remote_addr", conn.RemoteAddr().String()))
+			conn.Close()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stop := context.AfterFunc(ctx, func() {
+				conn.Close()
+			})
+			defer stop()
+			defer conn.Close()
+
+			s.handleConn(conn)
+		}()
+	}
+}
+
+// handleConn adds metrics of lines read from the connection to the batch. Malformed lines are reported with their
+// numbers and counted in GraphiteMalformedLines counter.
+func (s *Server) handleConn(conn net.Conn) {
+	scanner := bufio.NewScanner(conn)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if len(line) == 0 {
+			continue
+		}
+
+		path, value, ts, err := parseLine(line)
+		if err != nil {
+			slog.Warn("malformed graphite line",
+				slog.String("remote_addr", conn.RemoteAddr().String()),
+				slog.String("error", (&ingest.LineError{Line: n, Err: err}).Error()),
+			)
+			s.batcher.AddCounter(models.GraphiteMalformedLines, nil, 1)
+			continue
+		}
+
+		s.rule.Add(s.batcher, path, "", value, ts)
+	}
+
+	if err := scanner.Err(); err != nil && !errors.Is(err, net.ErrClosed) {
+		slog.Warn("graphite connection error", slog.String("remote_addr", conn.RemoteAddr().String()), slog.String("error", err.Error()))
+	}
+}
+
+func (s *Server) trusted(addr net.Addr) bool {
+	if s.trustedSubnet == nil {
+		return true
+	}
+
+	tcpAddr, ok := addr.(*net.TCPAddr)
+
+	return ok && s.trustedSubnet.Contains(tcpAddr.IP)
+}
@@ -0,0 +1,123 @@
+package graphite
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service/ingest"
+	"github.com/e1m0re/grdn/internal/service/ingest/mocks"
+)
+
+func TestServer_handleConn(t *testing.T) {
+	tests := []struct {
+		mockBatcherFunc func(mockBatcher *mocks.Batcher)
+		name            string
+		data            string
+	}{
+		{
+			name: "gauges and counters",
+			data: "servers.web1.load 0.5 1729150000\nservers.web1.requests_total 3 1729150000\n\nservers.web1.requests_total 2.4\n",
+			mockBatcherFunc: func(mockBatcher *mocks.Batcher) {
+				mockBatcher.On("SetGauge", "servers.web1.load", models.Labels(nil), 0.5).Return().Once()
+				mockBatcher.On("SetTime", "servers.web1.load", models.Labels(nil), time.Unix(1729150000, 0)).Return().Once()
+				mockBatcher.On("AddCounter", "servers.web1.requests_total", models.Labels(nil), int64(3)).Return().Once()
+				mockBatcher.On("SetTime", "servers.web1.requests_total", models.Labels(nil), time.Unix(1729150000, 0)).Return().Once()
+				mockBatcher.On("AddCounter", "servers.web1.requests_total", models.Labels(nil), int64(2)).Return().Once()
+			},
+		},
+		{
+			name: "malformed lines",
+			data: "servers.web1.load\nservers.web1.load 0.5\nservers.web1.load high",
+			mockBatcherFunc: func(mockBatcher *mocks.Batcher) {
+				mockBatcher.On("SetGauge", "servers.web1.load", models.Labels(nil), 0.5).Return().Once()
+				mockBatcher.On("AddCounter", models.GraphiteMalformedLines, models.Labels(nil), int64(1)).Return().Twice()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBatcher := mocks.NewBatcher(t)
+			tt.mockBatcherFunc(mockBatcher)
+
+			server, client := net.Pipe()
+			go func() {
+				client.Write([]byte(tt.data))
+				client.Close()
+			}()
+
+			NewServer(mockBatcher, ingest.TypeRule{CounterSuffix: "_total"}, nil).handleConn(server)
+		})
+	}
+}
+
+func TestServer_Serve(t *testing.T) {
+	_, loopback, err := net.ParseCIDR("127.0.0.0/8")
+	require.NoError(t, err)
+	_, other, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+
+	tests := []struct {
+		trustedSubnet *net.IPNet
+		name          string
+		accepted      bool
+	}{
+		{
+			name:     "any address",
+			accepted: true,
+		},
+		{
+			name:          "trusted address",
+			trustedSubnet: loopback,
+			accepted:      true,
+		},
+		{
+			name:          "untrusted address",
+			trustedSubnet: other,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+
+			received := make(chan struct{}, 1)
+			mockBatcher := mocks.NewBatcher(t)
+			if tt.accepted {
+				mockBatcher.
+					On("SetGauge", "servers.web1.load", models.Labels(nil), float64(1)).
+					Run(func(args mock.Arguments) { received <- struct{}{} }).
+					Return().
+					Once()
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+			go func() {
+				done <- NewServer(mockBatcher, ingest.TypeRule{}, tt.trustedSubnet).Serve(ctx, listener)
+			}()
+
+			client, err := net.Dial("tcp", listener.Addr().String())
+			require.NoError(t, err)
+			defer client.Close()
+			_, err = client.Write([]byte("servers.web1.load 1\n"))
+			require.NoError(t, err)
+
+			select {
+			case <-received:
+			case <-time.After(200 * time.Millisecond):
+				assert.False(t, tt.accepted, "line is not received")
+			}
+
+			// Open connections don't block the shutdown.
+			cancel()
+			assert.NoError(t, <-done)
+		})
+	}
+}
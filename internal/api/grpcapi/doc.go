@@ -0,0 +1,2 @@
+// Package grpcapi implements gRPC API of the server.
+package grpcapi
@@ -0,0 +1,74 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/e1m0re/grdn/internal/proto"
+	"github.com/e1m0re/grdn/internal/service/metrics"
+	"github.com/e1m0re/grdn/internal/utils"
+)
+
+// MetricsServer implements gRPC Metrics service.
+type MetricsServer struct {
+	pb.UnimplementedMetricsServer
+	metricsManager metrics.Manager
+}
+
+// NewMetricsServer is MetricsServer constructor.
+func NewMetricsServer(metricsManager metrics.Manager) *MetricsServer {
+	return &MetricsServer{
+		metricsManager: metricsManager,
+	}
+}
+
+// Update updates the batch of metrics.
+func (s *MetricsServer) Update(ctx context.Context, request *pb.UpdateRequest) (*pb.UpdateResponse, error) {
+	count, err := s.updateMetrics(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UpdateResponse{Count: count}, nil
+}
+
+// UpdateStream updates batches of metrics sent by the agent until it closes the stream.
+func (s *MetricsServer) UpdateStream(stream pb.Metrics_UpdateStreamServer) error {
+	var total int64
+	for {
+		request, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&pb.UpdateResponse{Count: total})
+		}
+		if err != nil {
+			return err
+		}
+
+		count, err := s.updateMetrics(stream.Context(), request)
+		if err != nil {
+			return err
+		}
+		total += count
+	}
+}
+
+func (s *MetricsServer) updateMetrics(ctx context.Context, request *pb.UpdateRequest) (int64, error) {
+	list := pb.ToModels(request.GetMetrics())
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	defer cancelFunc()
+	err := utils.RetryFunc(ctx, func() error {
+		return s.metricsManager.UpdateMetrics(ctx, list)
+	})
+	if err != nil {
+		slog.Error("update metrics error", slog.String("error", err.Error()))
+		return 0, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return int64(len(list)), nil
+}
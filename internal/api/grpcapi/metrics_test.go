@@ -0,0 +1,122 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/e1m0re/grdn/internal/models"
+	pb "github.com/e1m0re/grdn/internal/proto"
+	"github.com/e1m0re/grdn/internal/service/metrics"
+	"github.com/e1m0re/grdn/internal/service/metrics/mocks"
+)
+
+// startServer serves the metrics manager via in-memory connection and returns the client.
+func startServer(t *testing.T, metricsManager metrics.Manager) pb.MetricsClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pb.RegisterMetricsServer(server, NewMetricsServer(metricsManager))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewMetricsClient(conn)
+}
+
+func TestMetricsServer_Update(t *testing.T) {
+	delta := int64(5)
+	value := 0.5
+	type want struct {
+		count int64
+		code  codes.Code
+	}
+	tests := []struct {
+		mockMetricsManager func() metrics.Manager
+		request            *pb.UpdateRequest
+		name               string
+		want               want
+	}{
+		{
+			name: "update failed",
+			mockMetricsManager: func() metrics.Manager {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
+					Return(errors.New("unknown metric type"))
+
+				return mockMetricsManager
+			},
+			request: &pb.UpdateRequest{Metrics: []*pb.Metric{{Id: "metric", Type: "unknown"}}},
+			want:    want{code: codes.InvalidArgument},
+		},
+		{
+			name: "successfully case",
+			mockMetricsManager: func() metrics.Manager {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("UpdateMetrics", mock.Anything, models.MetricsList{
+						{ID: "PollCount", MType: models.CounterType, Delta: &delta},
+						{ID: "Alloc", MType: models.GaugeType, Value: &value},
+					}).
+					Return(nil)
+
+				return mockMetricsManager
+			},
+			request: &pb.UpdateRequest{Metrics: []*pb.Metric{
+				{Id: "PollCount", Type: models.CounterType, Delta: 5},
+				{Id: "Alloc", Type: models.GaugeType, Value: 0.5},
+			}},
+			want: want{count: 2, code: codes.OK},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := startServer(t, test.mockMetricsManager())
+
+			response, err := client.Update(context.Background(), test.request)
+			require.Equal(t, test.want.code, status.Code(err))
+			assert.Equal(t, test.want.count, response.GetCount())
+		})
+	}
+}
+
+func TestMetricsServer_UpdateStream(t *testing.T) {
+	mockMetricsManager := mocks.NewManager(t)
+	mockMetricsManager.
+		On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
+		Return(nil).
+		Times(3)
+	client := startServer(t, mockMetricsManager)
+
+	stream, err := client.UpdateStream(context.Background())
+	require.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		metrics := make([]*pb.Metric, i)
+		for j := range metrics {
+			metrics[j] = &pb.Metric{Id: "PollCount", Type: models.CounterType, Delta: 1}
+		}
+		require.NoError(t, stream.Send(&pb.UpdateRequest{Metrics: metrics}))
+	}
+
+	response, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), response.GetCount())
+}
@@ -1,45 +1,95 @@
 package api
 
 import (
+	"net"
+	"net/http"
 	"net/http/pprof"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
 	appMiddleware "github.com/e1m0re/grdn/internal/server/middleware"
 	"github.com/e1m0re/grdn/internal/service"
+	"github.com/e1m0re/grdn/internal/service/ingest"
+	"github.com/e1m0re/grdn/internal/service/signing"
 )
 
+// maxRequestBodySize limits size of the request body read by middleware and handlers.
+const maxRequestBodySize = 32 << 20
+
+// IngestOptions enables endpoints of third-party protocols on the HTTP server.
+type IngestOptions struct {
+	// Rule decides types of metrics received by the endpoints.
+	Rule ingest.TypeRule
+	// Influx enables InfluxDB line protocol on /write and /api/v2/write.
+	Influx bool
+	// PrometheusWrite enables Prometheus remote write on /api/v1/write.
+	PrometheusWrite bool
+	// OTLP enables OpenTelemetry metrics (OTLP/HTTP) on /v1/metrics.
+	OTLP bool
+	// Insecure serves the endpoints without authentication, only the trusted subnet is checked if it is set.
+	Insecure bool
+}
+
 type Handler struct {
 	services *service.ServerServices
+	// ingestOptions is nil if endpoints of third-party protocols are disabled.
+	ingestOptions  *IngestOptions
+	staleThreshold time.Duration
 }
 
 // NewHandler is Handler constructor.
-func NewHandler(services *service.ServerServices) *Handler {
+// Metrics not updated longer than staleThreshold are flagged on the main page and hidden from /value (0 disables the check).
+func NewHandler(services *service.ServerServices, staleThreshold time.Duration, ingestOptions *IngestOptions) *Handler {
 	return &Handler{
-		services: services,
+		services:       services,
+		ingestOptions:  ingestOptions,
+		staleThreshold: staleThreshold,
 	}
 }
 
-// NewRouter initializes new router.
-func (h *Handler) NewRouter(signKey string, privateKeyFile string) *chi.Mux {
+// NewRouter initializes new router. Requests and responses are signed if signKeys is not empty, signMaxSkew enables
+// replay protection of signed requests (see middleware.SignChecking). If the agents registry is configured, requests
+// are authenticated with credentials of agents instead of signKeys. If trustedSubnet is not nil, requests from other
+// addresses are rejected (GET requests only if checkReads is set). Agents are identified by client certificates if the
+// server uses mutual TLS.
+//
+// Endpoints of third-party protocols are registered by ingestRoutes.
+func (h *Handler) NewRouter(signKeys signing.KeySet, signMaxSkew time.Duration, privateKeyFile string, trustedSubnet *net.IPNet, checkReads bool) *chi.Mux {
 	r := chi.NewRouter()
 	r.Use(appMiddleware.Logging())
-	r.Use(appMiddleware.UnzipContent())
-	if len(signKey) > 0 {
-		r.Use(appMiddleware.SignChecking(signKey))
-	}
-	r.Use(middleware.Compress(5, "text/html", "application/json"))
-	if len(privateKeyFile) > 0 {
-		r.Use(appMiddleware.DecryptContent(privateKeyFile))
-	}
-	if len(signKey) > 0 {
-		r.Use(appMiddleware.SignResponse(signKey))
-	}
+	// The body is limited before any middleware reads it completely.
+	r.Use(appMiddleware.LimitBody(maxRequestBodySize))
+
+	r.Group(func(r chi.Router) {
+		if trustedSubnet != nil {
+			r.Use(appMiddleware.TrustedSubnet(trustedSubnet, checkReads))
+		}
+		r.Use(appMiddleware.ClientCertIdentity())
+		r.Use(appMiddleware.UnzipContent(maxRequestBodySize))
+		responseKeys := signKeys
+		switch {
+		case h.services != nil && h.services.AgentsRegistry != nil:
+			// Registered agents sign requests with their own keys.
+			r.Use(appMiddleware.AgentAuth(h.services.AgentsRegistry, signMaxSkew))
+			responseKeys = h.services.AgentsRegistry.Keys()
+		case len(signKeys) > 0:
+			r.Use(appMiddleware.SignChecking(signKeys, signMaxSkew))
+		}
+		r.Use(middleware.Compress(5, "text/html", "application/json"))
+		if len(privateKeyFile) > 0 {
+			r.Use(appMiddleware.DecryptContent(privateKeyFile))
+		}
+		if len(responseKeys) > 0 {
+			r.Use(appMiddleware.SignResponse(responseKeys))
+		}
 
-	r.Route("/", func(r chi.Router) {
 		r.Get("/", h.getMainPage)
 		r.Get("/ping", h.checkDBConnection)
+		r.Get("/metrics", h.getPrometheusMetrics)
+		r.Get("/query", h.queryMetric)
+		r.Get("/alerts", h.getAlerts)
 		r.Route("/value", func(r chi.Router) {
 			r.Post("/", h.getMetricValueV2)
 			r.Get("/{mType}/{mName}", h.getMetricValue)
@@ -59,7 +109,54 @@ func (h *Handler) NewRouter(signKey string, privateKeyFile string) *chi.Mux {
 			r.Get("/symbol", pprof.Symbol)
 			r.Get("/trace", pprof.Trace)
 		})
+
+		h.ingestRoutes(r, false, trustedSubnet)
 	})
 
+	if h.ingestOptions != nil {
+		r.Group(func(r chi.Router) {
+			if trustedSubnet != nil {
+				r.Use(appMiddleware.TrustedRemoteSubnet(trustedSubnet))
+			}
+			r.Use(appMiddleware.UnzipContent(maxRequestBodySize))
+
+			h.ingestRoutes(r, true, trustedSubnet)
+		})
+	}
+
 	return r
 }
+
+// ingestRoutes registers enabled endpoints of third-party protocols which are served without authentication if open
+// is set, other endpoints are registered behind the authentication. Clients of InfluxDB and Prometheus can't sign
+// requests and don't pass the X-Real-IP header, their endpoints are open if the remote address is checked against
+// trustedSubnet or the insecure mode is enabled explicitly. OTLP exporters share the signing and gzip middleware of
+// agents, their endpoint is open in the insecure mode only.
+func (h *Handler) ingestRoutes(r chi.Router, open bool, trustedSubnet *net.IPNet) {
+	if h.ingestOptions == nil {
+		return
+	}
+
+	unsigned := trustedSubnet != nil || h.ingestOptions.Insecure
+	if h.ingestOptions.Influx && unsigned == open {
+		// Paths of write API of InfluxDB 1.x and 2.x, query parameters (database, bucket) are ignored.
+		r.Post("/write", h.writeInflux)
+		r.Post("/api/v2/write", h.writeInflux)
+	}
+	if h.ingestOptions.PrometheusWrite && unsigned == open {
+		r.Post("/api/v1/write", h.writePrometheus)
+	}
+	if h.ingestOptions.OTLP && h.ingestOptions.Insecure == open {
+		r.Post("/v1/metrics", h.writeOTLP)
+	}
+}
+
+// readBodyStatus returns status of the response to the request whose body can't be read: 413 if the body exceeds
+// maxRequestBodySize, 400 otherwise.
+func readBodyStatus(err error) int {
+	if appMiddleware.IsBodyTooLarge(err) {
+		return http.StatusRequestEntityTooLarge
+	}
+
+	return http.StatusBadRequest
+}
@@ -0,0 +1,2 @@
+// Package influx contains the parser of InfluxDB line protocol.
+package influx
@@ -0,0 +1,184 @@
+package influx
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrMalformedLine is the error returned when the line doesn't match the format
+	// "measurement[,tag=value...] field=value[,field=value...] [timestamp]".
+	ErrMalformedLine = errors.New("malformed line")
+	// ErrInvalidField is the error returned when the field has no key or its value can't be parsed.
+	ErrInvalidField = errors.New("invalid field")
+	// ErrInvalidTimestamp is the error returned when the timestamp is not an integer or it is out of range.
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+	// ErrInvalidPrecision is the error returned when the precision of timestamps is unknown.
+	ErrInvalidPrecision = errors.New("invalid precision")
+)
+
+// Field is the numeric field of the point. Booleans are converted to 1 and 0.
+type Field struct {
+	Key   string
+	Value float64
+}
+
+// Point is the line of InfluxDB line protocol. Tags are not used by the server, string fields are skipped. Time is
+// zero if the line has no timestamp.
+type Point struct {
+	Time        time.Time
+	Measurement string
+	Fields      []Field
+}
+
+// ParsePrecision returns the unit of timestamps for the precision parameter of InfluxDB 1.x and 2.x write API.
+// Timestamps are in nanoseconds if the precision is empty.
+func ParsePrecision(precision string) (time.Duration, error) {
+	switch precision {
+	case "", "n", "ns":
+		return time.Nanosecond, nil
+	case "u", "us":
+		return time.Microsecond, nil
+	case "ms":
+		return time.Millisecond, nil
+	case "s":
+		return time.Second, nil
+	case "m":
+		return time.Minute, nil
+	case "h":
+		return time.Hour, nil
+	default:
+		return 0, ErrInvalidPrecision
+	}
+}
+
+// ParseLine parses the line of InfluxDB line protocol with timestamps in units of the precision.
+func ParseLine(line string, precision time.Duration) (Point, error) {
+	series, rest := cut(line, ' ', false)
+	fields, timestamp := cut(rest, ' ', true)
+
+	measurement, _ := cut(series, ',', false)
+	measurement = unescape(measurement)
+	if len(measurement) == 0 || len(fields) == 0 {
+		return Point{}, ErrMalformedLine
+	}
+
+	point := Point{Measurement: measurement}
+	timestamp = strings.TrimSpace(timestamp)
+	if len(timestamp) > 0 {
+		var err error
+		point.Time, err = parseTime(timestamp, precision)
+		if err != nil {
+			return Point{}, err
+		}
+	}
+
+	for len(fields) > 0 {
+		var field string
+		field, fields = cut(fields, ',', true)
+
+		key, value := cut(field, '=', false)
+		key = unescape(key)
+		if len(key) == 0 || len(value) == 0 {
+			return Point{}, ErrInvalidField
+		}
+
+		if value[0] == '"' {
+			if len(value) < 2 || value[len(value)-1] != '"' {
+				return Point{}, ErrInvalidField
+			}
+			continue
+		}
+
+		number, err := parseValue(value)
+		if err != nil {
+			return Point{}, ErrInvalidField
+		}
+		point.Fields = append(point.Fields, Field{Key: key, Value: number})
+	}
+
+	return point, nil
+}
+
+// cut slices s around the first unescaped separator. Separators in double-quoted strings are skipped if quoted is set.
+func cut(s string, sep byte, quoted bool) (string, string) {
+	inQuotes := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case s[i] == '"' && quoted:
+			inQuotes = !inQuotes
+		case s[i] == sep && !inQuotes:
+			return s[:i], s[i+1:]
+		}
+	}
+
+	return s, ""
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+
+	return strings.NewReplacer(`\,`, ",", `\ `, " ", `\=`, "=", `\\`, `\`).Replace(s)
+}
+
+// parseTime parses the timestamp in units of the precision.
+func parseTime(timestamp string, precision time.Duration) (time.Time, error) {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, ErrInvalidTimestamp
+	}
+
+	if precision < time.Second {
+		perSecond := int64(time.Second / precision)
+		return time.Unix(ts/perSecond, ts%perSecond*int64(precision)), nil
+	}
+
+	seconds := int64(precision / time.Second)
+	if ts > math.MaxInt64/seconds || ts < math.MinInt64/seconds {
+		return time.Time{}, ErrInvalidTimestamp
+	}
+
+	return time.Unix(ts*seconds, 0), nil
+}
+
+func parseValue(value string) (float64, error) {
+	switch value {
+	case "t", "T", "true", "True", "TRUE":
+		return 1, nil
+	case "f", "F", "false", "False", "FALSE":
+		return 0, nil
+	}
+
+	switch value[len(value)-1] {
+	case 'i':
+		number, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+		return float64(number), err
+	case 'u':
+		number, err := strconv.ParseUint(value[:len(value)-1], 10, 64)
+		return float64(number), err
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err == nil && (math.IsNaN(number) || math.IsInf(number, 0)) {
+		return 0, ErrInvalidField
+	}
+
+	return number, err
+}
+
+// MetricName returns name of the metric stored for the field of the point: "measurement_field", or just the
+// measurement for the field named "value".
+func MetricName(measurement, field string) string {
+	if field == "value" {
+		return measurement
+	}
+
+	return measurement + "_" + field
+}
@@ -0,0 +1,126 @@
+package influx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLine(t *testing.T) {
+	type want struct {
+		err   error
+		point Point
+	}
+	tests := []struct {
+		line      string
+		want      want
+		precision time.Duration
+	}{
+		{
+			line: "cpu,host=web1,region=eu usage=0.64,count=3i 1729150000000000001",
+			want: want{point: Point{Measurement: "cpu", Fields: []Field{{Key: "usage", Value: 0.64}, {Key: "count", Value: 3}}, Time: time.Unix(1729150000, 1)}},
+		},
+		{
+			line:      "cpu usage=0.64 1729150000123",
+			precision: time.Millisecond,
+			want:      want{point: Point{Measurement: "cpu", Fields: []Field{{Key: "usage", Value: 0.64}}, Time: time.UnixMilli(1729150000123)}},
+		},
+		{
+			line:      "cpu usage=0.64 480319",
+			precision: time.Hour,
+			want:      want{point: Point{Measurement: "cpu", Fields: []Field{{Key: "usage", Value: 0.64}}, Time: time.Unix(1729148400, 0)}},
+		},
+		{
+			line: "disk free=12u,healthy=true,readonly=F",
+			want: want{point: Point{Measurement: "disk", Fields: []Field{{Key: "free", Value: 12}, {Key: "healthy", Value: 1}, {Key: "readonly", Value: 0}}}},
+		},
+		{
+			line:      `http\ requests,path=/a\,b value=5,note="a, b = c" 1729150000`,
+			precision: time.Second,
+			want:      want{point: Point{Measurement: "http requests", Fields: []Field{{Key: "value", Value: 5}}, Time: time.Unix(1729150000, 0)}},
+		},
+		{
+			line: `events message="only string"`,
+			want: want{point: Point{Measurement: "events"}},
+		},
+		{
+			line: "cpu",
+			want: want{err: ErrMalformedLine},
+		},
+		{
+			line: ",host=web1 usage=1",
+			want: want{err: ErrMalformedLine},
+		},
+		{
+			line: "cpu usage=",
+			want: want{err: ErrInvalidField},
+		},
+		{
+			line: "cpu =1",
+			want: want{err: ErrInvalidField},
+		},
+		{
+			line: "cpu usage=high",
+			want: want{err: ErrInvalidField},
+		},
+		{
+			line: "cpu usage=1.5i",
+			want: want{err: ErrInvalidField},
+		},
+		{
+			line: `cpu note="unterminated`,
+			want: want{err: ErrInvalidField},
+		},
+		{
+			line: "cpu usage=1 yesterday",
+			want: want{err: ErrInvalidTimestamp},
+		},
+		{
+			line:      "cpu usage=1 9223372036854775807",
+			precision: time.Hour,
+			want:      want{err: ErrInvalidTimestamp},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			precision := tt.precision
+			if precision == 0 {
+				precision = time.Nanosecond
+			}
+			got, err := ParseLine(tt.line, precision)
+			assert.ErrorIs(t, err, tt.want.err)
+			assert.Equal(t, tt.want.point, got)
+		})
+	}
+}
+
+func TestParsePrecision(t *testing.T) {
+	tests := []struct {
+		err       error
+		precision string
+		want      time.Duration
+	}{
+		{precision: "", want: time.Nanosecond},
+		{precision: "ns", want: time.Nanosecond},
+		{precision: "u", want: time.Microsecond},
+		{precision: "us", want: time.Microsecond},
+		{precision: "ms", want: time.Millisecond},
+		{precision: "s", want: time.Second},
+		{precision: "m", want: time.Minute},
+		{precision: "h", want: time.Hour},
+		{precision: "d", err: ErrInvalidPrecision},
+	}
+	for _, tt := range tests {
+		t.Run(tt.precision, func(t *testing.T) {
+			got, err := ParsePrecision(tt.precision)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMetricName(t *testing.T) {
+	assert.Equal(t, "cpu_usage", MetricName("cpu", "usage"))
+	assert.Equal(t, "cpu", MetricName("cpu", "value"))
+}
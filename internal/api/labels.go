@@ -0,0 +1,47 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+// labelParam is the query parameter specifying labels of metrics in the form name:value, e.g. ?label=host:web1.
+const labelParam = "label"
+
+// parseLabels returns labels specified by label query parameters of the request. Nil is returned without parameters.
+func parseLabels(request *http.Request) (models.Labels, error) {
+	values := request.URL.Query()[labelParam]
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	labels := make(models.Labels, len(values))
+	for _, value := range values {
+		name, labelValue, ok := strings.Cut(value, ":")
+		if !ok || len(name) == 0 {
+			return nil, fmt.Errorf("invalid label %q, expected name:value", value)
+		}
+		labels[name] = labelValue
+	}
+
+	return labels, nil
+}
+
+// filterMetrics returns metrics having all labels of the filter.
+func filterMetrics(metrics models.MetricsList, filter models.Labels) models.MetricsList {
+	if len(filter) == 0 {
+		return metrics
+	}
+
+	result := make(models.MetricsList, 0, len(metrics))
+	for _, metric := range metrics {
+		if metric.Labels.Matches(filter) {
+			result = append(result, metric)
+		}
+	}
+
+	return result
+}
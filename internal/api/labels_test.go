@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+func Test_parseLabels(t *testing.T) {
+	type want struct {
+		labels models.Labels
+		err    string
+	}
+	tests := []struct {
+		name  string
+		query string
+		want  want
+	}{
+		{
+			name:  "Without labels",
+			query: "?name=metric1",
+			want:  want{labels: nil},
+		},
+		{
+			name:  "Labels",
+			query: "?label=host:web1&label=path:/var:log&label=empty:",
+			want:  want{labels: models.Labels{"host": "web1", "path": "/var:log", "empty": ""}},
+		},
+		{
+			name:  "Missing value",
+			query: "?label=host",
+			want:  want{err: `invalid label "host", expected name:value`},
+		},
+		{
+			name:  "Missing name",
+			query: "?label=:web1",
+			want:  want{err: `invalid label ":web1", expected name:value`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/metrics"+test.query, nil)
+			require.NoError(t, err)
+
+			got, err := parseLabels(req)
+			if len(test.want.err) > 0 {
+				require.EqualError(t, err, test.want.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want.labels, got)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service/metrics"
+	"github.com/e1m0re/grdn/internal/storage"
+)
+
+const (
+	defaultQueryRange = time.Hour
+	defaultQueryStep  = time.Minute
+)
+
+type queryResponse struct {
+	From    time.Time         `json:"from"`
+	To      time.Time         `json:"to"`
+	Labels  models.Labels     `json:"labels,omitempty"`
+	ID      models.MetricName `json:"id"`
+	MType   models.MetricType `json:"type"`
+	Step    string            `json:"step"`
+	Buckets []models.Bucket   `json:"buckets"`
+}
+
+// parseQueryTime parses time specified in RFC3339 or as unix timestamp in seconds.
+func parseQueryTime(value string, defaultValue time.Time) (time.Time, error) {
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))).UTC(), nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+func (h *Handler) queryMetric(response http.ResponseWriter, request *http.Request) {
+	params := request.URL.Query()
+
+	to, err := parseQueryTime(params.Get("to"), time.Now())
+	if err != nil {
+		http.Error(response, fmt.Sprintf("error parsing to: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	from, err := parseQueryTime(params.Get("from"), to.Add(-defaultQueryRange))
+	if err != nil {
+		http.Error(response, fmt.Sprintf("error parsing from: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	step := defaultQueryStep
+	if value := params.Get("step"); len(value) > 0 {
+		step, err = time.ParseDuration(value)
+		if err != nil {
+			http.Error(response, fmt.Sprintf("error parsing step: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	labels, err := parseLabels(request)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := queryResponse{
+		From:   from,
+		To:     to,
+		Labels: labels,
+		ID:     params.Get("name"),
+		MType:  params.Get("type"),
+		Step:   step.String(),
+	}
+
+	result.Buckets, err = h.services.MetricsManager.QueryMetric(request.Context(), result.MType, result.ID, result.Labels, from, to, step)
+	switch {
+	case errors.Is(err, storage.ErrUnknownMetricType),
+		errors.Is(err, metrics.ErrInvalidTimeRange),
+		errors.Is(err, metrics.ErrInvalidStep):
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		slog.Error(err.Error())
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respContent, err := json.Marshal(result)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	_, err = response.Write(respContent)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
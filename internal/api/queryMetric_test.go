@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service"
+	"github.com/e1m0re/grdn/internal/service/metrics"
+	"github.com/e1m0re/grdn/internal/service/metrics/mocks"
+)
+
+func TestHandler_queryMetric(t *testing.T) {
+	from := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	type args struct {
+		ctx context.Context
+		url string
+	}
+	type want struct {
+		expectedHeaders      map[string]string
+		expectedResponseBody string
+		expectedStatusCode   int
+	}
+	tests := []struct {
+		name         string
+		mockServices func() *service.ServerServices
+		args         args
+		want         want
+	}{
+		{
+			name: "Invalid from",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				ctx: context.Background(),
+				url: "/query?type=gauge&name=metric1&from=yesterday",
+			},
+			want: want{
+				expectedStatusCode:   http.StatusBadRequest,
+				expectedHeaders:      make(map[string]string),
+				expectedResponseBody: "error parsing from: parsing time \"yesterday\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"yesterday\" as \"2006\"\n",
+			},
+		},
+		{
+			name: "Invalid step",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("QueryMetric", mock.Anything, models.GaugeType, "metric1", models.Labels(nil), from, to, time.Nanosecond).
+					Return(nil, metrics.ErrInvalidStep)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx: context.Background(),
+				url: "/query?type=gauge&name=metric1&from=2024-04-11T13:00:00Z&to=2024-04-11T14:00:00Z&step=1ns",
+			},
+			want: want{
+				expectedStatusCode:   http.StatusBadRequest,
+				expectedHeaders:      make(map[string]string),
+				expectedResponseBody: "invalid step\n",
+			},
+		},
+		{
+			name: "QueryMetric failed",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("QueryMetric", mock.Anything, models.GaugeType, "metric1", models.Labels(nil), from, to, time.Minute).
+					Return(nil, errors.New("something wrong"))
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx: context.Background(),
+				url: "/query?type=gauge&name=metric1&from=2024-04-11T13:00:00Z&to=2024-04-11T14:00:00Z",
+			},
+			want: want{
+				expectedStatusCode:   http.StatusInternalServerError,
+				expectedHeaders:      make(map[string]string),
+				expectedResponseBody: "something wrong\n",
+			},
+		},
+		{
+			name: "Successfully case",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("QueryMetric", mock.Anything, models.GaugeType, "metric1", models.Labels(nil), from, to, 30*time.Minute).
+					Return([]models.Bucket{
+						{Start: from, Min: 1, Max: 3, Avg: 2, Sum: 4, Last: 3, Rate: 0.5, Count: 2},
+					}, nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx: context.Background(),
+				url: "/query?type=gauge&name=metric1&from=1712840400&to=2024-04-11T14:00:00Z&step=30m",
+			},
+			want: want{
+				expectedStatusCode:   http.StatusOK,
+				expectedHeaders:      map[string]string{"Content-Type": "application/json"},
+				expectedResponseBody: `{"from":"2024-04-11T13:00:00Z","to":"2024-04-11T14:00:00Z","id":"metric1","type":"gauge","step":"30m0s","buckets":[{"start":"2024-04-11T13:00:00Z","min":1,"max":3,"avg":2,"sum":4,"last":3,"rate":0.5,"count":2}]}`,
+			},
+		},
+		{
+			name: "Successfully case (labels)",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("QueryMetric", mock.Anything, models.GaugeType, "CPUutilization", models.Labels{"cpu": "3"}, from, to, time.Hour).
+					Return([]models.Bucket{}, nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				ctx: context.Background(),
+				url: "/query?type=gauge&name=CPUutilization&label=cpu:3&from=1712840400&to=1712844000&step=1h",
+			},
+			want: want{
+				expectedStatusCode:   http.StatusOK,
+				expectedHeaders:      map[string]string{"Content-Type": "application/json"},
+				expectedResponseBody: `{"from":"2024-04-11T13:00:00Z","to":"2024-04-11T14:00:00Z","labels":{"cpu":"3"},"id":"CPUutilization","type":"gauge","step":"1h0m0s","buckets":[]}`,
+			},
+		},
+		{
+			name: "Invalid label",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				ctx: context.Background(),
+				url: "/query?type=gauge&name=CPUutilization&label=:3",
+			},
+			want: want{
+				expectedStatusCode:   http.StatusBadRequest,
+				expectedHeaders:      make(map[string]string),
+				expectedResponseBody: "invalid label \":3\", expected name:value\n",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			services := test.mockServices()
+			handler := NewHandler(services, 0, nil)
+			router := handler.NewRouter(nil, 0, "", nil, false)
+
+			req, err := http.NewRequestWithContext(test.args.ctx, http.MethodGet, test.args.url, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, test.want.expectedStatusCode, rr.Code)
+			for key, value := range test.want.expectedHeaders {
+				require.Equal(t, value, rr.Header().Get(key))
+			}
+			require.Equal(t, test.want.expectedResponseBody, rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,3 @@
+// Package statsd implements the listener of the StatsD line protocol. Counters and gauges are stored as metrics of
+// the same types, timers, histograms, distributions and sets are dropped.
+package statsd
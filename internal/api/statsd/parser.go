@@ -0,0 +1,80 @@
+package statsd
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	counterType      = "c"
+	gaugeType        = "g"
+	timerType        = "ms"
+	histogramType    = "h"
+	distributionType = "d"
+	setType          = "s"
+)
+
+var (
+	// ErrMalformedLine is the error returned when the line doesn't match the format name:value|type[|@rate][|#tags].
+	ErrMalformedLine = errors.New("malformed line")
+	// ErrUnknownType is the error returned when the type of the sample is unknown.
+	ErrUnknownType = errors.New("unknown type")
+)
+
+type sample struct {
+	name  string
+	mType string
+	value float64
+	// relative is true for gauges with the explicit sign: the value is the delta to the current value of the gauge.
+	relative bool
+}
+
+// parseLine parses the line of StatsD protocol. Values of counters are scaled by the sample rate. Tags and other
+// extension fields are ignored.
+func parseLine(line string) (sample, error) {
+	name, rest, ok := strings.Cut(line, ":")
+	if !ok || len(name) == 0 {
+		return sample{}, ErrMalformedLine
+	}
+
+	fields := strings.Split(rest, "|")
+	if len(fields) < 2 {
+		return sample{}, ErrMalformedLine
+	}
+
+	s := sample{name: name, mType: fields[1]}
+	switch s.mType {
+	case counterType, gaugeType, timerType, histogramType, distributionType:
+	case setType:
+		// Members of sets are arbitrary strings.
+		return s, nil
+	default:
+		return sample{}, ErrUnknownType
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return sample{}, ErrMalformedLine
+	}
+	s.value = value
+	s.relative = s.mType == gaugeType && (fields[0][0] == '+' || fields[0][0] == '-')
+
+	for _, field := range fields[2:] {
+		if !strings.HasPrefix(field, "@") {
+			continue
+		}
+
+		var rate float64
+		rate, err = strconv.ParseFloat(field[1:], 64)
+		if err != nil || rate <= 0 || rate > 1 {
+			return sample{}, ErrMalformedLine
+		}
+		if s.mType == counterType {
+			s.value /= rate
+		}
+	}
+
+	return s, nil
+}
@@ -0,0 +1,82 @@
+package statsd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseLine(t *testing.T) {
+	type want struct {
+		err    error
+		sample sample
+	}
+	tests := []struct {
+		line string
+		want want
+	}{
+		{
+			line: "requests:3|c",
+			want: want{sample: sample{name: "requests", mType: counterType, value: 3}},
+		},
+		{
+			line: "requests:1|c|@0.25",
+			want: want{sample: sample{name: "requests", mType: counterType, value: 4}},
+		},
+		{
+			line: "app.load:0.75|g|#host:web1",
+			want: want{sample: sample{name: "app.load", mType: gaugeType, value: 0.75}},
+		},
+		{
+			line: "app.load:+1.5|g",
+			want: want{sample: sample{name: "app.load", mType: gaugeType, value: 1.5, relative: true}},
+		},
+		{
+			line: "app.load:-2|g",
+			want: want{sample: sample{name: "app.load", mType: gaugeType, value: -2, relative: true}},
+		},
+		{
+			line: "latency:320|ms|@0.5",
+			want: want{sample: sample{name: "latency", mType: timerType, value: 320}},
+		},
+		{
+			line: "users:alice|s",
+			want: want{sample: sample{name: "users", mType: setType}},
+		},
+		{
+			line: "requests",
+			want: want{err: ErrMalformedLine},
+		},
+		{
+			line: ":1|c",
+			want: want{err: ErrMalformedLine},
+		},
+		{
+			line: "requests:1",
+			want: want{err: ErrMalformedLine},
+		},
+		{
+			line: "requests:one|c",
+			want: want{err: ErrMalformedLine},
+		},
+		{
+			line: "requests:NaN|c",
+			want: want{err: ErrMalformedLine},
+		},
+		{
+			line: "requests:1|c|@2",
+			want: want{err: ErrMalformedLine},
+		},
+		{
+			line: "requests:1|x",
+			want: want{err: ErrUnknownType},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := parseLine(tt.line)
+			assert.ErrorIs(t, err, tt.want.err)
+			assert.Equal(t, tt.want.sample, got)
+		})
+	}
+}
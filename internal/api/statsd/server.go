@@ -0,0 +1,99 @@
+package statsd
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net"
+	"strings"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service/ingest"
+)
+
+// maxPacketSize is the maximum size of UDP payload.
+const maxPacketSize = 65535
+
+// Server is the listener of StatsD packets.
+type Server struct {
+	batcher ingest.Batcher
+	// trustedSubnet is nil if packets are accepted from any address.
+	trustedSubnet *net.IPNet
+}
+
+// NewServer is Server constructor. Packets from addresses outside the trusted subnet are dropped if it is not nil.
+func NewServer(batcher ingest.Batcher, trustedSubnet *net.IPNet) *Server {
+	return &Server{
+		batcher:       batcher,
+		trustedSubnet: trustedSubnet,
+	}
+}
+
+// Serve reads packets from the connection until the context is done. The connection is closed on exit.
+func (s *Server) Serve(ctx context.Context, conn net.PacketConn) error {
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		if !s.trusted(addr) {
+			slog.Debug("statsd packet from untrusted address is dropped", slog.String("remote_addr", addr.String()))
+			continue
+		}
+
+		s.handlePacket(string(buf[:n]))
+	}
+}
+
+// handlePacket adds samples of the packet to the batch. Valid lines of the malformed packet are not discarded,
+// the packet is counted in StatsDMalformedPackets counter.
+func (s *Server) handlePacket(packet string) {
+	malformed := false
+	for _, line := range strings.Split(packet, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		sample, err := parseLine(line)
+		if err != nil {
+			slog.Debug("malformed statsd line", slog.String("line", line), slog.String("error", err.Error()))
+			malformed = true
+			continue
+		}
+
+		switch {
+		case sample.mType == counterType:
+			s.batcher.AddCounter(sample.name, nil, int64(math.Round(sample.value)))
+		case sample.mType == gaugeType && sample.relative:
+			s.batcher.AddGaugeDelta(sample.name, nil, sample.value)
+		case sample.mType == gaugeType:
+			s.batcher.SetGauge(sample.name, nil, sample.value)
+		}
+	}
+
+	if malformed {
+		s.batcher.AddCounter(models.StatsDMalformedPackets, nil, 1)
+	}
+}
+
+func (s *Server) trusted(addr net.Addr) bool {
+	if s.trustedSubnet == nil {
+		return true
+	}
+
+	udpAddr, ok := addr.(*net.UDPAddr)
+
+	return ok && s.trustedSubnet.Contains(udpAddr.IP)
+}
@@ -0,0 +1,119 @@
+package statsd
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service/ingest/mocks"
+)
+
+func TestServer_handlePacket(t *testing.T) {
+	tests := []struct {
+		mockBatcherFunc func(mockBatcher *mocks.Batcher)
+		name            string
+		packet          string
+	}{
+		{
+			name:   "counters and gauges",
+			packet: "requests:2|c\nrequests:1|c|@0.5\napp.load:0.5|g\napp.load:-0.25|g\n",
+			mockBatcherFunc: func(mockBatcher *mocks.Batcher) {
+				mockBatcher.On("AddCounter", "requests", models.Labels(nil), int64(2)).Return().Twice()
+				mockBatcher.On("SetGauge", "app.load", models.Labels(nil), 0.5).Return().Once()
+				mockBatcher.On("AddGaugeDelta", "app.load", models.Labels(nil), -0.25).Return().Once()
+			},
+		},
+		{
+			name:   "timers and sets are dropped",
+			packet: "latency:320|ms\nusers:alice|s",
+			mockBatcherFunc: func(mockBatcher *mocks.Batcher) {
+			},
+		},
+		{
+			name:   "malformed packet",
+			packet: "requests:2|c\nrequests:two|c\nrequests|c",
+			mockBatcherFunc: func(mockBatcher *mocks.Batcher) {
+				mockBatcher.On("AddCounter", "requests", models.Labels(nil), int64(2)).Return().Once()
+				mockBatcher.On("AddCounter", models.StatsDMalformedPackets, models.Labels(nil), int64(1)).Return().Once()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBatcher := mocks.NewBatcher(t)
+			tt.mockBatcherFunc(mockBatcher)
+
+			NewServer(mockBatcher, nil).handlePacket(tt.packet)
+		})
+	}
+}
+
+func TestServer_Serve(t *testing.T) {
+	_, loopback, err := net.ParseCIDR("127.0.0.0/8")
+	require.NoError(t, err)
+	_, other, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+
+	tests := []struct {
+		trustedSubnet *net.IPNet
+		name          string
+		accepted      bool
+	}{
+		{
+			name:     "any address",
+			accepted: true,
+		},
+		{
+			name:          "trusted address",
+			trustedSubnet: loopback,
+			accepted:      true,
+		},
+		{
+			name:          "untrusted address",
+			trustedSubnet: other,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			require.NoError(t, err)
+
+			received := make(chan struct{}, 1)
+			mockBatcher := mocks.NewBatcher(t)
+			if tt.accepted {
+				mockBatcher.
+					On("AddCounter", "requests", models.Labels(nil), int64(1)).
+					Run(func(args mock.Arguments) { received <- struct{}{} }).
+					Return().
+					Once()
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+			go func() {
+				done <- NewServer(mockBatcher, tt.trustedSubnet).Serve(ctx, conn)
+			}()
+
+			client, err := net.Dial("udp", conn.LocalAddr().String())
+			require.NoError(t, err)
+			defer client.Close()
+			_, err = client.Write([]byte("requests:1|c"))
+			require.NoError(t, err)
+
+			select {
+			case <-received:
+			case <-time.After(200 * time.Millisecond):
+				assert.False(t, tt.accepted, "packet is not received")
+			}
+
+			cancel()
+			assert.NoError(t, <-done)
+		})
+	}
+}
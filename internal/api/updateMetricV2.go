@@ -14,7 +14,7 @@ func (h *Handler) updateMetricV2(response http.ResponseWriter, request *http.Req
 	var buf bytes.Buffer
 	_, err := buf.ReadFrom(request.Body)
 	if err != nil {
-		http.Error(response, err.Error(), http.StatusBadRequest)
+		http.Error(response, err.Error(), readBodyStatus(err))
 		return
 	}
 
@@ -54,6 +54,24 @@ func TestHandler_updateMetric(t *testing.T) {
 				expectedResponseBody: "",
 			},
 		},
+		{
+			name: "Histogram value in URL",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				ctx:    context.Background(),
+				method: http.MethodPost,
+				path:   fmt.Sprintf("/update/%s/GCPause/0.5", models.HistogramType),
+			},
+			want: want{
+				expectedStatusCode:   http.StatusBadRequest,
+				expectedHeaders:      make(map[string]string),
+				expectedResponseBody: "",
+			},
+		},
 		{
 			name: "UpdateMetric failed",
 			mockServices: func() *service.ServerServices {
@@ -104,8 +122,8 @@ func TestHandler_updateMetric(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			services := test.mockServices()
-			handler := NewHandler(services)
-			router := handler.NewRouter("", "")
+			handler := NewHandler(services, 0, nil)
+			router := handler.NewRouter(nil, 0, "", nil, false)
 
 			req, err := http.NewRequestWithContext(test.args.ctx, test.args.method, test.args.path, nil)
 			require.NoError(t, err)
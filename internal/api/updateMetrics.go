@@ -15,7 +15,7 @@ func (h *Handler) updateMetricsList(response http.ResponseWriter, request *http.
 	var buf bytes.Buffer
 	_, err := buf.ReadFrom(request.Body)
 	if err != nil {
-		http.Error(response, err.Error(), http.StatusBadRequest)
+		http.Error(response, err.Error(), readBodyStatus(err))
 		return
 	}
 
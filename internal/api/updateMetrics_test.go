@@ -2,17 +2,29 @@ package api
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/e1m0re/grdn/internal/models"
 	"github.com/e1m0re/grdn/internal/service"
+	"github.com/e1m0re/grdn/internal/service/encryption"
 	"github.com/e1m0re/grdn/internal/service/metrics/mocks"
+	"github.com/e1m0re/grdn/internal/service/signing"
 )
 
 func TestHandler_updateMetricsList(t *testing.T) {
@@ -126,8 +138,8 @@ func TestHandler_updateMetricsList(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			services := test.mockServices()
-			handler := NewHandler(services)
-			router := handler.NewRouter("", "")
+			handler := NewHandler(services, 0, nil)
+			router := handler.NewRouter(nil, 0, "", nil, false)
 
 			req, err := http.NewRequestWithContext(test.args.ctx, test.args.method, test.args.path, bytes.NewReader([]byte(test.args.body)))
 			require.NoError(t, err)
@@ -143,3 +155,156 @@ func TestHandler_updateMetricsList(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_updateMetricsList_encrypted(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	privateKeyFile := filepath.Join(dir, "private.pem")
+	publicKeyFile := filepath.Join(dir, "public.pem")
+	err = os.WriteFile(privateKeyFile, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	}), 0600)
+	require.NoError(t, err)
+	err = os.WriteFile(publicKeyFile, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PUBLIC KEY",
+		Bytes: x509.MarshalPKCS1PublicKey(&privateKey.PublicKey),
+	}), 0600)
+	require.NoError(t, err)
+
+	encryptor, err := encryption.NewEncryptor(publicKeyFile)
+	require.NoError(t, err)
+
+	// The batch is much larger than the RSA key and than one read buffer of the server.
+	metricsList := make(models.MetricsList, 0, 5000)
+	for i := 0; i < 5000; i++ {
+		value := float64(i)
+		metricsList = append(metricsList, &models.Metric{ID: fmt.Sprintf("metric%d", i), MType: models.GaugeType, Value: &value})
+	}
+	plaintext, err := json.Marshal(metricsList)
+	require.NoError(t, err)
+	ciphertext, err := encryptor.Encrypt(plaintext)
+	require.NoError(t, err)
+
+	type args struct {
+		body []byte
+	}
+	type want struct {
+		expectedStatusCode int
+	}
+	tests := []struct {
+		name         string
+		mockServices func() *service.ServerServices
+		args         args
+		want         want
+	}{
+		{
+			name: "Body is not encrypted",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				body: plaintext,
+			},
+			want: want{
+				expectedStatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Body is too large",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				body: bytes.Repeat(ciphertext, 200),
+			},
+			want: want{
+				expectedStatusCode: http.StatusRequestEntityTooLarge,
+			},
+		},
+		{
+			name: "Successfully test",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockMetricsManager.
+					On("UpdateMetrics", mock.Anything, mock.MatchedBy(func(list models.MetricsList) bool {
+						return len(list) == len(metricsList)
+					})).
+					Return(nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				body: ciphertext,
+			},
+			want: want{
+				expectedStatusCode: http.StatusOK,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			services := test.mockServices()
+			handler := NewHandler(services, 0, nil)
+			router := handler.NewRouter(nil, 0, privateKeyFile, nil, false)
+
+			req, err := http.NewRequest(http.MethodPost, "/updates/", bytes.NewReader(test.args.body))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, test.want.expectedStatusCode, rr.Code)
+		})
+	}
+}
+
+func TestHandler_updateMetricsList_gzipBomb(t *testing.T) {
+	// The compressed body is small, the extracted one exceeds the limit of the request body.
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	_, err := gzipWriter.Write(bytes.Repeat([]byte(" "), maxRequestBodySize+1))
+	require.NoError(t, err)
+	require.NoError(t, gzipWriter.Close())
+	require.Less(t, buf.Len(), maxRequestBodySize/100)
+
+	tests := []struct {
+		signKeys signing.KeySet
+		headers  map[string]string
+		name     string
+	}{
+		{
+			name: "Body is read by handler",
+		},
+		{
+			name:     "Body is read by sign checking",
+			signKeys: signing.KeySet{"": []byte("secret")},
+			headers:  map[string]string{signing.HashHeader: "sum"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewHandler(&service.ServerServices{MetricsManager: mocks.NewManager(t)}, 0, nil)
+			router := handler.NewRouter(test.signKeys, 0, "", nil, false)
+
+			req := httptest.NewRequest(http.MethodPost, "/updates/", bytes.NewReader(buf.Bytes()))
+			req.Header.Set("Content-Encoding", "gzip")
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		})
+	}
+}
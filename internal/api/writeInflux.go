@@ -0,0 +1,67 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/e1m0re/grdn/internal/api/influx"
+	"github.com/e1m0re/grdn/internal/service/ingest"
+)
+
+// writeInflux stores points of InfluxDB line protocol. Valid lines are stored even if other lines are malformed,
+// errors of malformed lines are returned with 400 status one per line. Timestamps are in units of the precision
+// query parameter.
+func (h *Handler) writeInflux(response http.ResponseWriter, request *http.Request) {
+	precision, err := influx.ParsePrecision(request.URL.Query().Get("precision"))
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	batch := ingest.NewBatch()
+	var errs []error
+	scanner := bufio.NewScanner(request.Body)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var point influx.Point
+		point, err = influx.ParseLine(line, precision)
+		if err != nil {
+			errs = append(errs, &ingest.LineError{Line: n, Err: err})
+			continue
+		}
+
+		for _, field := range point.Fields {
+			h.ingestOptions.Rule.Add(batch, influx.MetricName(point.Measurement, field.Key), field.Key, field.Value, point.Time)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		http.Error(response, err.Error(), readBodyStatus(err))
+		return
+	}
+
+	if batch.Len() > 0 {
+		ctx, cancelFunc := context.WithCancel(request.Context())
+		defer cancelFunc()
+		err = batch.Store(ctx, h.services.MetricsManager)
+		if err != nil {
+			slog.Error("update metrics error", slog.String("error", err.Error()))
+			response.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	if len(errs) > 0 {
+		http.Error(response, errors.Join(errs...).Error(), http.StatusBadRequest)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service"
+	"github.com/e1m0re/grdn/internal/service/ingest"
+	"github.com/e1m0re/grdn/internal/service/metrics/mocks"
+	"github.com/e1m0re/grdn/internal/service/signing"
+)
+
+func TestHandler_writeInflux(t *testing.T) {
+	usage := 0.5
+	delta := int64(5)
+	sampleTime := time.Unix(1729150000, 0)
+	options := &IngestOptions{Rule: ingest.TypeRule{CounterFields: []string{"count"}}, Influx: true}
+
+	type args struct {
+		options *IngestOptions
+		body    string
+		path    string
+	}
+	type want struct {
+		expectedResponseBody string
+		metrics              models.MetricsList
+		expectedStatusCode   int
+	}
+	tests := []struct {
+		mockServices func() *service.ServerServices
+		name         string
+		args         args
+		want         want
+	}{
+		{
+			name: "Endpoint is disabled",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				body: "cpu usage=0.5",
+				path: "/write",
+			},
+			want: want{
+				expectedStatusCode:   http.StatusNotFound,
+				expectedResponseBody: "404 page not found\n",
+			},
+		},
+		{
+			name: "UpdateMetrics failed",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockUpdateMetricsFunc(mockMetricsManager)
+				mockMetricsManager.
+					On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
+					Return(errors.New("something wrong"))
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				options: options,
+				body:    "cpu usage=0.5",
+				path:    "/write",
+			},
+			want: want{
+				expectedStatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Malformed lines",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockUpdateMetricsFunc(mockMetricsManager)
+				mockMetricsManager.
+					On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
+					Return(nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				options: options,
+				body:    "cpu\ncpu usage=0.5\ncpu usage=high\n",
+				path:    "/write",
+			},
+			want: want{
+				metrics: models.MetricsList{
+					{Value: &usage, MType: models.GaugeType, ID: "cpu_usage"},
+				},
+				expectedStatusCode:   http.StatusBadRequest,
+				expectedResponseBody: "line 1: malformed line\nline 3: invalid field\n",
+			},
+		},
+		{
+			name: "Successfully test",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockUpdateMetricsFunc(mockMetricsManager)
+				mockMetricsManager.
+					On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
+					Return(nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				options: options,
+				body:    "# comment\nhttp,host=web1 count=2i 1729150000000000000\n\nhttp,host=web2 count=3i,note=\"a b\"\ncpu,host=web1 usage=0.5",
+				path:    "/api/v2/write",
+			},
+			want: want{
+				metrics: models.MetricsList{
+					{Delta: &delta, MType: models.CounterType, ID: "http_count", Timestamp: &sampleTime},
+					{Value: &usage, MType: models.GaugeType, ID: "cpu_usage"},
+				},
+				expectedStatusCode: http.StatusNoContent,
+			},
+		},
+		{
+			name: "Timestamps in units of precision",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockUpdateMetricsFunc(mockMetricsManager)
+				mockMetricsManager.
+					On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
+					Return(nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				options: options,
+				body:    "cpu,host=web1 usage=0.5 1729150000",
+				path:    "/write?precision=s",
+			},
+			want: want{
+				metrics: models.MetricsList{
+					{Value: &usage, MType: models.GaugeType, ID: "cpu_usage", Timestamp: &sampleTime},
+				},
+				expectedStatusCode: http.StatusNoContent,
+			},
+		},
+		{
+			name: "Invalid precision",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				options: options,
+				body:    "cpu,host=web1 usage=0.5 1729150000",
+				path:    "/write?precision=d",
+			},
+			want: want{
+				expectedStatusCode:   http.StatusBadRequest,
+				expectedResponseBody: "invalid precision\n",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			services := test.mockServices()
+			handler := NewHandler(services, 0, test.args.options)
+			router := handler.NewRouter(nil, 0, "", nil, false)
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, test.args.path, bytes.NewReader([]byte(test.args.body)))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, test.want.expectedStatusCode, rr.Code)
+			require.Equal(t, test.want.expectedResponseBody, rr.Body.String())
+			if test.want.metrics != nil {
+				services.MetricsManager.(*mocks.Manager).AssertCalled(t, "UpdateMetrics", mock.Anything, mock.MatchedBy(func(list models.MetricsList) bool {
+					return assert.ElementsMatch(t, test.want.metrics, list)
+				}))
+			}
+		})
+	}
+}
+
+func TestHandler_writeInfluxSecuredRouter(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.0.0/24")
+	require.NoError(t, err)
+	keys := signing.KeySet{"": []byte("secret")}
+
+	tests := []struct {
+		subnet     *net.IPNet
+		name       string
+		remoteAddr string
+		want       int
+		insecure   bool
+	}{
+		{
+			name:       "Unsigned request from trusted subnet without X-Real-IP",
+			subnet:     subnet,
+			remoteAddr: "10.0.0.15:51234",
+			want:       http.StatusNoContent,
+		},
+		{
+			name:       "Request from untrusted address",
+			subnet:     subnet,
+			remoteAddr: "192.168.1.15:51234",
+			want:       http.StatusForbidden,
+		},
+		{
+			name:       "Unsigned request without trusted subnet",
+			remoteAddr: "192.168.1.15:51234",
+			want:       http.StatusBadRequest,
+		},
+		{
+			name:       "Unsigned request in insecure mode",
+			remoteAddr: "192.168.1.15:51234",
+			insecure:   true,
+			want:       http.StatusNoContent,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockMetricsManager := mocks.NewManager(t)
+			mockUpdateMetricsFunc(mockMetricsManager)
+			mockMetricsManager.On("UpdateMetrics", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			options := &IngestOptions{Influx: true, Insecure: test.insecure}
+			handler := NewHandler(&service.ServerServices{MetricsManager: mockMetricsManager}, 0, options)
+			router := handler.NewRouter(keys, 0, "", test.subnet, false)
+
+			req := httptest.NewRequest(http.MethodPost, "/write", bytes.NewReader([]byte("cpu usage=0.5")))
+			req.RemoteAddr = test.remoteAddr
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, test.want, rr.Code)
+		})
+	}
+}
+
+// mockUpdateMetricsFunc makes the mock build batches passed to UpdateMetricsFunc and update them with UpdateMetrics, so
+// tests can check updated metrics the same way for both methods.
+func mockUpdateMetricsFunc(mockMetricsManager *mocks.Manager) {
+	mockMetricsManager.
+		On("UpdateMetricsFunc", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(ctx context.Context) (models.MetricsList, error)) error {
+			list, err := fn(ctx)
+			if err != nil {
+				return err
+			}
+
+			return mockMetricsManager.UpdateMetrics(ctx, list)
+		}).
+		Maybe()
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service/ingest"
+)
+
+// Content types of OTLP/HTTP requests, responses are encoded the same way as the request.
+const (
+	otlpProtobufType = "application/x-protobuf"
+	otlpJSONType     = "application/json"
+)
+
+var (
+	errOTLPUnsupportedType        = errors.New("unsupported metric type")
+	errOTLPUnsupportedTemporality = errors.New("unsupported aggregation temporality")
+)
+
+// writeOTLP stores metrics of OTLP/HTTP export request (ExportMetricsServiceRequest encoded as protobuf or JSON).
+// Gauges are stored as gauges, sums are counters if they are monotonic and gauges otherwise. Cumulative sums are
+// stored as cumulative values and delta sums are added to the stored values. Attributes of the resource and the data
+// point are stored as labels of the series, the data point attributes win. Data points of other metric types are
+// rejected and reported in the partial success of the response. Failed updates are answered with 503 status to make
+// the exporter retry the request.
+func (h *Handler) writeOTLP(response http.ResponseWriter, request *http.Request) {
+	contentType, _, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	if err != nil || (contentType != otlpProtobufType && contentType != otlpJSONType) {
+		http.Error(response, "unsupported content type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		writeOTLPResponse(response, contentType, readBodyStatus(err), status.New(codes.InvalidArgument, err.Error()).Proto())
+		return
+	}
+
+	var exportRequest colmetricspb.ExportMetricsServiceRequest
+	if contentType == otlpJSONType {
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(body, &exportRequest)
+	} else {
+		err = proto.Unmarshal(body, &exportRequest)
+	}
+	if err != nil {
+		writeOTLPResponse(response, contentType, http.StatusBadRequest, status.New(codes.InvalidArgument, err.Error()).Proto())
+		return
+	}
+
+	batch := ingest.NewBatch()
+	var rejected int64
+	var errs []error
+	for _, resourceMetrics := range exportRequest.GetResourceMetrics() {
+		resource := resourceMetrics.GetResource().GetAttributes()
+		for _, scopeMetrics := range resourceMetrics.GetScopeMetrics() {
+			for _, metric := range scopeMetrics.GetMetrics() {
+				if n, metricErr := addOTLPMetric(batch, metric, resource); metricErr != nil {
+					rejected += n
+					errs = append(errs, fmt.Errorf("metric %q: %w", metric.GetName(), metricErr))
+				}
+			}
+		}
+	}
+
+	if batch.Len() > 0 {
+		ctx, cancelFunc := context.WithCancel(request.Context())
+		defer cancelFunc()
+		err = batch.Store(ctx, h.services.MetricsManager)
+		if err != nil {
+			slog.Error("update metrics error", slog.String("error", err.Error()))
+			writeOTLPResponse(response, contentType, http.StatusServiceUnavailable, status.New(codes.Unavailable, "update metrics error").Proto())
+			return
+		}
+	}
+
+	exportResponse := &colmetricspb.ExportMetricsServiceResponse{}
+	if len(errs) > 0 {
+		exportResponse.PartialSuccess = &colmetricspb.ExportMetricsPartialSuccess{
+			RejectedDataPoints: rejected,
+			ErrorMessage:       errors.Join(errs...).Error(),
+		}
+	}
+	writeOTLPResponse(response, contentType, http.StatusOK, exportResponse)
+}
+
+// addOTLPMetric adds data points of the metric to the batch. If the metric can't be stored, the number of its data
+// points is returned with the error. Data points of monotonic sums with fractional values are rejected the same way,
+// other data points are stored. Data points without values are skipped. History is recorded at times of data points.
+func addOTLPMetric(batch *ingest.Batch, metric *metricspb.Metric, resource []*commonpb.KeyValue) (int64, error) {
+	var points []*metricspb.NumberDataPoint
+	var add func(name models.MetricName, labels models.Labels, value float64)
+	// addCounter is set instead of add for monotonic sums.
+	var addCounter func(name models.MetricName, labels models.Labels, value int64)
+	switch data := metric.GetData().(type) {
+	case *metricspb.Metric_Gauge:
+		points = data.Gauge.GetDataPoints()
+		add = batch.SetGauge
+	case *metricspb.Metric_Sum:
+		points = data.Sum.GetDataPoints()
+		monotonic := data.Sum.GetIsMonotonic()
+		switch data.Sum.GetAggregationTemporality() {
+		case metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE:
+			add = batch.SetGauge
+			if monotonic {
+				addCounter = batch.SetCounter
+			}
+		case metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA:
+			add = batch.AddGaugeDelta
+			if monotonic {
+				addCounter = batch.AddCounter
+			}
+		default:
+			return int64(len(points)), errOTLPUnsupportedTemporality
+		}
+	case *metricspb.Metric_Histogram:
+		return int64(len(data.Histogram.GetDataPoints())), errOTLPUnsupportedType
+	case *metricspb.Metric_ExponentialHistogram:
+		return int64(len(data.ExponentialHistogram.GetDataPoints())), errOTLPUnsupportedType
+	case *metricspb.Metric_Summary:
+		return int64(len(data.Summary.GetDataPoints())), errOTLPUnsupportedType
+	default:
+		return 0, errOTLPUnsupportedType
+	}
+
+	var rejected int64
+	var errs []error
+	for _, point := range points {
+		value, ok := otlpValue(point)
+		if !ok {
+			continue
+		}
+
+		labels := make(models.Labels, len(resource)+len(point.GetAttributes()))
+		for _, attributes := range [][]*commonpb.KeyValue{resource, point.GetAttributes()} {
+			for _, attribute := range attributes {
+				labels[attribute.GetKey()] = otlpAttributeValue(attribute.GetValue())
+			}
+		}
+		if addCounter == nil {
+			add(metric.GetName(), labels, value)
+		} else {
+			counter, err := counterValue(value)
+			if err != nil {
+				rejected++
+				errs = append(errs, err)
+				continue
+			}
+			addCounter(metric.GetName(), labels, counter)
+		}
+		if ts := point.GetTimeUnixNano(); ts > 0 && ts <= math.MaxInt64 {
+			batch.SetTime(metric.GetName(), labels, time.Unix(0, int64(ts)))
+		}
+	}
+
+	return rejected, errors.Join(errs...)
+}
+
+// otlpValue returns the value of the data point. Data points flagged as having no recorded value and non-finite
+// values are skipped.
+func otlpValue(point *metricspb.NumberDataPoint) (float64, bool) {
+	if point.GetFlags()&uint32(metricspb.DataPointFlags_DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK) != 0 {
+		return 0, false
+	}
+
+	switch value := point.GetValue().(type) {
+	case *metricspb.NumberDataPoint_AsDouble:
+		if math.IsNaN(value.AsDouble) || math.IsInf(value.AsDouble, 0) {
+			return 0, false
+		}
+		return value.AsDouble, true
+	case *metricspb.NumberDataPoint_AsInt:
+		return float64(value.AsInt), true
+	default:
+		return 0, false
+	}
+}
+
+// otlpAttributeValue formats the attribute value. Arrays and maps are formatted as [v1,v2] and {k1=v1,k2=v2}.
+func otlpAttributeValue(value *commonpb.AnyValue) string {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+	case *commonpb.AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(v.BytesValue)
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]string, 0, len(v.ArrayValue.GetValues()))
+		for _, item := range v.ArrayValue.GetValues() {
+			values = append(values, otlpAttributeValue(item))
+		}
+		return "[" + strings.Join(values, ",") + "]"
+	case *commonpb.AnyValue_KvlistValue:
+		values := make([]string, 0, len(v.KvlistValue.GetValues()))
+		for _, item := range v.KvlistValue.GetValues() {
+			values = append(values, item.GetKey()+"="+otlpAttributeValue(item.GetValue()))
+		}
+		return "{" + strings.Join(values, ",") + "}"
+	default:
+		return ""
+	}
+}
+
+// writeOTLPResponse writes the message encoded according to the content type of the request.
+func writeOTLPResponse(response http.ResponseWriter, contentType string, statusCode int, message proto.Message) {
+	var data []byte
+	var err error
+	if contentType == otlpJSONType {
+		data, err = protojson.Marshal(message)
+	} else {
+		data, err = proto.Marshal(message)
+	}
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response.Header().Set("Content-Type", contentType)
+	response.WriteHeader(statusCode)
+	if _, err = response.Write(data); err != nil {
+		slog.Error("write response error", slog.String("error", err.Error()))
+	}
+}
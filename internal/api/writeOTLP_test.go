@@ -0,0 +1,338 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service"
+	"github.com/e1m0re/grdn/internal/service/metrics/mocks"
+	"github.com/e1m0re/grdn/internal/service/signing"
+)
+
+func TestHandler_writeOTLP(t *testing.T) {
+	options := &IngestOptions{OTLP: true}
+	jsonBody, err := os.ReadFile(filepath.Join("testdata", "otlp_metrics.json"))
+	require.NoError(t, err)
+	var exportRequest colmetricspb.ExportMetricsServiceRequest
+	require.NoError(t, protojson.Unmarshal(jsonBody, &exportRequest))
+	protobufBody, err := proto.Marshal(&exportRequest)
+	require.NoError(t, err)
+
+	usage := 0.5
+	requests := int64(120)
+	jobs := int64(5)
+	queue := float64(3)
+	cpu := int64(7)
+	sampleTime := time.Unix(1729150000, 0)
+	metrics := models.MetricsList{
+		{Value: &usage, MType: models.GaugeType, ID: "memory_usage", Labels: models.Labels{"host": "web1", "service.name": "api"}, Timestamp: &sampleTime},
+		{Delta: &requests, MType: models.CounterType, ID: "http_requests_total", Labels: models.Labels{"method": "GET", "service.name": "api"}},
+		{Delta: &jobs, MType: models.CounterType, ID: "jobs_done", Labels: models.Labels{"service.name": "worker"}},
+		{Value: &queue, MType: models.GaugeType, ID: "queue_size", Labels: models.Labels{"primary": "true", "service.name": "api"}},
+	}
+	partialSuccess := &colmetricspb.ExportMetricsServiceResponse{
+		PartialSuccess: &colmetricspb.ExportMetricsPartialSuccess{
+			RejectedDataPoints: 2,
+			ErrorMessage:       `metric "request_duration": unsupported metric type`,
+		},
+	}
+	successfulServices := func() *service.ServerServices {
+		mockMetricsManager := mocks.NewManager(t)
+		mockUpdateMetricsFunc(mockMetricsManager)
+		mockMetricsManager.
+			On("GetMetric", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, nil)
+		mockMetricsManager.
+			On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
+			Return(nil)
+
+		return &service.ServerServices{
+			MetricsManager: mockMetricsManager,
+		}
+	}
+
+	type args struct {
+		options     *IngestOptions
+		contentType string
+		body        []byte
+	}
+	type want struct {
+		response           *colmetricspb.ExportMetricsServiceResponse
+		metrics            models.MetricsList
+		expectedStatusCode int
+	}
+	tests := []struct {
+		mockServices func() *service.ServerServices
+		name         string
+		args         args
+		want         want
+	}{
+		{
+			name: "Endpoint is disabled",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				options:     &IngestOptions{PrometheusWrite: true},
+				contentType: otlpProtobufType,
+				body:        protobufBody,
+			},
+			want: want{
+				expectedStatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "Unsupported content type",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				options:     options,
+				contentType: "text/plain",
+				body:        jsonBody,
+			},
+			want: want{
+				expectedStatusCode: http.StatusUnsupportedMediaType,
+			},
+		},
+		{
+			name: "Invalid protobuf",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				options:     options,
+				contentType: otlpProtobufType,
+				body:        protobufBody[:len(protobufBody)/2],
+			},
+			want: want{
+				expectedStatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Invalid JSON",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				options:     options,
+				contentType: otlpJSONType,
+				body:        []byte(`{"resourceMetrics":`),
+			},
+			want: want{
+				expectedStatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Empty request",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				options:     options,
+				contentType: otlpJSONType,
+				body:        []byte(`{}`),
+			},
+			want: want{
+				response:           &colmetricspb.ExportMetricsServiceResponse{},
+				expectedStatusCode: http.StatusOK,
+			},
+		},
+		{
+			name: "UpdateMetrics failed",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockUpdateMetricsFunc(mockMetricsManager)
+				mockMetricsManager.
+					On("GetMetric", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, nil)
+				mockMetricsManager.
+					On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
+					Return(errors.New("something wrong"))
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				options:     options,
+				contentType: otlpProtobufType,
+				body:        protobufBody,
+			},
+			want: want{
+				expectedStatusCode: http.StatusServiceUnavailable,
+			},
+		},
+		{
+			name:         "Fractional monotonic sum is rejected",
+			mockServices: successfulServices,
+			args: args{
+				options:     options,
+				contentType: otlpJSONType,
+				body: []byte(`{"resourceMetrics": [{"scopeMetrics": [{"metrics": [{
+					"name": "cpu_seconds_total",
+					"sum": {"aggregationTemporality": 2, "isMonotonic": true, "dataPoints": [
+						{"asDouble": 42.4, "attributes": [{"key": "cpu", "value": {"stringValue": "0"}}]},
+						{"asDouble": 7, "attributes": [{"key": "cpu", "value": {"stringValue": "1"}}]}
+					]}
+				}]}]}]}`),
+			},
+			want: want{
+				response: &colmetricspb.ExportMetricsServiceResponse{
+					PartialSuccess: &colmetricspb.ExportMetricsPartialSuccess{
+						RejectedDataPoints: 1,
+						ErrorMessage:       `metric "cpu_seconds_total": counter value is not integer: 42.4`,
+					},
+				},
+				metrics: models.MetricsList{
+					{Delta: &cpu, MType: models.CounterType, ID: "cpu_seconds_total", Labels: models.Labels{"cpu": "1"}},
+				},
+				expectedStatusCode: http.StatusOK,
+			},
+		},
+		{
+			name:         "Successfully test (protobuf)",
+			mockServices: successfulServices,
+			args: args{
+				options:     options,
+				contentType: otlpProtobufType,
+				body:        protobufBody,
+			},
+			want: want{
+				response:           partialSuccess,
+				metrics:            metrics,
+				expectedStatusCode: http.StatusOK,
+			},
+		},
+		{
+			name:         "Successfully test (JSON)",
+			mockServices: successfulServices,
+			args: args{
+				options:     options,
+				contentType: otlpJSONType + "; charset=utf-8",
+				body:        jsonBody,
+			},
+			want: want{
+				response:           partialSuccess,
+				metrics:            metrics,
+				expectedStatusCode: http.StatusOK,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			services := test.mockServices()
+			handler := NewHandler(services, 0, test.args.options)
+			router := handler.NewRouter(nil, 0, "", nil, false)
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/v1/metrics", bytes.NewReader(test.args.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", test.args.contentType)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, test.want.expectedStatusCode, rr.Code)
+			if test.want.response != nil {
+				var got colmetricspb.ExportMetricsServiceResponse
+				if rr.Header().Get("Content-Type") == otlpJSONType {
+					require.NoError(t, protojson.Unmarshal(rr.Body.Bytes(), &got))
+				} else {
+					require.Equal(t, otlpProtobufType, rr.Header().Get("Content-Type"))
+					require.NoError(t, proto.Unmarshal(rr.Body.Bytes(), &got))
+				}
+				require.True(t, proto.Equal(test.want.response, &got), "got %v", &got)
+			}
+			if test.want.metrics != nil {
+				services.MetricsManager.(*mocks.Manager).AssertCalled(t, "UpdateMetrics", mock.Anything, mock.MatchedBy(func(list models.MetricsList) bool {
+					return assert.ElementsMatch(t, test.want.metrics, list)
+				}))
+			}
+		})
+	}
+}
+
+func TestHandler_writeOTLPSecuredRouter(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.0.0/24")
+	require.NoError(t, err)
+	keys := signing.KeySet{"": []byte("secret")}
+	body, err := os.ReadFile(filepath.Join("testdata", "otlp_metrics.json"))
+	require.NoError(t, err)
+	signature, err := keys.Sum("", body)
+	require.NoError(t, err)
+
+	tests := []struct {
+		subnet   *net.IPNet
+		headers  map[string]string
+		name     string
+		want     int
+		insecure bool
+	}{
+		{
+			name:    "Signed request",
+			headers: map[string]string{signing.HashHeader: signature},
+			want:    http.StatusOK,
+		},
+		{
+			name:   "Unsigned request from trusted subnet",
+			subnet: subnet,
+			want:   http.StatusBadRequest,
+		},
+		{
+			name:     "Unsigned request in insecure mode",
+			insecure: true,
+			want:     http.StatusOK,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockMetricsManager := mocks.NewManager(t)
+			mockUpdateMetricsFunc(mockMetricsManager)
+			mockMetricsManager.On("GetMetric", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+			mockMetricsManager.On("UpdateMetrics", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			options := &IngestOptions{OTLP: true, Insecure: test.insecure}
+			handler := NewHandler(&service.ServerServices{MetricsManager: mockMetricsManager}, 0, options)
+			router := handler.NewRouter(keys, 0, "", test.subnet, false)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/metrics", bytes.NewReader(body))
+			req.RemoteAddr = "10.0.0.15:51234"
+			req.Header.Set("X-Real-IP", "10.0.0.15")
+			req.Header.Set("Content-Type", otlpJSONType)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, test.want, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/proto/prompb"
+	"github.com/e1m0re/grdn/internal/service/ingest"
+)
+
+// nameLabel is the label containing the name of Prometheus series.
+const nameLabel = "__name__"
+
+// errNonIntegralCounter is the error of the counter sample with fractional value. Counters are stored as integers, so
+// rounding would lose increments of counters like node_cpu_seconds_total.
+var errNonIntegralCounter = errors.New("counter value is not integer")
+
+// writePrometheus stores series of Prometheus remote write request (snappy-compressed protobuf WriteRequest). Only
+// the latest sample of each series is stored. Types of series are taken from metadata of the request: counters are
+// stored as cumulative values, unknown and other types are gauges. Series without metadata are typed by the ingest
+// rule. Counter samples with fractional values are rejected, other series are stored and rejected ones are reported
+// with 400 status. Failed updates are answered with 500 status to make Prometheus retry the request.
+func (h *Handler) writePrometheus(response http.ResponseWriter, request *http.Request) {
+	compressed, err := io.ReadAll(request.Body)
+	if err != nil {
+		http.Error(response, err.Error(), readBodyStatus(err))
+		return
+	}
+
+	// The decoded length is declared by the header, it is checked before the buffer of this size is allocated.
+	size, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if size > maxRequestBodySize {
+		http.Error(response, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var writeRequest prompb.WriteRequest
+	if err = proto.Unmarshal(data, &writeRequest); err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	types := make(map[string]prompb.MetricMetadata_MetricType, len(writeRequest.GetMetadata()))
+	for _, metadata := range writeRequest.GetMetadata() {
+		types[metadata.GetMetricFamilyName()] = metadata.GetType()
+	}
+
+	batch := ingest.NewBatch()
+	var errs []error
+	for _, series := range writeRequest.GetTimeseries() {
+		name, labels := seriesLabels(series.GetLabels())
+		sample := latestSample(series.GetSamples())
+		if len(name) == 0 || sample == nil {
+			continue
+		}
+
+		counter := h.ingestOptions.Rule.MetricType(name, "") == models.CounterType
+		if mType, ok := types[name]; ok {
+			counter = mType == prompb.MetricMetadata_COUNTER
+		}
+		if counter {
+			total, valueErr := counterValue(sample.GetValue())
+			if valueErr != nil {
+				errs = append(errs, fmt.Errorf("series %s%s: %w", name, labels, valueErr))
+				continue
+			}
+			batch.SetCounter(name, labels, total)
+		} else {
+			batch.SetGauge(name, labels, sample.GetValue())
+		}
+		if sample.GetTimestamp() > 0 {
+			batch.SetTime(name, labels, time.UnixMilli(sample.GetTimestamp()))
+		}
+	}
+
+	if batch.Len() > 0 {
+		ctx, cancelFunc := context.WithCancel(request.Context())
+		defer cancelFunc()
+		err = batch.Store(ctx, h.services.MetricsManager)
+		if err != nil {
+			slog.Error("update metrics error", slog.String("error", err.Error()))
+			response.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if len(errs) > 0 {
+		http.Error(response, errors.Join(errs...).Error(), http.StatusBadRequest)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
+
+// counterValue returns the value of the counter sample as integer. Fractional values are not rounded.
+func counterValue(value float64) (int64, error) {
+	if value != math.Trunc(value) {
+		return 0, fmt.Errorf("%w: %g", errNonIntegralCounter, value)
+	}
+
+	return int64(value), nil
+}
+
+// seriesLabels returns the name of the series and its other labels.
+func seriesLabels(labels []*prompb.Label) (models.MetricName, models.Labels) {
+	var name models.MetricName
+	other := make(models.Labels, len(labels))
+	for _, label := range labels {
+		if label.GetName() == nameLabel {
+			name = label.GetValue()
+			continue
+		}
+		other[label.GetName()] = label.GetValue()
+	}
+
+	return name, other
+}
+
+// latestSample returns the sample with the greatest timestamp. Stale markers and other non-finite values are skipped.
+func latestSample(samples []*prompb.Sample) *prompb.Sample {
+	var latest *prompb.Sample
+	for _, sample := range samples {
+		if math.IsNaN(sample.GetValue()) || math.IsInf(sample.GetValue(), 0) {
+			continue
+		}
+		if latest == nil || sample.GetTimestamp() >= latest.GetTimestamp() {
+			latest = sample
+		}
+	}
+
+	return latest
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service"
+	"github.com/e1m0re/grdn/internal/service/ingest"
+	"github.com/e1m0re/grdn/internal/service/metrics/mocks"
+	"github.com/e1m0re/grdn/internal/service/signing"
+)
+
+func TestHandler_writePrometheus(t *testing.T) {
+	options := &IngestOptions{Rule: ingest.TypeRule{CounterSuffix: "_total"}, PrometheusWrite: true}
+	fixture := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		require.NoError(t, err)
+		return data
+	}
+	requests := int64(120)
+	memory := 1.5e6
+	up := float64(1)
+	jobs := float64(7)
+	info := float64(3)
+	sampleTime := time.UnixMilli(1729150000000)
+	latestTime := time.UnixMilli(1729150015000)
+
+	type args struct {
+		options *IngestOptions
+		body    []byte
+	}
+	type want struct {
+		expectedBody       string
+		metrics            models.MetricsList
+		expectedStatusCode int
+	}
+	tests := []struct {
+		mockServices func() *service.ServerServices
+		name         string
+		args         args
+		want         want
+	}{
+		{
+			name: "Endpoint is disabled",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				options: &IngestOptions{Influx: true},
+				body:    fixture("remote_write.pb.snappy"),
+			},
+			want: want{
+				expectedStatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "Invalid compression",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				options: options,
+				body:    []byte("not snappy"),
+			},
+			want: want{
+				expectedStatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Declared decoded length exceeds limit",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				options: options,
+				// Snappy block starts with varint of the decoded length.
+				body: append(binary.AppendUvarint(nil, 1<<30), 0),
+			},
+			want: want{
+				expectedStatusCode: http.StatusRequestEntityTooLarge,
+			},
+		},
+		{
+			name: "Invalid protobuf",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				options: options,
+				body:    fixture("remote_write_invalid.pb.snappy"),
+			},
+			want: want{
+				expectedStatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Empty request",
+			mockServices: func() *service.ServerServices {
+				return &service.ServerServices{
+					MetricsManager: mocks.NewManager(t),
+				}
+			},
+			args: args{
+				options: options,
+				body:    fixture("remote_write_empty.pb.snappy"),
+			},
+			want: want{
+				expectedStatusCode: http.StatusNoContent,
+			},
+		},
+		{
+			name: "UpdateMetrics failed",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockUpdateMetricsFunc(mockMetricsManager)
+				mockMetricsManager.
+					On("GetMetric", mock.Anything, models.CounterType, mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
+					Return(nil, nil)
+				mockMetricsManager.
+					On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
+					Return(errors.New("something wrong"))
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				options: options,
+				body:    fixture("remote_write.pb.snappy"),
+			},
+			want: want{
+				expectedStatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "Successfully test (fractional counter is rejected)",
+			mockServices: func() *service.ServerServices {
+				mockMetricsManager := mocks.NewManager(t)
+				mockUpdateMetricsFunc(mockMetricsManager)
+				mockMetricsManager.
+					On("GetMetric", mock.Anything, models.CounterType, "http_requests_total", models.Labels{"job": "api", "method": "GET"}).
+					Return(nil, nil)
+				mockMetricsManager.
+					On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
+					Return(nil)
+
+				return &service.ServerServices{
+					MetricsManager: mockMetricsManager,
+				}
+			},
+			args: args{
+				options: options,
+				body:    fixture("remote_write.pb.snappy"),
+			},
+			want: want{
+				metrics: models.MetricsList{
+					{Delta: &requests, MType: models.CounterType, ID: "http_requests_total", Labels: models.Labels{"job": "api", "method": "GET"}, Timestamp: &latestTime},
+					{Value: &memory, MType: models.GaugeType, ID: "process_resident_memory_bytes", Labels: models.Labels{"job": "api"}, Timestamp: &sampleTime},
+					{Value: &up, MType: models.GaugeType, ID: "up", Labels: models.Labels{"instance": "localhost:9090", "job": "api"}, Timestamp: &sampleTime},
+					{Value: &jobs, MType: models.GaugeType, ID: "legacy_jobs_total", Timestamp: &sampleTime},
+					{Value: &info, MType: models.GaugeType, ID: "build_info", Labels: models.Labels{"version": `go "1.22"`}, Timestamp: &sampleTime},
+				},
+				expectedBody:       `series node_cpu_seconds_total{cpu="0",mode="idle"}: counter value is not integer: 42.4` + "\n",
+				expectedStatusCode: http.StatusBadRequest,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			services := test.mockServices()
+			handler := NewHandler(services, 0, test.args.options)
+			router := handler.NewRouter(nil, 0, "", nil, false)
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/write", bytes.NewReader(test.args.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Encoding", "snappy")
+			req.Header.Set("Content-Type", "application/x-protobuf")
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, test.want.expectedStatusCode, rr.Code)
+			if len(test.want.expectedBody) > 0 {
+				assert.Equal(t, test.want.expectedBody, rr.Body.String())
+			}
+			if test.want.metrics != nil {
+				services.MetricsManager.(*mocks.Manager).AssertCalled(t, "UpdateMetrics", mock.Anything, mock.MatchedBy(func(list models.MetricsList) bool {
+					return assert.ElementsMatch(t, test.want.metrics, list)
+				}))
+			}
+		})
+	}
+}
+
+func TestHandler_writePrometheusSecuredRouter(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.0.0/24")
+	require.NoError(t, err)
+	body, err := os.ReadFile(filepath.Join("testdata", "remote_write.pb.snappy"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		subnet   *net.IPNet
+		name     string
+		want     int
+		insecure bool
+	}{
+		{
+			name:   "Unsigned request from trusted subnet without X-Real-IP",
+			subnet: subnet,
+			want:   http.StatusNoContent,
+		},
+		{
+			name: "Unsigned request without trusted subnet",
+			want: http.StatusBadRequest,
+		},
+		{
+			name:     "Unsigned request in insecure mode",
+			insecure: true,
+			want:     http.StatusNoContent,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockMetricsManager := mocks.NewManager(t)
+			mockUpdateMetricsFunc(mockMetricsManager)
+			mockMetricsManager.On("GetMetric", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+			mockMetricsManager.On("UpdateMetrics", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			options := &IngestOptions{PrometheusWrite: true, Insecure: test.insecure}
+			handler := NewHandler(&service.ServerServices{MetricsManager: mockMetricsManager}, 0, options)
+			router := handler.NewRouter(signing.KeySet{"": []byte("secret")}, 0, "", test.subnet, false)
+
+			// Prometheus neither signs requests nor passes the X-Real-IP header.
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/write", bytes.NewReader(body))
+			req.RemoteAddr = "10.0.0.15:51234"
+			req.Header.Set("Content-Encoding", "snappy")
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, test.want, rr.Code)
+		})
+	}
+}
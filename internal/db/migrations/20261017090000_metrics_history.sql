@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+ALTER TABLE metrics ADD COLUMN Timestamp TIMESTAMP WITH TIME ZONE;
+CREATE TABLE metrics_history
+(
+    Id        BIGSERIAL PRIMARY KEY,
+    Name      VARCHAR(50)              NOT NULL,
+    Type      VARCHAR(50)              NOT NULL,
+    Delta     BIGINT,
+    Value     DOUBLE PRECISION,
+    Timestamp TIMESTAMP WITH TIME ZONE NOT NULL
+);
+CREATE INDEX metrics_history_name_type_timestamp_idx ON metrics_history (Name, Type, Timestamp);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE metrics_history;
+ALTER TABLE metrics DROP COLUMN Timestamp;
+-- +goose StatementEnd
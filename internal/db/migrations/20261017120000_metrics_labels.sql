@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+ALTER TABLE metrics ADD COLUMN Labels JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE metrics DROP CONSTRAINT metrics_name_type_key;
+ALTER TABLE metrics ADD CONSTRAINT metrics_name_type_labels_key UNIQUE (Name, Type, Labels);
+ALTER TABLE metrics_history ADD COLUMN Labels JSONB NOT NULL DEFAULT '{}';
+DROP INDEX metrics_history_name_type_timestamp_idx;
+CREATE INDEX metrics_history_name_type_labels_timestamp_idx ON metrics_history (Name, Type, Labels, Timestamp);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DELETE FROM metrics WHERE Labels <> '{}';
+DELETE FROM metrics_history WHERE Labels <> '{}';
+DROP INDEX metrics_history_name_type_labels_timestamp_idx;
+CREATE INDEX metrics_history_name_type_timestamp_idx ON metrics_history (Name, Type, Timestamp);
+ALTER TABLE metrics_history DROP COLUMN Labels;
+ALTER TABLE metrics DROP CONSTRAINT metrics_name_type_labels_key;
+ALTER TABLE metrics ADD CONSTRAINT metrics_name_type_key UNIQUE (Name, Type);
+ALTER TABLE metrics DROP COLUMN Labels;
+-- +goose StatementEnd
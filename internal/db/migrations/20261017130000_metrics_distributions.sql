@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+ALTER TABLE metrics ADD COLUMN Histogram JSONB;
+ALTER TABLE metrics ADD COLUMN Summary JSONB;
+ALTER TABLE metrics_history ADD COLUMN Histogram JSONB;
+ALTER TABLE metrics_history ADD COLUMN Summary JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DELETE FROM metrics WHERE Type IN ('histogram', 'summary');
+DELETE FROM metrics_history WHERE Type IN ('histogram', 'summary');
+ALTER TABLE metrics_history DROP COLUMN Summary;
+ALTER TABLE metrics_history DROP COLUMN Histogram;
+ALTER TABLE metrics DROP COLUMN Summary;
+ALTER TABLE metrics DROP COLUMN Histogram;
+-- +goose StatementEnd
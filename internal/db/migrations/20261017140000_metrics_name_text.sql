@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+ALTER TABLE metrics ALTER COLUMN Name TYPE TEXT;
+ALTER TABLE metrics_history ALTER COLUMN Name TYPE TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DELETE FROM metrics WHERE LENGTH(Name) > 50;
+DELETE FROM metrics_history WHERE LENGTH(Name) > 50;
+ALTER TABLE metrics_history ALTER COLUMN Name TYPE VARCHAR(50);
+ALTER TABLE metrics ALTER COLUMN Name TYPE VARCHAR(50);
+-- +goose StatementEnd
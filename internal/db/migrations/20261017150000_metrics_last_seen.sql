@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+ALTER TABLE metrics ADD COLUMN Last_Seen TIMESTAMP WITH TIME ZONE;
+UPDATE metrics SET Last_Seen = Timestamp;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE metrics DROP COLUMN Last_Seen;
+-- +goose StatementEnd
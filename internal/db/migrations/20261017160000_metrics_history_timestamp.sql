@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE INDEX metrics_history_timestamp_idx ON metrics_history (Timestamp);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP INDEX metrics_history_timestamp_idx;
+-- +goose StatementEnd
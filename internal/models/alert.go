@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// AlertState is the state of an alerting rule.
+type AlertState = string
+
+const (
+	AlertStateInactive = AlertState("inactive") // Condition of the rule is not met
+	AlertStatePending  = AlertState("pending")  // Condition is met, but not long enough
+	AlertStateFiring   = AlertState("firing")   // Condition is met for the required duration
+	AlertStateResolved = AlertState("resolved") // Condition is not met anymore after firing
+)
+
+// Alert is the current state of an alerting rule.
+type Alert struct {
+	ActiveAt   *time.Time `json:"active_at,omitempty"`
+	FiredAt    *time.Time `json:"fired_at,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	Value      *float64   `json:"value,omitempty"`
+	// Labels are labels of the series the value belongs to.
+	Labels Labels     `json:"labels,omitempty"`
+	Rule   string     `json:"rule"`
+	State  AlertState `json:"state"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Bucket contains aggregated values of the metric samples recorded in the time range [Start, Start+step).
+type Bucket struct {
+	Start time.Time `json:"start"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Avg   float64   `json:"avg"`
+	Sum   float64   `json:"sum"`
+	Last  float64   `json:"last"`
+	// Rate is the per-second rate of change of the metric value by the end of the bucket.
+	Rate  float64 `json:"rate"`
+	Count int     `json:"count"`
+}
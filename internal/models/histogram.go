@@ -0,0 +1,145 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidHistogram is the error returned when the histogram is missing or its buckets are inconsistent.
+var ErrInvalidHistogram = errors.New("invalid histogram")
+
+// Histogram is the distribution of observations by buckets. Bounds are upper bounds of the buckets in ascending order,
+// Counts contains the number of observations of each bucket and of the last overflow bucket (+Inf), so it is one item
+// longer than Bounds. Counts are not cumulative.
+type Histogram struct {
+	Bounds []float64 `json:"bounds"`
+	Counts []uint64  `json:"counts"`
+	Sum    float64   `json:"sum"`
+}
+
+// NewHistogram returns empty histogram with the specified upper bounds of buckets.
+func NewHistogram(bounds []float64) *Histogram {
+	return &Histogram{
+		Bounds: append([]float64(nil), bounds...),
+		Counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+// Observe adds the value to the first bucket whose upper bound is greater than or equal to the value.
+func (h *Histogram) Observe(value float64) {
+	h.Counts[sort.SearchFloat64s(h.Bounds, value)]++
+	h.Sum += value
+}
+
+// Count returns the total number of observations.
+func (h *Histogram) Count() uint64 {
+	var count uint64
+	for _, c := range h.Counts {
+		count += c
+	}
+
+	return count
+}
+
+// Validate checks that bounds are finite and ascending and there is the count for each bucket.
+func (h *Histogram) Validate() error {
+	if h == nil {
+		return fmt.Errorf("%w: histogram is missing", ErrInvalidHistogram)
+	}
+	if len(h.Counts) != len(h.Bounds)+1 {
+		return fmt.Errorf("%w: %d counts for %d bounds", ErrInvalidHistogram, len(h.Counts), len(h.Bounds))
+	}
+	for i, bound := range h.Bounds {
+		if math.IsNaN(bound) || math.IsInf(bound, 0) {
+			return fmt.Errorf("%w: bound %v is not finite", ErrInvalidHistogram, bound)
+		}
+		if i > 0 && bound <= h.Bounds[i-1] {
+			return fmt.Errorf("%w: bounds are not ascending", ErrInvalidHistogram)
+		}
+	}
+	if math.IsNaN(h.Sum) || math.IsInf(h.Sum, 0) {
+		return fmt.Errorf("%w: sum is not finite", ErrInvalidHistogram)
+	}
+
+	return nil
+}
+
+// Merge returns new histogram containing observations of both histograms. If bounds of the histograms differ, the
+// buckets were reconfigured, so the other histogram replaces this one.
+func (h *Histogram) Merge(other *Histogram) *Histogram {
+	result := NewHistogram(other.Bounds)
+	copy(result.Counts, other.Counts)
+	result.Sum = other.Sum
+	if h == nil || !equalBounds(h.Bounds, other.Bounds) {
+		return result
+	}
+
+	for i, count := range h.Counts {
+		result.Counts[i] += count
+	}
+	result.Sum += h.Sum
+
+	return result
+}
+
+// String returns the histogram in the form count=3 sum=0.5 buckets=0.1:1,1:3,+Inf:3 where counts of buckets are
+// cumulative, i.e. the number of observations less than or equal to the bound.
+func (h *Histogram) String() string {
+	buckets := make([]string, 0, len(h.Counts))
+	var cumulative uint64
+	for i, count := range h.Counts {
+		cumulative += count
+		bound := "+Inf"
+		if i < len(h.Bounds) {
+			bound = strconv.FormatFloat(h.Bounds[i], 'f', -1, 64)
+		}
+		buckets = append(buckets, bound+":"+strconv.FormatUint(cumulative, 10))
+	}
+
+	return fmt.Sprintf("count=%d sum=%s buckets=%s", h.Count(), strconv.FormatFloat(h.Sum, 'f', -1, 64), strings.Join(buckets, ","))
+}
+
+// Value stores the histogram in the database as JSON object, missing histogram is NULL.
+func (h *Histogram) Value() (driver.Value, error) {
+	if h == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(*h)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+// Scan loads the histogram stored in the database as JSON object.
+func (h *Histogram) Scan(src any) error {
+	switch value := src.(type) {
+	case []byte:
+		return json.Unmarshal(value, h)
+	case string:
+		return json.Unmarshal([]byte(value), h)
+	default:
+		return fmt.Errorf("unsupported type of histogram: %T", src)
+	}
+}
+
+func equalBounds(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogram_Observe(t *testing.T) {
+	bounds := []float64{0.1, 1}
+	h := NewHistogram(bounds)
+	bounds[0] = 0.5
+
+	for _, value := range []float64{0.05, 0.1, 0.5, 2} {
+		h.Observe(value)
+	}
+
+	assert.Equal(t, &Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{2, 1, 1}, Sum: 2.65}, h)
+	assert.Equal(t, uint64(4), h.Count())
+}
+
+func TestHistogram_Validate(t *testing.T) {
+	tests := []struct {
+		histogram *Histogram
+		want      error
+		name      string
+	}{
+		{
+			name:      "Missing histogram",
+			histogram: nil,
+			want:      fmt.Errorf("%w: histogram is missing", ErrInvalidHistogram),
+		},
+		{
+			name:      "Counts don't match bounds",
+			histogram: &Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{1, 2}},
+			want:      fmt.Errorf("%w: 2 counts for 2 bounds", ErrInvalidHistogram),
+		},
+		{
+			name:      "Infinite bound",
+			histogram: &Histogram{Bounds: []float64{0.1, math.Inf(1)}, Counts: []uint64{1, 2, 0}},
+			want:      fmt.Errorf("%w: bound +Inf is not finite", ErrInvalidHistogram),
+		},
+		{
+			name:      "Bounds are not ascending",
+			histogram: &Histogram{Bounds: []float64{1, 1}, Counts: []uint64{1, 2, 0}},
+			want:      fmt.Errorf("%w: bounds are not ascending", ErrInvalidHistogram),
+		},
+		{
+			name:      "Sum is not finite",
+			histogram: &Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{1, 2, 0}, Sum: math.NaN()},
+			want:      fmt.Errorf("%w: sum is not finite", ErrInvalidHistogram),
+		},
+		{
+			name:      "Only overflow bucket",
+			histogram: &Histogram{Counts: []uint64{3}, Sum: 1.5},
+			want:      nil,
+		},
+		{
+			name:      "Valid histogram",
+			histogram: &Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{1, 2, 0}, Sum: 1.25},
+			want:      nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.histogram.Validate())
+		})
+	}
+}
+
+func TestHistogram_Merge(t *testing.T) {
+	other := &Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{0, 2, 1}, Sum: 3}
+	tests := []struct {
+		histogram *Histogram
+		want      *Histogram
+		name      string
+	}{
+		{
+			name:      "New histogram",
+			histogram: nil,
+			want:      &Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{0, 2, 1}, Sum: 3},
+		},
+		{
+			name:      "Same bounds",
+			histogram: &Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{1, 1, 0}, Sum: 0.5},
+			want:      &Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{1, 3, 1}, Sum: 3.5},
+		},
+		{
+			name:      "Other bounds",
+			histogram: &Histogram{Bounds: []float64{0.5}, Counts: []uint64{1, 1}, Sum: 1},
+			want:      &Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{0, 2, 1}, Sum: 3},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.histogram.Merge(other)
+			assert.Equal(t, test.want, got)
+
+			got.Counts[0]++
+			assert.Equal(t, []uint64{0, 2, 1}, other.Counts)
+		})
+	}
+}
+
+func TestHistogram_Value(t *testing.T) {
+	var missing *Histogram
+	value, err := missing.Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	value, err = (&Histogram{Bounds: []float64{0.1}, Counts: []uint64{1, 2}, Sum: 4.5}).Value()
+	require.NoError(t, err)
+	assert.Equal(t, `{"bounds":[0.1],"counts":[1,2],"sum":4.5}`, value)
+}
+
+func TestHistogram_Scan(t *testing.T) {
+	type want struct {
+		histogram Histogram
+		err       bool
+	}
+	tests := []struct {
+		src  any
+		name string
+		want want
+	}{
+		{
+			name: "JSON object",
+			src:  []byte(`{"bounds":[0.1],"counts":[1,2],"sum":4.5}`),
+			want: want{histogram: Histogram{Bounds: []float64{0.1}, Counts: []uint64{1, 2}, Sum: 4.5}},
+		},
+		{
+			name: "JSON string",
+			src:  `{"bounds":[],"counts":[3],"sum":1}`,
+			want: want{histogram: Histogram{Bounds: []float64{}, Counts: []uint64{3}, Sum: 1}},
+		},
+		{
+			name: "Invalid JSON",
+			src:  `{"counts":[-1]}`,
+			want: want{err: true},
+		},
+		{
+			name: "Unsupported type",
+			src:  42,
+			want: want{err: true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var h Histogram
+			err := h.Scan(test.src)
+			if test.want.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want.histogram, h)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Labels are name-value pairs that distinguish series of the metric with the same name (hosts, CPU cores, etc.).
+// The metric is identified by its name, type and labels. Nil and empty labels are the same.
+type Labels map[string]string
+
+// Names returns names of the labels in ascending order.
+func (l Labels) Names() []string {
+	names := make([]string, 0, len(l))
+	for name := range l {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// String returns labels sorted by names in the form {name1="value1",name2="value2"}. Empty labels are an empty string.
+func (l Labels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, name := range l.Names() {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(name)
+		sb.WriteString(`="`)
+		sb.WriteString(labelValueEscaper.Replace(l[name]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+
+	return sb.String()
+}
+
+// Matches reports whether the labels contain all labels of the filter. Any labels match the empty filter.
+func (l Labels) Matches(filter Labels) bool {
+	for name, value := range filter {
+		if actual, ok := l[name]; !ok || actual != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Value stores labels in the database as JSON object.
+func (l Labels) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return "{}", nil
+	}
+
+	data, err := json.Marshal(map[string]string(l))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+// Scan loads labels stored in the database as JSON object.
+func (l *Labels) Scan(src any) error {
+	var data []byte
+	switch value := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = value
+	case string:
+		data = []byte(value)
+	default:
+		return fmt.Errorf("unsupported type of labels: %T", src)
+	}
+
+	var labels map[string]string
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return err
+	}
+	if len(labels) == 0 {
+		labels = nil
+	}
+	*l = labels
+
+	return nil
+}
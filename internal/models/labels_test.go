@@ -0,0 +1,133 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels_String(t *testing.T) {
+	tests := []struct {
+		labels Labels
+		name   string
+		want   string
+	}{
+		{
+			name:   "Nil labels",
+			labels: nil,
+			want:   "",
+		},
+		{
+			name:   "Sorted labels",
+			labels: Labels{"host": "web1", "cpu": "0"},
+			want:   `{cpu="0",host="web1"}`,
+		},
+		{
+			name:   "Escaped value",
+			labels: Labels{"path": "C:\\tmp", "version": "go \"1.22\"\n"},
+			want:   `{path="C:\\tmp",version="go \"1.22\"\n"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.labels.String())
+		})
+	}
+}
+
+func TestLabels_Matches(t *testing.T) {
+	labels := Labels{"host": "web1", "cpu": "0"}
+
+	tests := []struct {
+		filter Labels
+		name   string
+		want   bool
+	}{
+		{
+			name:   "Empty filter",
+			filter: nil,
+			want:   true,
+		},
+		{
+			name:   "Subset of labels",
+			filter: Labels{"host": "web1"},
+			want:   true,
+		},
+		{
+			name:   "Other value",
+			filter: Labels{"host": "web2"},
+			want:   false,
+		},
+		{
+			name:   "Missing label",
+			filter: Labels{"host": "web1", "env": "prod"},
+			want:   false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, labels.Matches(test.filter))
+		})
+	}
+}
+
+func TestLabels_Value(t *testing.T) {
+	value, err := Labels(nil).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "{}", value)
+
+	value, err = Labels{"host": "web1", "cpu": "0"}.Value()
+	require.NoError(t, err)
+	assert.Equal(t, `{"cpu":"0","host":"web1"}`, value)
+}
+
+func TestLabels_Scan(t *testing.T) {
+	type want struct {
+		labels Labels
+		err    bool
+	}
+	tests := []struct {
+		src  any
+		name string
+		want want
+	}{
+		{
+			name: "NULL",
+			src:  nil,
+			want: want{labels: nil},
+		},
+		{
+			name: "Empty object",
+			src:  []byte(`{}`),
+			want: want{labels: nil},
+		},
+		{
+			name: "JSON object",
+			src:  `{"cpu":"0","host":"web1"}`,
+			want: want{labels: Labels{"host": "web1", "cpu": "0"}},
+		},
+		{
+			name: "Invalid JSON",
+			src:  `{"cpu":0}`,
+			want: want{err: true},
+		},
+		{
+			name: "Unsupported type",
+			src:  42,
+			want: want{err: true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			labels := Labels{"stale": "label"}
+			err := labels.Scan(test.src)
+			if test.want.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want.labels, labels)
+		})
+	}
+}
@@ -4,7 +4,10 @@ type CounterDateType = int64
 type CounterName = MetricName
 
 const (
-	PollCount = CounterName("PollCount")
+	ExecCollectorErrors    = CounterName("ExecCollectorErrors")
+	GraphiteMalformedLines = CounterName("GraphiteMalformedLines")
+	PollCount              = CounterName("PollCount")
+	StatsDMalformedPackets = CounterName("StatsDMalformedPackets")
 )
 
 var MetricsCounterNamesList = []MetricName{
@@ -4,36 +4,42 @@ type GaugeDateType = float64
 type GaugeName = MetricName
 
 const (
-	Alloc         = GaugeName("Alloc")
-	BuckHashSys   = GaugeName("BuckHashSys")
-	FreeMemory    = GaugeName("FreeMemory")
-	Frees         = GaugeName("Frees")
-	GCCPUFraction = GaugeName("GCCPUFraction")
-	GCSys         = GaugeName("GCSys")
-	HeapAlloc     = GaugeName("HeapAlloc")
-	HeapIdle      = GaugeName("HeapIdle")
-	HeapInuse     = GaugeName("HeapInuse")
-	HeapObjects   = GaugeName("HeapObjects")
-	HeapReleased  = GaugeName("HeapReleased")
-	HeapSys       = GaugeName("HeapSys")
-	LastGC        = GaugeName("LastGC")
-	Lookups       = GaugeName("Lookups")
-	MCacheInuse   = GaugeName("MCacheInuse")
-	MCacheSys     = GaugeName("MCacheSys")
-	MSpanInuse    = GaugeName("MSpanInuse")
-	MSpanSys      = GaugeName("MSpanSys")
-	Mallocs       = GaugeName("Mallocs")
-	NextGC        = GaugeName("NextGC")
-	NumForcedGC   = GaugeName("NumForcedGC")
-	NumGC         = GaugeName("NumGC")
-	OtherSys      = GaugeName("OtherSys")
-	PauseTotalNs  = GaugeName("PauseTotalNs")
-	RandomValue   = GaugeName("RandomValue")
-	StackInuse    = GaugeName("StackInuse")
-	StackSys      = GaugeName("StackSys")
-	Sys           = GaugeName("Sys")
-	TotalAlloc    = GaugeName("TotalAlloc")
-	TotalMemory   = GaugeName("TotalMemory")
+	Alloc          = GaugeName("Alloc")
+	BuckHashSys    = GaugeName("BuckHashSys")
+	FreeMemory     = GaugeName("FreeMemory")
+	Frees          = GaugeName("Frees")
+	GCCPUFraction  = GaugeName("GCCPUFraction")
+	GCSys          = GaugeName("GCSys")
+	HeapAlloc      = GaugeName("HeapAlloc")
+	HeapIdle       = GaugeName("HeapIdle")
+	HeapInuse      = GaugeName("HeapInuse")
+	HeapObjects    = GaugeName("HeapObjects")
+	HeapReleased   = GaugeName("HeapReleased")
+	HeapSys        = GaugeName("HeapSys")
+	LastGC         = GaugeName("LastGC")
+	Load1          = GaugeName("Load1")
+	Load15         = GaugeName("Load15")
+	Load5          = GaugeName("Load5")
+	Lookups        = GaugeName("Lookups")
+	MCacheInuse    = GaugeName("MCacheInuse")
+	MCacheSys      = GaugeName("MCacheSys")
+	MSpanInuse     = GaugeName("MSpanInuse")
+	MSpanSys       = GaugeName("MSpanSys")
+	Mallocs        = GaugeName("Mallocs")
+	NextGC         = GaugeName("NextGC")
+	NumForcedGC    = GaugeName("NumForcedGC")
+	NumGC          = GaugeName("NumGC")
+	OtherSys       = GaugeName("OtherSys")
+	PauseTotalNs   = GaugeName("PauseTotalNs")
+	ProcessBlocked = GaugeName("ProcessBlocked")
+	ProcessCount   = GaugeName("ProcessCount")
+	ProcessRunning = GaugeName("ProcessRunning")
+	RandomValue    = GaugeName("RandomValue")
+	StackInuse     = GaugeName("StackInuse")
+	StackSys       = GaugeName("StackSys")
+	Sys            = GaugeName("Sys")
+	TotalAlloc     = GaugeName("TotalAlloc")
+	TotalMemory    = GaugeName("TotalMemory")
 )
 
 var MetricsGaugeNamesList = []MetricName{
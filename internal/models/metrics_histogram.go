@@ -0,0 +1,7 @@
+package models
+
+type HistogramName = MetricName
+
+const (
+	GCPause = HistogramName("GCPause")
+)
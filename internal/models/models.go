@@ -1,23 +1,38 @@
 package models
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
+	"time"
 )
 
 type MetricType = string
 type MetricName = string
 
 const (
-	GaugeType   = MetricType("gauge")
-	CounterType = MetricType("counter")
+	GaugeType     = MetricType("gauge")
+	CounterType   = MetricType("counter")
+	HistogramType = MetricType("histogram")
+	SummaryType   = MetricType("summary")
 )
 
+// ErrNoStringValue is the error returned when the value of the metric type can't be parsed from a string.
+var ErrNoStringValue = errors.New("value of the metric type can't be parsed from string")
+
 type Metric struct {
-	Value *float64   `json:"value,omitempty" db:"value"`
-	Delta *int64     `json:"delta,omitempty" db:"delta"`
-	MType MetricType `json:"type" db:"type"`
-	ID    MetricName `json:"id" db:"name"`
+	Value *float64 `json:"value,omitempty" db:"value"`
+	Delta *int64   `json:"delta,omitempty" db:"delta"`
+	// Timestamp is the time of the sample, LastSeen is the server time of the last update of the metric.
+	Timestamp *time.Time `json:"timestamp,omitempty" db:"timestamp"`
+	LastSeen  *time.Time `json:"last_seen,omitempty" db:"last_seen"`
+	// Histogram is used by histograms, Summary is used by summaries.
+	Histogram *Histogram `json:"histogram,omitempty" db:"histogram"`
+	Summary   *Summary   `json:"summary,omitempty" db:"summary"`
+	Labels    Labels     `json:"labels,omitempty" db:"labels"`
+	MType     MetricType `json:"type" db:"type"`
+	ID        MetricName `json:"id" db:"name"`
+	AgentID   string     `json:"agent_id,omitempty" db:"agent_id"`
 }
 
 type MetricsList []*Metric
@@ -28,13 +43,45 @@ func (m *Metric) ValueToString() string {
 		return strconv.FormatFloat(*m.Value, 'f', -1, 64)
 	case CounterType:
 		return fmt.Sprintf("%d", *m.Delta)
+	case HistogramType:
+		return m.Histogram.String()
+	case SummaryType:
+		return m.Summary.String()
 	default:
 		return ""
 	}
 }
 
+// FloatValue returns the value of the metric as float64 regardless of its type.
+func (m *Metric) FloatValue() float64 {
+	switch {
+	case m.MType == GaugeType && m.Value != nil:
+		return *m.Value
+	case m.MType == CounterType && m.Delta != nil:
+		return float64(*m.Delta)
+	default:
+		return 0
+	}
+}
+
+// IsStale reports whether the metric was not updated longer than the window. Zero window disables the check.
+func (m *Metric) IsStale(now time.Time, window time.Duration) bool {
+	lastSeen := m.LastSeenTime()
+	return window > 0 && lastSeen != nil && now.Sub(*lastSeen) > window
+}
+
+// LastSeenTime returns the server time of the last update of the metric. The sample time is returned for metrics
+// stored before the time of the update was recorded.
+func (m *Metric) LastSeenTime() *time.Time {
+	if m.LastSeen != nil {
+		return m.LastSeen
+	}
+
+	return m.Timestamp
+}
+
 func (m *Metric) String() string {
-	return fmt.Sprintf("%s: %s", m.ID, m.ValueToString())
+	return fmt.Sprintf("%s%s: %s", m.ID, m.Labels, m.ValueToString())
 }
 
 func (m *Metric) ValueFromString(str string) error {
@@ -52,6 +99,8 @@ func (m *Metric) ValueFromString(str string) error {
 			return err
 		}
 		m.Delta = &value
+	case HistogramType, SummaryType:
+		return fmt.Errorf("%w: %s", ErrNoStringValue, m.MType)
 	}
 
 	return nil
@@ -1,9 +1,11 @@
 package models
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"strconv"
 	"testing"
+	"time"
 )
 
 func TestMetric_ValueFromString(t *testing.T) {
@@ -104,6 +106,23 @@ func TestMetric_ValueFromString(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Histogram value can't be parsed",
+			fields: fields{
+				MType: HistogramType,
+				ID:    "latency",
+			},
+			args: args{
+				str: "0.5",
+			},
+			want: want{
+				err: fmt.Errorf("%w: histogram", ErrNoStringValue),
+				metric: Metric{
+					MType: HistogramType,
+					ID:    "latency",
+				},
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -122,10 +141,12 @@ func TestMetric_ValueToString(t *testing.T) {
 	d := int64(100)
 	v := float64(100.1)
 	type fields struct {
-		Value *float64
-		Delta *int64
-		MType MetricType
-		ID    MetricName
+		Value     *float64
+		Delta     *int64
+		Histogram *Histogram
+		Summary   *Summary
+		MType     MetricType
+		ID        MetricName
 	}
 	type want struct {
 		result string
@@ -162,14 +183,36 @@ func TestMetric_ValueToString(t *testing.T) {
 				result: "100.1",
 			},
 		},
+		{
+			name: "successfully case (histogram)",
+			fields: fields{
+				Histogram: &Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{1, 2, 0}, Sum: 1.25},
+				MType:     HistogramType,
+			},
+			want: want{
+				result: "count=3 sum=1.25 buckets=0.1:1,1:3,+Inf:3",
+			},
+		},
+		{
+			name: "successfully case (summary)",
+			fields: fields{
+				Summary: &Summary{Quantiles: []Quantile{{Quantile: 0.5, Value: 0.2}}, Count: 3, Sum: 1.25},
+				MType:   SummaryType,
+			},
+			want: want{
+				result: "count=3 sum=1.25 quantiles=0.5:0.2",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := &Metric{
-				Value: tt.fields.Value,
-				Delta: tt.fields.Delta,
-				MType: tt.fields.MType,
-				ID:    tt.fields.ID,
+				Value:     tt.fields.Value,
+				Delta:     tt.fields.Delta,
+				Histogram: tt.fields.Histogram,
+				Summary:   tt.fields.Summary,
+				MType:     tt.fields.MType,
+				ID:        tt.fields.ID,
 			}
 			assert.Equalf(t, tt.want.result, m.ValueToString(), "ValueToString()")
 		})
@@ -180,10 +223,11 @@ func TestMetric_String(t *testing.T) {
 	d := int64(100)
 	v := float64(100.1)
 	type fields struct {
-		Value *float64
-		Delta *int64
-		MType MetricType
-		ID    MetricName
+		Value  *float64
+		Delta  *int64
+		Labels Labels
+		MType  MetricType
+		ID     MetricName
 	}
 	type want struct {
 		result string
@@ -211,16 +255,80 @@ func TestMetric_String(t *testing.T) {
 			},
 			want: want{result: "metric 1: 100.1"},
 		},
+		{
+			name: "metric with labels",
+			fields: fields{
+				Value:  &v,
+				Labels: Labels{"host": "web1", "cpu": "0"},
+				MType:  GaugeType,
+				ID:     "CPUutilization",
+			},
+			want: want{result: `CPUutilization{cpu="0",host="web1"}: 100.1`},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			m := &Metric{
-				Value: test.fields.Value,
-				Delta: test.fields.Delta,
-				MType: test.fields.MType,
-				ID:    test.fields.ID,
+				Value:  test.fields.Value,
+				Delta:  test.fields.Delta,
+				Labels: test.fields.Labels,
+				MType:  test.fields.MType,
+				ID:     test.fields.ID,
 			}
 			assert.Equalf(t, test.want.result, m.String(), "String()")
 		})
 	}
 }
+
+func TestMetric_IsStale(t *testing.T) {
+	now := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+	updatedAt := now.Add(-time.Minute)
+	sampledAt := now.Add(-time.Hour)
+	type args struct {
+		timestamp *time.Time
+		lastSeen  *time.Time
+		window    time.Duration
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "Check disabled",
+			args: args{timestamp: &updatedAt, window: 0},
+			want: false,
+		},
+		{
+			name: "Unknown update time",
+			args: args{timestamp: nil, window: time.Second},
+			want: false,
+		},
+		{
+			name: "Fresh metric",
+			args: args{timestamp: &updatedAt, window: time.Minute},
+			want: false,
+		},
+		{
+			name: "Stale metric",
+			args: args{timestamp: &updatedAt, window: 30 * time.Second},
+			want: true,
+		},
+		{
+			name: "Old sample received recently",
+			args: args{timestamp: &sampledAt, lastSeen: &updatedAt, window: time.Minute},
+			want: false,
+		},
+		{
+			name: "Stale metric with last seen time",
+			args: args{timestamp: &sampledAt, lastSeen: &updatedAt, window: 30 * time.Second},
+			want: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := &Metric{Timestamp: test.args.timestamp, LastSeen: test.args.lastSeen, MType: GaugeType, ID: "metric 1"}
+			assert.Equal(t, test.want, m.IsStale(now, test.args.window))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSummary is the error returned when the summary is missing or its quantiles are invalid.
+var ErrInvalidSummary = errors.New("invalid summary")
+
+// Quantile is the value below which the specified fraction of observations falls.
+type Quantile struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
+}
+
+// Summary contains quantiles of observations calculated by the client together with the number and the sum of
+// observations. Quantiles can't be merged, so the last reported summary replaces the stored one.
+type Summary struct {
+	Quantiles []Quantile `json:"quantiles"`
+	Count     uint64     `json:"count"`
+	Sum       float64    `json:"sum"`
+}
+
+// Validate checks that quantiles are in the range [0, 1] and all values are finite.
+func (s *Summary) Validate() error {
+	if s == nil {
+		return fmt.Errorf("%w: summary is missing", ErrInvalidSummary)
+	}
+	for _, q := range s.Quantiles {
+		if math.IsNaN(q.Quantile) || q.Quantile < 0 || q.Quantile > 1 {
+			return fmt.Errorf("%w: quantile %v is out of range [0, 1]", ErrInvalidSummary, q.Quantile)
+		}
+		if math.IsNaN(q.Value) || math.IsInf(q.Value, 0) {
+			return fmt.Errorf("%w: value of quantile %v is not finite", ErrInvalidSummary, q.Quantile)
+		}
+	}
+	if math.IsNaN(s.Sum) || math.IsInf(s.Sum, 0) {
+		return fmt.Errorf("%w: sum is not finite", ErrInvalidSummary)
+	}
+
+	return nil
+}
+
+// String returns the summary in the form count=3 sum=0.5 quantiles=0.5:0.1,0.99:0.3.
+func (s *Summary) String() string {
+	quantiles := make([]string, 0, len(s.Quantiles))
+	for _, q := range s.Quantiles {
+		quantiles = append(quantiles, strconv.FormatFloat(q.Quantile, 'f', -1, 64)+":"+strconv.FormatFloat(q.Value, 'f', -1, 64))
+	}
+
+	return fmt.Sprintf("count=%d sum=%s quantiles=%s", s.Count, strconv.FormatFloat(s.Sum, 'f', -1, 64), strings.Join(quantiles, ","))
+}
+
+// Value stores the summary in the database as JSON object, missing summary is NULL.
+func (s *Summary) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(*s)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+// Scan loads the summary stored in the database as JSON object.
+func (s *Summary) Scan(src any) error {
+	switch value := src.(type) {
+	case []byte:
+		return json.Unmarshal(value, s)
+	case string:
+		return json.Unmarshal([]byte(value), s)
+	default:
+		return fmt.Errorf("unsupported type of summary: %T", src)
+	}
+}
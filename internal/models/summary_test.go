@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary_Validate(t *testing.T) {
+	tests := []struct {
+		summary *Summary
+		want    error
+		name    string
+	}{
+		{
+			name:    "Missing summary",
+			summary: nil,
+			want:    fmt.Errorf("%w: summary is missing", ErrInvalidSummary),
+		},
+		{
+			name:    "Quantile out of range",
+			summary: &Summary{Quantiles: []Quantile{{Quantile: 1.5, Value: 0.3}}},
+			want:    fmt.Errorf("%w: quantile 1.5 is out of range [0, 1]", ErrInvalidSummary),
+		},
+		{
+			name:    "Value is not finite",
+			summary: &Summary{Quantiles: []Quantile{{Quantile: 0.99, Value: math.Inf(1)}}},
+			want:    fmt.Errorf("%w: value of quantile 0.99 is not finite", ErrInvalidSummary),
+		},
+		{
+			name:    "Sum is not finite",
+			summary: &Summary{Sum: math.NaN()},
+			want:    fmt.Errorf("%w: sum is not finite", ErrInvalidSummary),
+		},
+		{
+			name:    "Valid summary",
+			summary: &Summary{Quantiles: []Quantile{{Quantile: 0, Value: 0.1}, {Quantile: 1, Value: 0.3}}, Count: 2, Sum: 0.4},
+			want:    nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.summary.Validate())
+		})
+	}
+}
+
+func TestSummary_Value(t *testing.T) {
+	var missing *Summary
+	value, err := missing.Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	value, err = (&Summary{Quantiles: []Quantile{{Quantile: 0.5, Value: 0.2}}, Count: 3, Sum: 1.25}).Value()
+	require.NoError(t, err)
+	assert.Equal(t, `{"quantiles":[{"quantile":0.5,"value":0.2}],"count":3,"sum":1.25}`, value)
+}
+
+func TestSummary_Scan(t *testing.T) {
+	type want struct {
+		summary Summary
+		err     bool
+	}
+	tests := []struct {
+		src  any
+		name string
+		want want
+	}{
+		{
+			name: "JSON object",
+			src:  []byte(`{"quantiles":[{"quantile":0.5,"value":0.2}],"count":3,"sum":1.25}`),
+			want: want{summary: Summary{Quantiles: []Quantile{{Quantile: 0.5, Value: 0.2}}, Count: 3, Sum: 1.25}},
+		},
+		{
+			name: "Invalid JSON",
+			src:  `{"count":"3"}`,
+			want: want{err: true},
+		},
+		{
+			name: "Unsupported type",
+			src:  42,
+			want: want{err: true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var s Summary
+			err := s.Scan(test.src)
+			if test.want.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want.summary, s)
+		})
+	}
+}
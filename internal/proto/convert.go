@@ -0,0 +1,97 @@
+package proto
+
+import (
+	"time"
+
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+// FromModels converts metrics to messages.
+func FromModels(metrics models.MetricsList) []*Metric {
+	result := make([]*Metric, 0, len(metrics))
+	for _, m := range metrics {
+		metric := &Metric{
+			Id:     m.ID,
+			Type:   m.MType,
+			Labels: m.Labels,
+		}
+		if m.Delta != nil {
+			metric.Delta = *m.Delta
+		}
+		if m.Value != nil {
+			metric.Value = *m.Value
+		}
+		if m.Timestamp != nil {
+			metric.Timestamp = m.Timestamp.UnixMilli()
+		}
+		if m.Histogram != nil {
+			metric.Histogram = &Histogram{
+				Bounds: m.Histogram.Bounds,
+				Counts: m.Histogram.Counts,
+				Sum:    m.Histogram.Sum,
+			}
+		}
+		if m.Summary != nil {
+			metric.Summary = &Summary{
+				Quantiles: make([]*Quantile, 0, len(m.Summary.Quantiles)),
+				Count:     m.Summary.Count,
+				Sum:       m.Summary.Sum,
+			}
+			for _, q := range m.Summary.Quantiles {
+				metric.Summary.Quantiles = append(metric.Summary.Quantiles, &Quantile{Quantile: q.Quantile, Value: q.Value})
+			}
+		}
+		result = append(result, metric)
+	}
+
+	return result
+}
+
+// ToModels converts messages to metrics. Delta is set for counters, value is set for gauges, histogram and summary
+// are set for metrics of the same types only.
+func ToModels(metrics []*Metric) models.MetricsList {
+	result := make(models.MetricsList, 0, len(metrics))
+	for _, m := range metrics {
+		metric := &models.Metric{
+			ID:    m.GetId(),
+			MType: m.GetType(),
+		}
+		if len(m.GetLabels()) > 0 {
+			metric.Labels = m.GetLabels()
+		}
+		if m.GetTimestamp() > 0 {
+			timestamp := time.UnixMilli(m.GetTimestamp())
+			metric.Timestamp = &timestamp
+		}
+		switch metric.MType {
+		case models.CounterType:
+			delta := m.GetDelta()
+			metric.Delta = &delta
+		case models.GaugeType:
+			value := m.GetValue()
+			metric.Value = &value
+		case models.HistogramType:
+			if h := m.GetHistogram(); h != nil {
+				metric.Histogram = &models.Histogram{
+					Bounds: h.GetBounds(),
+					Counts: h.GetCounts(),
+					Sum:    h.GetSum(),
+				}
+			}
+		case models.SummaryType:
+			if s := m.GetSummary(); s != nil {
+				metric.Summary = &models.Summary{
+					Quantiles: make([]models.Quantile, 0, len(s.GetQuantiles())),
+					Count:     s.GetCount(),
+					Sum:       s.GetSum(),
+				}
+				for _, q := range s.GetQuantiles() {
+					metric.Summary.Quantiles = append(metric.Summary.Quantiles, models.Quantile{Quantile: q.GetQuantile(), Value: q.GetValue()})
+				}
+			}
+		}
+		result = append(result, metric)
+	}
+
+	return result
+}
@@ -0,0 +1,52 @@
+package proto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+func TestConvert(t *testing.T) {
+	delta := int64(5)
+	value := 0.5
+	timestamp := time.UnixMilli(1729150000123)
+	metrics := models.MetricsList{
+		{ID: "PollCount", MType: models.CounterType, Delta: &delta, Timestamp: &timestamp},
+		{ID: "Alloc", MType: models.GaugeType, Value: &value},
+		{ID: "CPUutilization", MType: models.GaugeType, Value: &value, Labels: models.Labels{"cpu": "3"}},
+		{
+			ID:        "GCPause",
+			MType:     models.HistogramType,
+			Histogram: &models.Histogram{Bounds: []float64{0.001}, Counts: []uint64{2, 1}, Sum: 0.0075},
+		},
+		{
+			ID:      "RequestDuration",
+			MType:   models.SummaryType,
+			Summary: &models.Summary{Quantiles: []models.Quantile{{Quantile: 0.5, Value: 0.2}}, Count: 3, Sum: 0.7},
+		},
+		{ID: "Unknown", MType: "exponential"},
+	}
+
+	messages := FromModels(metrics)
+	assert.Equal(t, []*Metric{
+		{Id: "PollCount", Type: models.CounterType, Delta: 5, Timestamp: 1729150000123},
+		{Id: "Alloc", Type: models.GaugeType, Value: 0.5},
+		{Id: "CPUutilization", Type: models.GaugeType, Value: 0.5, Labels: map[string]string{"cpu": "3"}},
+		{
+			Id:        "GCPause",
+			Type:      models.HistogramType,
+			Histogram: &Histogram{Bounds: []float64{0.001}, Counts: []uint64{2, 1}, Sum: 0.0075},
+		},
+		{
+			Id:      "RequestDuration",
+			Type:    models.SummaryType,
+			Summary: &Summary{Quantiles: []*Quantile{{Quantile: 0.5, Value: 0.2}}, Count: 3, Sum: 0.7},
+		},
+		{Id: "Unknown", Type: "exponential"},
+	}, messages)
+
+	assert.Equal(t, metrics, ToModels(messages))
+}
@@ -0,0 +1,2 @@
+// Package proto contains gRPC API of metrics delivery generated from metrics.proto (see proto target of Makefile).
+package proto
@@ -0,0 +1,3 @@
+// Package prompb contains messages of Prometheus remote write protocol generated from remote.proto (see proto target
+// of Makefile).
+package prompb
@@ -0,0 +1,15 @@
+package proto
+
+import (
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// SignedData returns data of the call covered by its sum: the request message of unary calls and the method name
+// of streams. Messages of streams are not signed one by one, so the server accepts signed streams over TLS only.
+func SignedData(fullMethod string, request any) ([]byte, error) {
+	if message, ok := request.(protobuf.Message); ok {
+		return protobuf.MarshalOptions{Deterministic: true}.Marshal(message)
+	}
+
+	return []byte(fullMethod), nil
+}
@@ -0,0 +1,23 @@
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+func TestSignedData(t *testing.T) {
+	data, err := SignedData(Metrics_UpdateStream_FullMethodName, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("/grdn.Metrics/UpdateStream"), data)
+
+	request := &UpdateRequest{Metrics: []*Metric{{Id: "PollCount", Type: models.CounterType, Delta: 5}}}
+	first, err := SignedData(Metrics_Update_FullMethodName, request)
+	assert.NoError(t, err)
+	second, err := SignedData(Metrics_Update_FullMethodName, request)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, first)
+	assert.Equal(t, first, second)
+}
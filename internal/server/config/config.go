@@ -3,43 +3,146 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 	"time"
 )
 
 const (
-	defaultServerAddr      = "localhost:8080"
-	defaultVerboseMode     = false
-	defaultStoreInternal   = 300 * time.Second
-	defaultFileStoragePath = "/tmp/metrics-db.json"
-	defaultRestoreData     = true
-	defaultDatabaseDSN     = ""
-	defaultKey             = ""
-	defaultPrivateKeyFile  = ""
-
-	envConfigFileName      = "CONFIG"
-	envRunAddrName         = "ADDRESS"
-	envStoreIntervalName   = "STORE_INTERVAL"
-	envFileStoragePathName = "FILE_STORAGE_PATH"
-	envRestoreDataName     = "RESTORE"
-	envDatabaseDSNName     = "DATABASE_DSN"
-	envKeyName             = "KEY"
-	envCryptoKeyName       = "CRYPTO_KEY"
+	defaultServerAddr       = "localhost:8080"
+	defaultVerboseMode      = false
+	defaultStoreInternal    = 300 * time.Second
+	defaultFileStoragePath  = "/tmp/metrics-db.json"
+	defaultRestoreData      = true
+	defaultDatabaseDSN      = ""
+	defaultKey              = ""
+	defaultSignKeys         = ""
+	defaultSignKeysFile     = ""
+	defaultSignMaxSkew      = 0
+	defaultAgentsFile       = ""
+	defaultPrivateKeyFile   = ""
+	defaultHistoryRetention = time.Hour
+	defaultAlertRulesFile   = ""
+	defaultAlertInterval    = 10 * time.Second
+	defaultWebhookURLs      = ""
+	defaultWebhookKey       = ""
+	defaultStaleThreshold   = 0
+	defaultReportInterval   = 10 * time.Second
+	defaultTrustedSubnet    = ""
+	defaultTrustedReads     = false
+	defaultTLSCertFile      = ""
+	defaultTLSKeyFile       = ""
+	defaultTLSClientCAFile  = ""
+	defaultGRPCAddr         = ""
+	defaultStatsDAddr       = ""
+	defaultStatsDFlush      = 10 * time.Second
+	defaultGraphiteAddr     = ""
+	defaultInflux           = false
+	defaultCounterSuffix    = "_total"
+	defaultCounterFields    = ""
+	defaultPrometheusWrite  = false
+	defaultOTLP             = false
+	defaultIngestInsecure   = false
+
+	envConfigFileName       = "CONFIG"
+	envRunAddrName          = "ADDRESS"
+	envStoreIntervalName    = "STORE_INTERVAL"
+	envFileStoragePathName  = "FILE_STORAGE_PATH"
+	envRestoreDataName      = "RESTORE"
+	envDatabaseDSNName      = "DATABASE_DSN"
+	envKeyName              = "KEY"
+	envSignKeysName         = "SIGN_KEYS"
+	envSignKeysFileName     = "SIGN_KEYS_FILE"
+	envSignMaxSkewName      = "SIGN_MAX_SKEW"
+	envAgentsFileName       = "AGENTS_FILE"
+	envCryptoKeyName        = "CRYPTO_KEY"
+	envHistoryRetentionName = "HISTORY_RETENTION"
+	envAlertRulesFileName   = "ALERT_RULES"
+	envAlertIntervalName    = "ALERT_INTERVAL"
+	envWebhookURLsName      = "WEBHOOK_URLS"
+	envWebhookKeyName       = "WEBHOOK_KEY"
+	envStaleThresholdName   = "STALE_THRESHOLD"
+	envReportIntervalName   = "REPORT_INTERVAL"
+	envTrustedSubnetName    = "TRUSTED_SUBNET"
+	envTrustedReadsName     = "TRUSTED_SUBNET_READS"
+	envTLSCertFileName      = "TLS_CERT"
+	envTLSKeyFileName       = "TLS_KEY"
+	envTLSClientCAFileName  = "TLS_CLIENT_CA"
+	envGRPCAddrName         = "GRPC_ADDRESS"
+	envStatsDAddrName       = "STATSD_ADDRESS"
+	envStatsDFlushName      = "STATSD_FLUSH_INTERVAL"
+	envGraphiteAddrName     = "GRAPHITE_ADDRESS"
+	envInfluxName           = "INFLUX"
+	envCounterSuffixName    = "INGEST_COUNTER_SUFFIX"
+	envCounterFieldsName    = "INFLUX_COUNTER_FIELDS"
+	envPrometheusWriteName  = "PROMETHEUS_WRITE"
+	envOTLPName             = "OTLP"
+	envIngestInsecureName   = "INGEST_INSECURE"
 )
 
+// ErrInvalidInterval is the error returned when the interval of the periodic task is not positive.
+var ErrInvalidInterval = errors.New("invalid interval")
+
+// ErrInsecureIngest is the error returned when endpoints of third-party protocols would accept writes from anyone.
+var ErrInsecureIngest = errors.New("insecure ingest")
+
 type Config struct {
 	FileStoragePath string `yaml:"store_file"`
 	LoggerLevel     string
 	ServerAddr      string `yaml:"address"`
 	DatabaseDSN     string `yaml:"database_dsn"`
 	Key             string
-	PrivateKeyFile  string        `yaml:"crypto_key"`
-	StoreInternal   time.Duration `yaml:"store_interval"`
-	LogLevel        slog.Level
-	RestoreData     bool `yaml:"restore"`
-	VerboseMode     bool
+	SignKeys        string `yaml:"sign_keys"`
+	SignKeysFile    string `yaml:"sign_keys_file"`
+	AgentsFile      string `yaml:"agents_file"`
+	PrivateKeyFile  string `yaml:"crypto_key"`
+	AlertRulesFile  string `yaml:"alert_rules"`
+	WebhookKey      string `yaml:"webhook_key"`
+	// TrustedSubnet is the CIDR of the agents network, requests from other addresses are rejected. Empty value
+	// disables the check.
+	TrustedSubnet string `yaml:"trusted_subnet"`
+	// TLSCertFile and TLSKeyFile are PEM files of the server certificate, the server listens HTTPS if they are set.
+	TLSCertFile string `yaml:"tls_cert"`
+	TLSKeyFile  string `yaml:"tls_key"`
+	// TLSClientCAFile is PEM file of CA of agents certificates. If it is set agents must present certificates (mutual
+	// TLS) and the common name of the certificate is the agent ID.
+	TLSClientCAFile string `yaml:"tls_client_ca"`
+	// GRPCAddr is the address of gRPC server of metrics delivery. Empty value disables gRPC server.
+	GRPCAddr string `yaml:"grpc_address"`
+	// StatsDAddr is the UDP address of StatsD listener. Empty value disables the listener.
+	StatsDAddr string `yaml:"statsd_address"`
+	// GraphiteAddr is the TCP address of Graphite listener. Empty value disables the listener.
+	GraphiteAddr string `yaml:"graphite_address"`
+	// CounterSuffix is the suffix of Graphite and InfluxDB metrics stored as counters, other metrics are gauges.
+	// InfluxDB fields listed in CounterFields are counters as well.
+	CounterSuffix    string        `yaml:"ingest_counter_suffix"`
+	CounterFields    []string      `yaml:"influx_counter_fields"`
+	WebhookURLs      []string      `yaml:"webhook_urls"`
+	StoreInternal    time.Duration `yaml:"store_interval"`
+	SignMaxSkew      time.Duration `yaml:"sign_max_skew"`
+	HistoryRetention time.Duration `yaml:"history_retention"`
+	AlertInterval    time.Duration `yaml:"alert_interval"`
+	StatsDFlush      time.Duration `yaml:"statsd_flush_interval"`
+	StaleThreshold   time.Duration `yaml:"stale_threshold"`
+	ReportInterval   time.Duration `yaml:"report_interval"`
+	LogLevel         slog.Level
+	RestoreData      bool `yaml:"restore"`
+	VerboseMode      bool
+	// Influx enables the endpoint of InfluxDB line protocol on the HTTP server.
+	Influx bool `yaml:"influx"`
+	// PrometheusWrite enables the endpoint of Prometheus remote write on the HTTP server.
+	PrometheusWrite bool `yaml:"prometheus_write"`
+	// OTLP enables the endpoint of OpenTelemetry metrics (OTLP/HTTP) on the HTTP server.
+	OTLP bool `yaml:"otlp"`
+	// IngestInsecure serves endpoints of third-party protocols without authentication, only the trusted subnet is
+	// checked if it is set.
+	IngestInsecure bool `yaml:"ingest_insecure"`
+	// TrustedSubnetReads enables the check of the trusted subnet for read-only GET requests.
+	TrustedSubnetReads bool `yaml:"trusted_subnet_reads"`
 }
 
 // InitConfig initializes the server configuration.
@@ -68,9 +171,45 @@ func InitConfig() (*Config, error) {
 	flag.BoolVar(&config.RestoreData, "r", defaultRestoreData, "save or don't save data to HDD on shutdown")
 	flag.StringVar(&config.DatabaseDSN, "d", defaultDatabaseDSN, "database connection string")
 	flag.StringVar(&config.Key, "k", defaultKey, "key to use for encryption")
+	flag.StringVar(&config.SignKeys, "sign-keys", defaultSignKeys, "comma separated list of rotated sign keys in format <id>:<secret>")
+	flag.StringVar(&config.SignKeysFile, "sign-keys-file", defaultSignKeysFile, "JSON file with rotated sign keys")
+	flag.DurationVar(&config.SignMaxSkew, "sign-max-skew", defaultSignMaxSkew, "max clock skew of signed requests, enables replay protection (0 - disabled)")
+	flag.StringVar(&config.AgentsFile, "agents-file", defaultAgentsFile, "JSON file with credentials of registered agents")
 	flag.StringVar(&config.PrivateKeyFile, "crypto-key", defaultPrivateKeyFile, "public key file path")
+	flag.DurationVar(&config.HistoryRetention, "history-retention", defaultHistoryRetention, "period of keeping metrics history (0 - forever)")
+	flag.StringVar(&config.AlertRulesFile, "alert-rules", defaultAlertRulesFile, "alerting rules file path")
+	flag.DurationVar(&config.AlertInterval, "alert-interval", defaultAlertInterval, "time interval to check alerting rules")
+	var webhookURLs string
+	flag.StringVar(&webhookURLs, "webhook-urls", defaultWebhookURLs, "comma separated list of urls to send alerts notifications")
+	flag.StringVar(&config.WebhookKey, "webhook-key", defaultWebhookKey, "key to sign alerts notifications")
+	flag.DurationVar(&config.StaleThreshold, "stale-threshold", defaultStaleThreshold, "period after which not updated metrics are considered stale (0 - never)")
+	flag.DurationVar(&config.ReportInterval, "report-interval", defaultReportInterval, "expected interval of agents reports used by silent alerting rules")
+	flag.StringVar(&config.TrustedSubnet, "t", defaultTrustedSubnet, "CIDR of the trusted subnet of agents (empty - any address)")
+	flag.BoolVar(&config.TrustedSubnetReads, "trusted-subnet-reads", defaultTrustedReads, "check the trusted subnet for GET requests too")
+	flag.StringVar(&config.TLSCertFile, "tls-cert", defaultTLSCertFile, "server certificate file path (empty - HTTP)")
+	flag.StringVar(&config.TLSKeyFile, "tls-key", defaultTLSKeyFile, "server certificate key file path")
+	flag.StringVar(&config.TLSClientCAFile, "tls-client-ca", defaultTLSClientCAFile, "CA file path of agents certificates (empty - agents certificates are not required)")
+	flag.StringVar(&config.GRPCAddr, "g", defaultGRPCAddr, "address and port to run gRPC server (empty - disabled)")
+	flag.StringVar(&config.StatsDAddr, "statsd-address", defaultStatsDAddr, "UDP address and port to listen StatsD packets (empty - disabled)")
+	flag.DurationVar(&config.StatsDFlush, "statsd-flush-interval", defaultStatsDFlush, "time interval to flush StatsD and Graphite samples to the storage")
+	flag.StringVar(&config.GraphiteAddr, "graphite-address", defaultGraphiteAddr, "TCP address and port to listen Graphite plaintext protocol (empty - disabled)")
+	flag.BoolVar(&config.Influx, "influx", defaultInflux, "accept InfluxDB line protocol on /write")
+	flag.BoolVar(&config.PrometheusWrite, "prometheus-write", defaultPrometheusWrite, "accept Prometheus remote write on /api/v1/write")
+	flag.BoolVar(&config.OTLP, "otlp", defaultOTLP, "accept OpenTelemetry metrics (OTLP/HTTP) on /v1/metrics")
+	flag.BoolVar(&config.IngestInsecure, "ingest-insecure", defaultIngestInsecure, "accept writes of third-party protocols from any address without authentication")
+	flag.StringVar(&config.CounterSuffix, "ingest-counter-suffix", defaultCounterSuffix, "suffix of Graphite and InfluxDB metrics stored as counters (empty - gauges only)")
+	var counterFields string
+	flag.StringVar(&counterFields, "influx-counter-fields", defaultCounterFields, "comma separated list of InfluxDB fields stored as counters")
 	flag.Parse()
 
+	if webhookURLs != "" {
+		config.WebhookURLs = splitList(webhookURLs)
+	}
+
+	if counterFields != "" {
+		config.CounterFields = splitList(counterFields)
+	}
+
 	if envRunAddr := os.Getenv(envRunAddrName); envRunAddr != "" {
 		config.ServerAddr = envRunAddr
 	}
@@ -103,13 +242,167 @@ func InitConfig() (*Config, error) {
 		config.Key = envKey
 	}
 
+	if envSignKeys := os.Getenv(envSignKeysName); envSignKeys != "" {
+		config.SignKeys = envSignKeys
+	}
+
+	if envSignKeysFile := os.Getenv(envSignKeysFileName); envSignKeysFile != "" {
+		config.SignKeysFile = envSignKeysFile
+	}
+
+	if envSignMaxSkew := os.Getenv(envSignMaxSkewName); envSignMaxSkew != "" {
+		value, err := time.ParseDuration(envSignMaxSkew)
+		if err == nil {
+			config.SignMaxSkew = value
+		}
+	}
+
+	if envAgentsFile := os.Getenv(envAgentsFileName); envAgentsFile != "" {
+		config.AgentsFile = envAgentsFile
+	}
+
 	if envCryptoKey := os.Getenv(envCryptoKeyName); envCryptoKey != "" {
 		config.PrivateKeyFile = envCryptoKey
 	}
 
+	if envHistoryRetention := os.Getenv(envHistoryRetentionName); envHistoryRetention != "" {
+		value, err := time.ParseDuration(envHistoryRetention)
+		if err == nil {
+			config.HistoryRetention = value
+		}
+	}
+
+	if envAlertRulesFile := os.Getenv(envAlertRulesFileName); envAlertRulesFile != "" {
+		config.AlertRulesFile = envAlertRulesFile
+	}
+
+	if envAlertInterval := os.Getenv(envAlertIntervalName); envAlertInterval != "" {
+		value, err := time.ParseDuration(envAlertInterval)
+		if err == nil {
+			config.AlertInterval = value
+		}
+	}
+
+	if envWebhookURLs := os.Getenv(envWebhookURLsName); envWebhookURLs != "" {
+		config.WebhookURLs = splitList(envWebhookURLs)
+	}
+
+	if envWebhookKey := os.Getenv(envWebhookKeyName); envWebhookKey != "" {
+		config.WebhookKey = envWebhookKey
+	}
+
+	if envStaleThreshold := os.Getenv(envStaleThresholdName); envStaleThreshold != "" {
+		value, err := time.ParseDuration(envStaleThreshold)
+		if err == nil {
+			config.StaleThreshold = value
+		}
+	}
+
+	if envReportInterval := os.Getenv(envReportIntervalName); envReportInterval != "" {
+		value, err := time.ParseDuration(envReportInterval)
+		if err == nil {
+			config.ReportInterval = value
+		}
+	}
+
+	if envTrustedSubnet := os.Getenv(envTrustedSubnetName); envTrustedSubnet != "" {
+		config.TrustedSubnet = envTrustedSubnet
+	}
+
+	if envTrustedReads := os.Getenv(envTrustedReadsName); envTrustedReads != "" {
+		config.TrustedSubnetReads = envTrustedReads == "true"
+	}
+
+	if envTLSCertFile := os.Getenv(envTLSCertFileName); envTLSCertFile != "" {
+		config.TLSCertFile = envTLSCertFile
+	}
+
+	if envTLSKeyFile := os.Getenv(envTLSKeyFileName); envTLSKeyFile != "" {
+		config.TLSKeyFile = envTLSKeyFile
+	}
+
+	if envTLSClientCAFile := os.Getenv(envTLSClientCAFileName); envTLSClientCAFile != "" {
+		config.TLSClientCAFile = envTLSClientCAFile
+	}
+
+	if envGRPCAddr := os.Getenv(envGRPCAddrName); envGRPCAddr != "" {
+		config.GRPCAddr = envGRPCAddr
+	}
+
+	if envStatsDAddr := os.Getenv(envStatsDAddrName); envStatsDAddr != "" {
+		config.StatsDAddr = envStatsDAddr
+	}
+
+	if envStatsDFlush := os.Getenv(envStatsDFlushName); envStatsDFlush != "" {
+		value, err := time.ParseDuration(envStatsDFlush)
+		if err == nil {
+			config.StatsDFlush = value
+		}
+	}
+
+	if envGraphiteAddr := os.Getenv(envGraphiteAddrName); envGraphiteAddr != "" {
+		config.GraphiteAddr = envGraphiteAddr
+	}
+
+	if envInflux := os.Getenv(envInfluxName); envInflux != "" {
+		config.Influx = envInflux == "true"
+	}
+
+	if envPrometheusWrite := os.Getenv(envPrometheusWriteName); envPrometheusWrite != "" {
+		config.PrometheusWrite = envPrometheusWrite == "true"
+	}
+
+	if envOTLP := os.Getenv(envOTLPName); envOTLP != "" {
+		config.OTLP = envOTLP == "true"
+	}
+
+	if envIngestInsecure := os.Getenv(envIngestInsecureName); envIngestInsecure != "" {
+		config.IngestInsecure = envIngestInsecure == "true"
+	}
+
+	if envCounterSuffix := os.Getenv(envCounterSuffixName); envCounterSuffix != "" {
+		config.CounterSuffix = envCounterSuffix
+	}
+
+	if envCounterFields := os.Getenv(envCounterFieldsName); envCounterFields != "" {
+		config.CounterFields = splitList(envCounterFields)
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
+// validate checks values which the server can't work with.
+func (c *Config) validate() error {
+	if c.AlertInterval <= 0 {
+		return fmt.Errorf("%w: alert interval must be positive, got %s", ErrInvalidInterval, c.AlertInterval)
+	}
+	if c.StatsDFlush <= 0 {
+		return fmt.Errorf("%w: statsd flush interval must be positive, got %s", ErrInvalidInterval, c.StatsDFlush)
+	}
+	if (c.Influx || c.PrometheusWrite) && len(c.TrustedSubnet) == 0 && !c.IngestInsecure {
+		return fmt.Errorf("%w: influx and prometheus write endpoints require trusted subnet or ingest insecure mode", ErrInsecureIngest)
+	}
+
+	return nil
+}
+
+// splitList splits comma separated list skipping empty items.
+func splitList(value string) []string {
+	result := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
 func updateConfigFromFile(c *Config, path string) error {
 	f, err := os.Open(path)
 	if err != nil {
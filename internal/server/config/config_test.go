@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -25,21 +26,73 @@ func TestInitConfig(t *testing.T) {
 				os.Setenv(envRunAddrName, "127.0.0.1:8081")
 				os.Setenv(envKeyName, "key")
 				os.Setenv(envCryptoKeyName, "public key")
+				os.Setenv(envSignKeysName, "2024-04:old secret,2024-05:new secret")
+				os.Setenv(envSignKeysFileName, "/tmp/sign_keys.json")
+				os.Setenv(envSignMaxSkewName, "5m")
+				os.Setenv(envAgentsFileName, "/tmp/agents.json")
 				os.Setenv(envStoreIntervalName, "100s")
 				os.Setenv(envRestoreDataName, "true")
 				os.Setenv(envDatabaseDSNName, "")
 				os.Setenv(envFileStoragePathName, "/tmp/tmp.tmp")
+				os.Setenv(envHistoryRetentionName, "30m")
+				os.Setenv(envAlertRulesFileName, "/tmp/rules.txt")
+				os.Setenv(envAlertIntervalName, "5s")
+				os.Setenv(envWebhookURLsName, "http://127.0.0.1:9000/hook, http://127.0.0.1:9001/hook")
+				os.Setenv(envWebhookKeyName, "webhook key")
+				os.Setenv(envStaleThresholdName, "1m")
+				os.Setenv(envReportIntervalName, "20s")
+				os.Setenv(envTrustedSubnetName, "10.0.0.0/24")
+				os.Setenv(envTrustedReadsName, "true")
+				os.Setenv(envTLSCertFileName, "/tmp/server.crt")
+				os.Setenv(envTLSKeyFileName, "/tmp/server.key")
+				os.Setenv(envTLSClientCAFileName, "/tmp/ca.crt")
+				os.Setenv(envGRPCAddrName, "127.0.0.1:3200")
+				os.Setenv(envStatsDAddrName, "127.0.0.1:8125")
+				os.Setenv(envStatsDFlushName, "15s")
+				os.Setenv(envGraphiteAddrName, "127.0.0.1:2003")
+				os.Setenv(envInfluxName, "true")
+				os.Setenv(envPrometheusWriteName, "true")
+				os.Setenv(envOTLPName, "true")
+				os.Setenv(envIngestInsecureName, "true")
+				os.Setenv(envCounterSuffixName, ".count")
+				os.Setenv(envCounterFieldsName, "count, requests")
 			},
 			want: want{
 				cfg: &Config{
-					Key:             "key",
-					ServerAddr:      "127.0.0.1:8081",
-					StoreInternal:   time.Duration(100) * time.Second,
-					FileStoragePath: "/tmp/tmp.tmp",
-					DatabaseDSN:     "",
-					PrivateKeyFile:  "public key",
-					RestoreData:     true,
-					LoggerLevel:     "info",
+					Key:                "key",
+					SignKeys:           "2024-04:old secret,2024-05:new secret",
+					SignKeysFile:       "/tmp/sign_keys.json",
+					SignMaxSkew:        5 * time.Minute,
+					AgentsFile:         "/tmp/agents.json",
+					ServerAddr:         "127.0.0.1:8081",
+					StoreInternal:      time.Duration(100) * time.Second,
+					HistoryRetention:   30 * time.Minute,
+					AlertRulesFile:     "/tmp/rules.txt",
+					AlertInterval:      5 * time.Second,
+					WebhookURLs:        []string{"http://127.0.0.1:9000/hook", "http://127.0.0.1:9001/hook"},
+					WebhookKey:         "webhook key",
+					StaleThreshold:     time.Minute,
+					ReportInterval:     20 * time.Second,
+					TrustedSubnet:      "10.0.0.0/24",
+					TrustedSubnetReads: true,
+					TLSCertFile:        "/tmp/server.crt",
+					TLSKeyFile:         "/tmp/server.key",
+					TLSClientCAFile:    "/tmp/ca.crt",
+					GRPCAddr:           "127.0.0.1:3200",
+					StatsDAddr:         "127.0.0.1:8125",
+					StatsDFlush:        15 * time.Second,
+					GraphiteAddr:       "127.0.0.1:2003",
+					Influx:             true,
+					PrometheusWrite:    true,
+					OTLP:               true,
+					IngestInsecure:     true,
+					CounterSuffix:      ".count",
+					CounterFields:      []string{"count", "requests"},
+					FileStoragePath:    "/tmp/tmp.tmp",
+					DatabaseDSN:        "",
+					PrivateKeyFile:     "public key",
+					RestoreData:        true,
+					LoggerLevel:        "info",
 				},
 				err: nil,
 			},
@@ -54,3 +107,57 @@ func TestInitConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_validate(t *testing.T) {
+	tests := []struct {
+		want error
+		name string
+		cfg  Config
+	}{
+		{
+			name: "Zero alert interval",
+			cfg:  Config{AlertInterval: 0, StatsDFlush: time.Second},
+			want: fmt.Errorf("%w: alert interval must be positive, got 0s", ErrInvalidInterval),
+		},
+		{
+			name: "Negative alert interval",
+			cfg:  Config{AlertInterval: -time.Second, StatsDFlush: time.Second},
+			want: fmt.Errorf("%w: alert interval must be positive, got -1s", ErrInvalidInterval),
+		},
+		{
+			name: "Zero statsd flush interval",
+			cfg:  Config{AlertInterval: time.Second, StatsDFlush: 0},
+			want: fmt.Errorf("%w: statsd flush interval must be positive, got 0s", ErrInvalidInterval),
+		},
+		{
+			name: "Influx without trusted subnet",
+			cfg:  Config{AlertInterval: time.Second, StatsDFlush: time.Second, Influx: true},
+			want: fmt.Errorf("%w: influx and prometheus write endpoints require trusted subnet or ingest insecure mode", ErrInsecureIngest),
+		},
+		{
+			name: "Prometheus write without trusted subnet",
+			cfg:  Config{AlertInterval: time.Second, StatsDFlush: time.Second, PrometheusWrite: true},
+			want: fmt.Errorf("%w: influx and prometheus write endpoints require trusted subnet or ingest insecure mode", ErrInsecureIngest),
+		},
+		{
+			name: "Influx from trusted subnet",
+			cfg:  Config{AlertInterval: time.Second, StatsDFlush: time.Second, Influx: true, TrustedSubnet: "10.0.0.0/24"},
+			want: nil,
+		},
+		{
+			name: "Influx in insecure mode",
+			cfg:  Config{AlertInterval: time.Second, StatsDFlush: time.Second, Influx: true, IngestInsecure: true},
+			want: nil,
+		},
+		{
+			name: "Valid config",
+			cfg:  Config{AlertInterval: time.Second, StatsDFlush: time.Second},
+			want: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.cfg.validate())
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	// Registers gzip compressor used by agents, it replaces middleware.UnzipContent for gRPC transport.
+	_ "google.golang.org/grpc/encoding/gzip"
+
+	"github.com/e1m0re/grdn/internal/api/grpcapi"
+	pb "github.com/e1m0re/grdn/internal/proto"
+	"github.com/e1m0re/grdn/internal/server/interceptors"
+	"github.com/e1m0re/grdn/internal/service"
+	"github.com/e1m0re/grdn/internal/service/signing"
+)
+
+// newGRPCServer returns gRPC server of metrics delivery. Its interceptors check calls the same way the router
+// middleware checks HTTP requests, all calls are checked as they update metrics.
+func newGRPCServer(services *service.ServerServices, signKeys signing.KeySet, signMaxSkew time.Duration, trustedSubnet *net.IPNet, tlsConfig *tls.Config) *grpc.Server {
+	chain := make([]interceptors.Interceptor, 0, 3)
+	if trustedSubnet != nil {
+		chain = append(chain, interceptors.TrustedSubnet(trustedSubnet))
+	}
+	chain = append(chain, interceptors.ClientCertIdentity())
+	switch {
+	case services.AgentsRegistry != nil:
+		chain = append(chain, interceptors.AgentAuth(services.AgentsRegistry, signMaxSkew))
+	case len(signKeys) > 0:
+		chain = append(chain, interceptors.SignChecking(signKeys, signMaxSkew))
+	}
+
+	unary := make([]grpc.UnaryServerInterceptor, 0, len(chain))
+	stream := make([]grpc.StreamServerInterceptor, 0, len(chain))
+	for _, i := range chain {
+		unary = append(unary, i.Unary())
+		stream = append(stream, i.Stream())
+	}
+
+	options := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	server := grpc.NewServer(options...)
+	pb.RegisterMetricsServer(server, grpcapi.NewMetricsServer(services.MetricsManager))
+
+	return server
+}
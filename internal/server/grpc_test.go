@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service"
+	"github.com/e1m0re/grdn/internal/service/apiclient"
+	"github.com/e1m0re/grdn/internal/service/identity"
+	metricsMocks "github.com/e1m0re/grdn/internal/service/metrics/mocks"
+	"github.com/e1m0re/grdn/internal/service/signing"
+)
+
+func Test_newGRPCServer(t *testing.T) {
+	_, localSubnet, err := net.ParseCIDR("127.0.0.0/8")
+	require.NoError(t, err)
+	_, otherSubnet, err := net.ParseCIDR("10.0.0.0/24")
+	require.NoError(t, err)
+	registry, err := identity.NewRegistry(map[string]identity.Credentials{"web-1": {Key: "web secret", Token: "web token"}})
+	require.NoError(t, err)
+	data := []byte(`[{"id":"PollCount","type":"counter","delta":5},{"id":"Alloc","type":"gauge","value":0.5}]`)
+
+	type args struct {
+		registry      identity.Registry
+		signKeys      signing.KeySet
+		trustedSubnet *net.IPNet
+		agentID       string
+		token         string
+		keyID         string
+		key           []byte
+	}
+	type want struct {
+		agentID string
+		code    codes.Code
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "call without checks",
+			want: want{code: codes.OK},
+		},
+		{
+			name: "call signed with rotated key",
+			args: args{
+				signKeys: signing.KeySet{"": []byte("legacy secret"), "2024-05": []byte("new secret")},
+				keyID:    "2024-05",
+				key:      []byte("new secret"),
+			},
+			want: want{code: codes.OK},
+		},
+		{
+			name: "call signed with unknown key",
+			args: args{
+				signKeys: signing.KeySet{"": []byte("legacy secret")},
+				key:      []byte("new secret"),
+			},
+			want: want{code: codes.Unauthenticated},
+		},
+		{
+			name: "call of registered agent",
+			args: args{registry: registry, agentID: "web-1", token: "web token", key: []byte("web secret")},
+			want: want{code: codes.OK, agentID: "web-1"},
+		},
+		{
+			name: "call of unknown agent",
+			args: args{registry: registry, agentID: "web-2", token: "web token", key: []byte("web secret")},
+			want: want{code: codes.Unauthenticated},
+		},
+		{
+			name: "call from trusted subnet",
+			args: args{trustedSubnet: localSubnet},
+			want: want{code: codes.OK},
+		},
+		{
+			name: "call from untrusted subnet",
+			args: args{trustedSubnet: otherSubnet},
+			want: want{code: codes.PermissionDenied},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockMetricsManager := metricsMocks.NewManager(t)
+			if test.want.code == codes.OK {
+				mockMetricsManager.
+					On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
+					Run(func(args mock.Arguments) {
+						assert.Equal(t, test.want.agentID, identity.AgentIDFromContext(args.Get(0).(context.Context)))
+						assert.Len(t, args.Get(1).(models.MetricsList), 2)
+					}).
+					Return(nil)
+			}
+			services := &service.ServerServices{AgentsRegistry: test.args.registry, MetricsManager: mockMetricsManager}
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			grpcServer := newGRPCServer(services, test.args.signKeys, 0, test.args.trustedSubnet, nil)
+			go func() { _ = grpcServer.Serve(listener) }()
+			defer grpcServer.Stop()
+
+			client, err := apiclient.NewGRPCClient(listener.Addr().String(), test.args.agentID, test.args.token, test.args.keyID, test.args.key, nil)
+			require.NoError(t, err)
+
+			err = client.SendMetricsData(&data)
+			assert.Equal(t, test.want.code, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/e1m0re/grdn/internal/service/identity"
+	"github.com/e1m0re/grdn/internal/service/signing"
+)
+
+// AgentAuth authenticates calls of agents registered in the registry like middleware.AgentAuth does for HTTP requests
+// and attaches the agent ID to the call context.
+func AgentAuth(registry identity.Registry, maxSkew time.Duration) Interceptor {
+	nonces := newNonceCache(maxSkew)
+
+	return Interceptor{check: func(ctx context.Context, fullMethod string, request any) (context.Context, error) {
+		agentID := identity.AgentIDFromContext(ctx)
+		authenticated := agentID != ""
+		if !authenticated {
+			agentID = getMetadata(ctx, strings.ToLower(identity.AgentIDHeader))
+		}
+
+		credentials, ok := registry.Get(agentID)
+		if !ok {
+			slog.Warn("call of unknown agent is rejected", slog.String("agent", agentID))
+			return nil, status.Error(codes.Unauthenticated, "unknown agent")
+		}
+
+		if authenticated {
+			return ctx, nil
+		}
+
+		if credentials.Token != "" && !checkToken(ctx, credentials.Token) {
+			slog.Warn("invalid agent token", slog.String("agent", agentID))
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+
+		if credentials.Key != "" {
+			keys := signing.KeySet{agentID: []byte(credentials.Key)}
+			if !verifySign(ctx, fullMethod, request, keys, agentID, nonces, maxSkew) {
+				slog.Warn("invalid agent sign", slog.String("agent", agentID))
+				return nil, status.Error(codes.Unauthenticated, "invalid sign")
+			}
+		}
+
+		return identity.WithAgentID(ctx, agentID), nil
+	}}
+}
+
+// checkToken compares the bearer token of the call with the expected one in constant time.
+func checkToken(ctx context.Context, token string) bool {
+	actual, ok := strings.CutPrefix(getMetadata(ctx, "authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(actual), []byte(token)) == 1
+}
@@ -0,0 +1,94 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/e1m0re/grdn/internal/proto"
+	"github.com/e1m0re/grdn/internal/service/identity"
+)
+
+func TestAgentAuth(t *testing.T) {
+	registry, err := identity.NewRegistry(map[string]identity.Credentials{
+		"web-1": {Key: "web secret"},
+		"db-1":  {Token: "db token"},
+	})
+	require.NoError(t, err)
+	agentIDKey := strings.ToLower(identity.AgentIDHeader)
+	request := &pb.UpdateRequest{}
+
+	type want struct {
+		agentID string
+		code    codes.Code
+	}
+	tests := []struct {
+		name string
+		ctx  func() context.Context
+		want want
+	}{
+		{
+			name: "anonymous call",
+			ctx:  context.Background,
+			want: want{code: codes.Unauthenticated},
+		},
+		{
+			name: "agent signed call with its key",
+			ctx: func() context.Context {
+				return signedContext(t, []byte("web secret"), pb.Metrics_Update_FullMethodName, request, agentIDKey, "web-1")
+			},
+			want: want{code: codes.OK, agentID: "web-1"},
+		},
+		{
+			name: "agent signed call with other key",
+			ctx: func() context.Context {
+				return signedContext(t, []byte("db secret"), pb.Metrics_Update_FullMethodName, request, agentIDKey, "web-1")
+			},
+			want: want{code: codes.Unauthenticated},
+		},
+		{
+			name: "agent passed token",
+			ctx: func() context.Context {
+				return metadata.NewIncomingContext(context.Background(), metadata.Pairs(agentIDKey, "db-1", "authorization", "Bearer db token"))
+			},
+			want: want{code: codes.OK, agentID: "db-1"},
+		},
+		{
+			name: "agent passed invalid token",
+			ctx: func() context.Context {
+				return metadata.NewIncomingContext(context.Background(), metadata.Pairs(agentIDKey, "db-1", "authorization", "Bearer web token"))
+			},
+			want: want{code: codes.Unauthenticated},
+		},
+		{
+			name: "agent identified by certificate",
+			ctx: func() context.Context {
+				return identity.WithAgentID(context.Background(), "db-1")
+			},
+			want: want{code: codes.OK, agentID: "db-1"},
+		},
+		{
+			name: "unknown agent identified by certificate",
+			ctx: func() context.Context {
+				return identity.WithAgentID(context.Background(), "web-2")
+			},
+			want: want{code: codes.Unauthenticated},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, err := callUnary(AgentAuth(registry, time.Minute), test.ctx(), pb.Metrics_Update_FullMethodName, request)
+			require.Equal(t, test.want.code, status.Code(err))
+			if err == nil {
+				assert.Equal(t, test.want.agentID, identity.AgentIDFromContext(ctx))
+			}
+		})
+	}
+}
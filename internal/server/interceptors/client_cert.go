@@ -0,0 +1,44 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/e1m0re/grdn/internal/service/certs"
+	"github.com/e1m0re/grdn/internal/service/identity"
+)
+
+// ClientCertIdentity attaches the common name of the verified client certificate to the call context as the agent
+// ID. Calls whose x-agent-id metadata differs from the certificate are rejected with Unauthenticated code.
+func ClientCertIdentity() Interceptor {
+	return Interceptor{check: func(ctx context.Context, _ string, _ any) (context.Context, error) {
+		p, ok := peer.FromContext(ctx)
+		if !ok {
+			return ctx, nil
+		}
+		tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+		if !ok {
+			return ctx, nil
+		}
+		agentID, ok := certs.CommonName(&tlsInfo.State)
+		if !ok {
+			return ctx, nil
+		}
+
+		if value := getMetadata(ctx, strings.ToLower(identity.AgentIDHeader)); value != "" && value != agentID {
+			slog.Warn("agent ID doesn't match client certificate",
+				slog.String("agent", value),
+				slog.String("certificate", agentID),
+			)
+			return nil, status.Error(codes.Unauthenticated, "agent ID doesn't match client certificate")
+		}
+
+		return identity.WithAgentID(ctx, agentID), nil
+	}}
+}
@@ -0,0 +1,72 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/e1m0re/grdn/internal/service/identity"
+)
+
+func TestClientCertIdentity(t *testing.T) {
+	type args struct {
+		commonName string
+		agentID    string
+	}
+	type want struct {
+		agentID string
+		code    codes.Code
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "call without certificate",
+			args: args{agentID: "web-1"},
+			want: want{code: codes.OK},
+		},
+		{
+			name: "call with certificate",
+			args: args{commonName: "web-1"},
+			want: want{code: codes.OK, agentID: "web-1"},
+		},
+		{
+			name: "call with certificate of other agent",
+			args: args{commonName: "web-1", agentID: "web-2"},
+			want: want{code: codes.Unauthenticated},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if len(test.args.commonName) > 0 {
+				state := tls.ConnectionState{
+					VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: test.args.commonName}}}},
+				}
+				ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+			}
+			if len(test.args.agentID) > 0 {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(strings.ToLower(identity.AgentIDHeader), test.args.agentID))
+			}
+
+			handlerCtx, err := callUnary(ClientCertIdentity(), ctx, "/grdn.Metrics/Update", nil)
+			require.Equal(t, test.want.code, status.Code(err))
+			if err == nil {
+				assert.Equal(t, test.want.agentID, identity.AgentIDFromContext(handlerCtx))
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+// Package interceptors contains interceptors for processing incoming gRPC calls. They replace HTTP middleware for the
+// gRPC transport: metadata keys are the lower-cased names of the corresponding HTTP headers.
+package interceptors
@@ -0,0 +1,60 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// checkFunc checks the call and returns the context passed to its handler. The request is nil for streams.
+type checkFunc func(ctx context.Context, fullMethod string, request any) (context.Context, error)
+
+// Interceptor checks unary and stream calls the same way.
+type Interceptor struct {
+	check checkFunc
+}
+
+// Unary returns interceptor of unary calls.
+func (i Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := i.check(ctx, info.FullMethod, request)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, request)
+	}
+}
+
+// Stream returns interceptor of streams. The stream is checked once when it is opened.
+func (i Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.check(stream.Context(), info.FullMethod, nil)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// contextStream overrides context of the stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (cs *contextStream) Context() context.Context {
+	return cs.ctx
+}
+
+// getMetadata returns the first value of the incoming metadata key.
+func getMetadata(ctx context.Context, key string) string {
+	values := metadata.ValueFromIncomingContext(ctx, key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
@@ -0,0 +1,62 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/e1m0re/grdn/internal/service/identity"
+)
+
+// callUnary passes the call through the interceptor and returns the context of the handler.
+func callUnary(i Interceptor, ctx context.Context, fullMethod string, request any) (context.Context, error) {
+	var handlerCtx context.Context
+	_, err := i.Unary()(ctx, request, &grpc.UnaryServerInfo{FullMethod: fullMethod}, func(ctx context.Context, _ any) (any, error) {
+		handlerCtx = ctx
+		return nil, nil
+	})
+
+	return handlerCtx, err
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ts *testStream) Context() context.Context {
+	return ts.ctx
+}
+
+func TestInterceptor_Stream(t *testing.T) {
+	i := Interceptor{check: func(ctx context.Context, fullMethod string, request any) (context.Context, error) {
+		if request != nil || fullMethod != "/grdn.Metrics/UpdateStream" {
+			return nil, errors.New("unexpected call")
+		}
+
+		return identity.WithAgentID(ctx, "web-1"), nil
+	}}
+
+	var agentID string
+	err := i.Stream()(nil, &testStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/grdn.Metrics/UpdateStream"},
+		func(_ any, stream grpc.ServerStream) error {
+			agentID = identity.AgentIDFromContext(stream.Context())
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "web-1", agentID)
+
+	failed := Interceptor{check: func(ctx context.Context, _ string, _ any) (context.Context, error) {
+		return nil, errors.New("rejected")
+	}}
+	err = failed.Stream()(nil, &testStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, func(any, grpc.ServerStream) error {
+		t.Fatal("handler must not be called")
+		return nil
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,90 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/e1m0re/grdn/internal/proto"
+	"github.com/e1m0re/grdn/internal/service/signing"
+)
+
+// nonceCacheSize limits count of nonces remembered for replay protection.
+const nonceCacheSize = 100_000
+
+// newNonceCache returns cache of nonces if replay protection is enabled by positive maxSkew.
+func newNonceCache(maxSkew time.Duration) signing.NonceCache {
+	if maxSkew <= 0 {
+		return nil
+	}
+
+	return signing.NewNonceCache(nonceCacheSize)
+}
+
+// SignChecking executes check of calls sign like middleware.SignChecking does for HTTP requests. The sum covers the
+// request message of unary calls and the method name of streams (see proto.SignedData). Messages of streams are not
+// signed, so streams are accepted over TLS connections only.
+func SignChecking(keys signing.KeySet, maxSkew time.Duration) Interceptor {
+	nonces := newNonceCache(maxSkew)
+
+	return Interceptor{check: func(ctx context.Context, fullMethod string, request any) (context.Context, error) {
+		keyID := getMetadata(ctx, strings.ToLower(signing.KeyIDHeader))
+		if !verifySign(ctx, fullMethod, request, keys, keyID, nonces, maxSkew) {
+			return nil, status.Error(codes.Unauthenticated, "invalid sign")
+		}
+
+		return ctx, nil
+	}}
+}
+
+// verifySign checks the sum of the call made with the key. Timestamp and nonce are checked if nonces cache is passed.
+// Streams (with nil request) are rejected if the connection is not protected by TLS.
+func verifySign(ctx context.Context, fullMethod string, request any, keys signing.KeySet, keyID string, nonces signing.NonceCache, maxSkew time.Duration) bool {
+	if request == nil && !isTLS(ctx) {
+		slog.Warn("signed stream over connection without TLS is rejected", slog.String("method", fullMethod))
+		return false
+	}
+
+	ctrlSum := getMetadata(ctx, strings.ToLower(signing.HashHeader))
+	if ctrlSum == "" {
+		return false
+	}
+
+	data, err := pb.SignedData(fullMethod, request)
+	if err != nil {
+		return false
+	}
+
+	payload := data
+	timestamp := getMetadata(ctx, strings.ToLower(signing.TimestampHeader))
+	nonce := getMetadata(ctx, strings.ToLower(signing.NonceHeader))
+	if timestamp != "" || nonce != "" || nonces != nil {
+		if timestamp == "" || nonce == "" {
+			return false
+		}
+		payload = signing.SignedPayload(timestamp, nonce, data)
+	}
+
+	if !keys.Verify(keyID, payload, ctrlSum) {
+		return false
+	}
+
+	return nonces == nil || signing.CheckReplay(nonces, timestamp, nonce, maxSkew)
+}
+
+// isTLS reports whether the call is made over TLS connection.
+func isTLS(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	_, ok = p.AuthInfo.(credentials.TLSInfo)
+
+	return ok
+}
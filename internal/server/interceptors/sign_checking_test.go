@@ -0,0 +1,122 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/e1m0re/grdn/internal/models"
+	pb "github.com/e1m0re/grdn/internal/proto"
+	"github.com/e1m0re/grdn/internal/service/signing"
+)
+
+// signedContext returns context with metadata of the call signed with the key.
+func signedContext(t *testing.T, key []byte, fullMethod string, request any, pairs ...string) context.Context {
+	data, err := pb.SignedData(fullMethod, request)
+	require.NoError(t, err)
+	nonce, err := signing.NewNonce()
+	require.NoError(t, err)
+	timestamp := signing.FormatTimestamp(time.Now())
+
+	md := metadata.Pairs(pairs...)
+	md.Set(strings.ToLower(signing.TimestampHeader), timestamp)
+	md.Set(strings.ToLower(signing.NonceHeader), nonce)
+	md.Set(strings.ToLower(signing.HashHeader), signing.Sum(key, signing.SignedPayload(timestamp, nonce, data)))
+
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+// tlsContext returns context of the call made over TLS connection.
+func tlsContext(ctx context.Context) context.Context {
+	return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{}})
+}
+
+func TestSignChecking(t *testing.T) {
+	keys := signing.KeySet{"": []byte("legacy secret"), "2024-05": []byte("new secret")}
+	request := &pb.UpdateRequest{Metrics: []*pb.Metric{{Id: "PollCount", Type: models.CounterType, Delta: 5}}}
+
+	tests := []struct {
+		request    any
+		ctx        func() context.Context
+		name       string
+		fullMethod string
+		want       codes.Code
+	}{
+		{
+			name:       "call without sign",
+			ctx:        context.Background,
+			fullMethod: pb.Metrics_Update_FullMethodName,
+			request:    request,
+			want:       codes.Unauthenticated,
+		},
+		{
+			name: "call signed with legacy key",
+			ctx: func() context.Context {
+				return signedContext(t, []byte("legacy secret"), pb.Metrics_Update_FullMethodName, request)
+			},
+			fullMethod: pb.Metrics_Update_FullMethodName,
+			request:    request,
+			want:       codes.OK,
+		},
+		{
+			name: "call signed with rotated key",
+			ctx: func() context.Context {
+				return signedContext(t, []byte("new secret"), pb.Metrics_Update_FullMethodName, request,
+					strings.ToLower(signing.KeyIDHeader), "2024-05")
+			},
+			fullMethod: pb.Metrics_Update_FullMethodName,
+			request:    request,
+			want:       codes.OK,
+		},
+		{
+			name: "call with tampered request",
+			ctx: func() context.Context {
+				return signedContext(t, []byte("legacy secret"), pb.Metrics_Update_FullMethodName, &pb.UpdateRequest{})
+			},
+			fullMethod: pb.Metrics_Update_FullMethodName,
+			request:    request,
+			want:       codes.Unauthenticated,
+		},
+		{
+			name: "stream signed with legacy key",
+			ctx: func() context.Context {
+				return tlsContext(signedContext(t, []byte("legacy secret"), pb.Metrics_UpdateStream_FullMethodName, nil))
+			},
+			fullMethod: pb.Metrics_UpdateStream_FullMethodName,
+			want:       codes.OK,
+		},
+		{
+			name: "signed stream without TLS",
+			ctx: func() context.Context {
+				return signedContext(t, []byte("legacy secret"), pb.Metrics_UpdateStream_FullMethodName, nil)
+			},
+			fullMethod: pb.Metrics_UpdateStream_FullMethodName,
+			want:       codes.Unauthenticated,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := callUnary(SignChecking(keys, time.Minute), test.ctx(), test.fullMethod, test.request)
+			assert.Equal(t, test.want, status.Code(err))
+		})
+	}
+}
+
+func TestSignChecking_replay(t *testing.T) {
+	i := SignChecking(signing.KeySet{"": []byte("legacy secret")}, time.Minute)
+	ctx := tlsContext(signedContext(t, []byte("legacy secret"), pb.Metrics_UpdateStream_FullMethodName, nil))
+
+	_, err := callUnary(i, ctx, pb.Metrics_UpdateStream_FullMethodName, nil)
+	require.NoError(t, err)
+	_, err = callUnary(i, ctx, pb.Metrics_UpdateStream_FullMethodName, nil)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,52 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"net"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RealIPKey is the metadata key containing IP address of the agent outbound interface.
+const RealIPKey = "x-real-ip"
+
+// TrustedSubnet rejects calls which come from addresses outside the subnet with PermissionDenied code. Both the
+// address passed by the agent in the x-real-ip metadata and the peer address must belong to the subnet.
+func TrustedSubnet(subnet *net.IPNet) Interceptor {
+	return Interceptor{check: func(ctx context.Context, _ string, _ any) (context.Context, error) {
+		realIP := getMetadata(ctx, RealIPKey)
+		if !inSubnet(subnet, realIP) || !inSubnet(subnet, peerIP(ctx)) {
+			slog.Warn("call from untrusted address is rejected",
+				slog.String("real_ip", realIP),
+				slog.String("peer", peerIP(ctx)),
+			)
+			return nil, status.Error(codes.PermissionDenied, "untrusted address")
+		}
+
+		return ctx, nil
+	}}
+}
+
+// peerIP returns IP address of the call peer without port.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+func inSubnet(subnet *net.IPNet, address string) bool {
+	ip := net.ParseIP(address)
+
+	return ip != nil && subnet.Contains(ip)
+}
@@ -0,0 +1,63 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestTrustedSubnet(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.0.0/24")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		realIP string
+		peerIP string
+		want   codes.Code
+	}{
+		{
+			name:   "call from trusted subnet",
+			realIP: "10.0.0.15",
+			peerIP: "10.0.0.15",
+			want:   codes.OK,
+		},
+		{
+			name:   "call without x-real-ip",
+			peerIP: "10.0.0.15",
+			want:   codes.PermissionDenied,
+		},
+		{
+			name:   "call with x-real-ip outside subnet",
+			realIP: "192.168.1.15",
+			peerIP: "10.0.0.15",
+			want:   codes.PermissionDenied,
+		},
+		{
+			name:   "call with spoofed x-real-ip",
+			realIP: "10.0.0.15",
+			peerIP: "192.168.1.15",
+			want:   codes.PermissionDenied,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{
+				Addr: &net.TCPAddr{IP: net.ParseIP(test.peerIP), Port: 51234},
+			})
+			if len(test.realIP) > 0 {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(RealIPKey, test.realIP))
+			}
+
+			_, err := callUnary(TrustedSubnet(subnet), ctx, "/grdn.Metrics/Update", nil)
+			assert.Equal(t, test.want, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/e1m0re/grdn/internal/service/identity"
+	"github.com/e1m0re/grdn/internal/service/signing"
+)
+
+// AgentAuth authenticates requests of agents registered in the registry and attaches the agent ID to the request
+// context. The agent is identified by the X-Agent-ID header and must present all its credentials: the bearer token
+// in the Authorization header and the sum of the request made with its key (checked like in SignChecking). Agents
+// already identified by the client certificate (see ClientCertIdentity) must be registered only.
+func AgentAuth(registry identity.Registry, maxSkew time.Duration) func(next http.Handler) http.Handler {
+	nonces := newNonceCache(maxSkew)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "GET" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			agentID := identity.AgentIDFromContext(r.Context())
+			authenticated := agentID != ""
+			if !authenticated {
+				agentID = r.Header.Get(identity.AgentIDHeader)
+			}
+
+			credentials, ok := registry.Get(agentID)
+			if !ok {
+				slog.Warn("request of unknown agent is rejected", slog.String("agent", agentID))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			if authenticated {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if credentials.Token != "" && !checkToken(r, credentials.Token) {
+				slog.Warn("invalid agent token", slog.String("agent", agentID))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			if credentials.Key != "" {
+				keys := signing.KeySet{agentID: []byte(credentials.Key)}
+				err := verifySign(r, keys, agentID, nonces, maxSkew)
+				if IsBodyTooLarge(err) {
+					http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				if err != nil {
+					slog.Warn("invalid agent sign", slog.String("agent", agentID))
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(identity.WithAgentID(r.Context(), agentID)))
+		})
+	}
+}
+
+// checkToken compares the bearer token of the request with the expected one in constant time.
+func checkToken(r *http.Request, token string) bool {
+	actual, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(actual), []byte(token)) == 1
+}
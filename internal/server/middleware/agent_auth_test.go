@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/service/identity"
+	"github.com/e1m0re/grdn/internal/service/signing"
+)
+
+func TestAgentAuth(t *testing.T) {
+	registry, err := identity.NewRegistry(map[string]identity.Credentials{
+		"web-1": {Key: "web secret"},
+		"db-1":  {Token: "db token"},
+		"api-1": {Key: "api secret", Token: "api token"},
+	})
+	require.NoError(t, err)
+	body := []byte("request body")
+
+	type args struct {
+		method  string
+		agentID string
+		key     string
+		token   string
+		// certAgentID is the agent identified by the client certificate.
+		certAgentID string
+	}
+	type want struct {
+		agentID    string
+		statusCode int
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "GET request",
+			args: args{method: http.MethodGet},
+			want: want{statusCode: http.StatusOK},
+		},
+		{
+			name: "anonymous request",
+			args: args{method: http.MethodPost},
+			want: want{statusCode: http.StatusUnauthorized},
+		},
+		{
+			name: "unknown agent",
+			args: args{method: http.MethodPost, agentID: "web-2", key: "web secret"},
+			want: want{statusCode: http.StatusUnauthorized},
+		},
+		{
+			name: "agent signed request with its key",
+			args: args{method: http.MethodPost, agentID: "web-1", key: "web secret"},
+			want: want{statusCode: http.StatusOK, agentID: "web-1"},
+		},
+		{
+			name: "agent signed request with key of other agent",
+			args: args{method: http.MethodPost, agentID: "web-1", key: "api secret"},
+			want: want{statusCode: http.StatusUnauthorized},
+		},
+		{
+			name: "agent passed token",
+			args: args{method: http.MethodPost, agentID: "db-1", token: "db token"},
+			want: want{statusCode: http.StatusOK, agentID: "db-1"},
+		},
+		{
+			name: "agent passed invalid token",
+			args: args{method: http.MethodPost, agentID: "db-1", token: "api token"},
+			want: want{statusCode: http.StatusUnauthorized},
+		},
+		{
+			name: "agent passed all credentials",
+			args: args{method: http.MethodPost, agentID: "api-1", key: "api secret", token: "api token"},
+			want: want{statusCode: http.StatusOK, agentID: "api-1"},
+		},
+		{
+			name: "agent passed token only",
+			args: args{method: http.MethodPost, agentID: "api-1", token: "api token"},
+			want: want{statusCode: http.StatusUnauthorized},
+		},
+		{
+			name: "agent identified by certificate",
+			args: args{method: http.MethodPost, certAgentID: "api-1"},
+			want: want{statusCode: http.StatusOK, agentID: "api-1"},
+		},
+		{
+			name: "unknown agent identified by certificate",
+			args: args{method: http.MethodPost, certAgentID: "web-2"},
+			want: want{statusCode: http.StatusUnauthorized},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var agentID string
+			r := chi.NewRouter()
+			if len(test.args.certAgentID) > 0 {
+				r.Use(func(next http.Handler) http.Handler {
+					return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						next.ServeHTTP(w, r.WithContext(identity.WithAgentID(r.Context(), test.args.certAgentID)))
+					})
+				})
+			}
+			r.Use(AgentAuth(registry, time.Minute))
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+			r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+				agentID = identity.AgentIDFromContext(r.Context())
+			})
+
+			request := httptest.NewRequest(test.args.method, "/", bytes.NewReader(body))
+			if len(test.args.agentID) > 0 {
+				request.Header.Set(identity.AgentIDHeader, test.args.agentID)
+			}
+			if len(test.args.token) > 0 {
+				request.Header.Set("Authorization", "Bearer "+test.args.token)
+			}
+			if len(test.args.key) > 0 {
+				nonce, err := signing.NewNonce()
+				require.NoError(t, err)
+				timestamp := signing.FormatTimestamp(time.Now())
+				request.Header.Set(signing.TimestampHeader, timestamp)
+				request.Header.Set(signing.NonceHeader, nonce)
+				request.Header.Set(signing.HashHeader, signing.Sum([]byte(test.args.key), signing.SignedPayload(timestamp, nonce, body)))
+			}
+
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, request)
+			response := recorder.Result()
+			defer response.Body.Close()
+
+			require.Equal(t, test.want.statusCode, response.StatusCode)
+			assert.Equal(t, test.want.agentID, agentID)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/e1m0re/grdn/internal/service/certs"
+	"github.com/e1m0re/grdn/internal/service/identity"
+)
+
+// ClientCertIdentity attaches the common name of the verified client certificate to the request context as the agent
+// ID. Requests whose X-Agent-ID header differs from the certificate are rejected with 401 status.
+func ClientCertIdentity() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			agentID, ok := certs.PeerCommonName(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if header := r.Header.Get(identity.AgentIDHeader); header != "" && header != agentID {
+				slog.Warn("agent ID doesn't match client certificate",
+					slog.String("agent", header),
+					slog.String("certificate", agentID),
+				)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(identity.WithAgentID(r.Context(), agentID)))
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/e1m0re/grdn/internal/service/identity"
+)
+
+func TestClientCertIdentity(t *testing.T) {
+	type args struct {
+		commonName string
+		agentID    string
+	}
+	type want struct {
+		agentID    string
+		statusCode int
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "request without certificate",
+			args: args{agentID: "web-1"},
+			want: want{statusCode: http.StatusOK},
+		},
+		{
+			name: "request with certificate",
+			args: args{commonName: "web-1"},
+			want: want{statusCode: http.StatusOK, agentID: "web-1"},
+		},
+		{
+			name: "request with certificate and same agent ID",
+			args: args{commonName: "web-1", agentID: "web-1"},
+			want: want{statusCode: http.StatusOK, agentID: "web-1"},
+		},
+		{
+			name: "request with certificate of other agent",
+			args: args{commonName: "web-1", agentID: "web-2"},
+			want: want{statusCode: http.StatusUnauthorized},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var agentID string
+			r := chi.NewRouter()
+			r.Use(ClientCertIdentity())
+			r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+				agentID = identity.AgentIDFromContext(r.Context())
+			})
+
+			request := httptest.NewRequest(http.MethodPost, "/", nil)
+			if len(test.args.commonName) > 0 {
+				request.TLS = &tls.ConnectionState{
+					VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: test.args.commonName}}}},
+				}
+			}
+			if len(test.args.agentID) > 0 {
+				request.Header.Set(identity.AgentIDHeader, test.args.agentID)
+			}
+
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, request)
+			response := recorder.Result()
+			defer response.Body.Close()
+
+			assert.Equal(t, test.want.statusCode, response.StatusCode)
+			assert.Equal(t, test.want.agentID, agentID)
+		})
+	}
+}
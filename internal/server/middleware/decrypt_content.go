@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"bytes"
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
@@ -8,34 +10,14 @@ import (
 	"github.com/e1m0re/grdn/internal/service/encryption"
 )
 
-type decryptReader struct {
-	r         io.ReadCloser
-	decryptor encryption.Decryptor
-}
-
-func newDecryptReader(r io.ReadCloser, decryptor encryption.Decryptor) *decryptReader {
-	return &decryptReader{
-		r:         r,
-		decryptor: decryptor,
-	}
-}
-
-func (c *decryptReader) Read(p []byte) (n int, err error) {
-	p, err = c.decryptor.Decrypt(p)
-
-	return len(p), err
-}
-
-func (c *decryptReader) Close() error {
-	if err := c.r.Close(); err != nil {
-		return err
-	}
-
-	return c.Close()
-}
+// maxEncryptedBodySize limits size of the encrypted request body read into memory.
+const maxEncryptedBodySize = 32 << 20
 
-// DecryptContent decrypts requests body.
+// DecryptContent decrypts requests body. The encrypted body is read completely (up to maxEncryptedBodySize bytes),
+// decrypted once and replaced with the plaintext.
 func DecryptContent(privateKeyFile string) func(next http.Handler) http.Handler {
+	decryptor, keyErr := encryption.NewDecryptor(privateKeyFile)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(
 			func(w http.ResponseWriter, r *http.Request) {
@@ -44,16 +26,35 @@ func DecryptContent(privateKeyFile string) func(next http.Handler) http.Handler
 					return
 				}
 
-				decryptor, err := encryption.NewDecryptor(privateKeyFile)
-				if err != nil {
-					slog.Error("internal server error", slog.String("error", err.Error()))
+				if keyErr != nil {
+					slog.Error("internal server error", slog.String("error", keyErr.Error()))
 					w.WriteHeader(http.StatusInternalServerError)
 					return
 				}
 
-				dr := newDecryptReader(r.Body, decryptor)
-				r.Body = dr
-				//defer r.Body.Close()
+				ciphertext, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxEncryptedBodySize))
+				r.Body.Close()
+				if err != nil {
+					var maxBytesError *http.MaxBytesError
+					if errors.As(err, &maxBytesError) {
+						http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+						return
+					}
+
+					slog.Error("reading request body failed", slog.String("error", err.Error()))
+					http.Error(w, "reading request body failed", http.StatusBadRequest)
+					return
+				}
+
+				plaintext, err := decryptor.Decrypt(ciphertext)
+				if err != nil {
+					slog.Error("decryption of request body failed", slog.String("error", err.Error()))
+					http.Error(w, "invalid encrypted request body", http.StatusBadRequest)
+					return
+				}
+
+				r.Body = io.NopCloser(bytes.NewReader(plaintext))
+				r.ContentLength = int64(len(plaintext))
 
 				next.ServeHTTP(w, r)
 			})
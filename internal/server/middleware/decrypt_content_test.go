@@ -5,6 +5,7 @@ import (
 	"github.com/e1m0re/grdn/internal/service/encryption"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/require"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -67,6 +68,43 @@ func TestDecryptContent(t *testing.T) {
 				body:       []byte("hello world!"),
 			},
 		},
+		{
+			name: "body is not encrypted",
+			args: args{
+				privateKeyFile: "/tmp/TestDecryptContent_private_key",
+				body:           []byte("hello world!"),
+				encryptBody:    false,
+				method:         "POST",
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "body too large",
+			args: args{
+				privateKeyFile: "/tmp/TestDecryptContent_private_key",
+				body:           make([]byte, maxEncryptedBodySize+1),
+				encryptBody:    false,
+				method:         "POST",
+			},
+			want: want{
+				statusCode: http.StatusRequestEntityTooLarge,
+			},
+		},
+		{
+			name: "successfully case (POST, body larger than read buffer)",
+			args: args{
+				privateKeyFile: "/tmp/TestDecryptContent_private_key",
+				body:           bytes.Repeat([]byte("hello world!"), 100000),
+				encryptBody:    true,
+				method:         "POST",
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				body:       bytes.Repeat([]byte("hello world!"), 100000),
+			},
+		},
 		{
 			name: "successfully case (POST)",
 			args: args{
@@ -87,7 +125,11 @@ func TestDecryptContent(t *testing.T) {
 
 			r := chi.NewRouter()
 			r.Use(DecryptContent(test.args.privateKeyFile))
-			r.Post("/", func(w http.ResponseWriter, r *http.Request) {})
+			r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.Equal(t, test.want.body, body)
+			})
 			r.Get("/", func(w http.ResponseWriter, r *http.Request) {})
 
 			body := test.args.body
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+)
+
+// LimitBody limits size of the request body read by the following middleware and handlers to maxSize bytes, so
+// middleware reading the whole body (sign checking, decryption) can't be forced to buffer arbitrary amount of data.
+// Requests declaring larger Content-Length are rejected with 413 status before the body is read. Compressed bodies are
+// limited again after decompression (see UnzipContent).
+func LimitBody(maxSize int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxSize {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// IsBodyTooLarge reports whether reading of the request body failed because the body exceeds the limit. Such requests
+// are answered with 413 status.
+func IsBodyTooLarge(err error) bool {
+	var maxBytesError *http.MaxBytesError
+	return errors.As(err, &maxBytesError)
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitBody(t *testing.T) {
+	type args struct {
+		body          []byte
+		contentLength int64
+	}
+	type want struct {
+		body       string
+		statusCode int
+	}
+	tests := []struct {
+		name string
+		want want
+		args args
+	}{
+		{
+			name: "Body within limit",
+			args: args{body: []byte("body"), contentLength: 4},
+			want: want{body: "body", statusCode: http.StatusOK},
+		},
+		{
+			name: "Declared length exceeds limit",
+			args: args{body: []byte("large body"), contentLength: 10},
+			want: want{body: "request body too large\n", statusCode: http.StatusRequestEntityTooLarge},
+		},
+		{
+			name: "Body of unknown length exceeds limit",
+			args: args{body: []byte("large body"), contentLength: -1},
+			want: want{body: "read failed\n", statusCode: http.StatusRequestEntityTooLarge},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Use(LimitBody(8))
+			r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if IsBodyTooLarge(err) {
+					http.Error(w, "read failed", http.StatusRequestEntityTooLarge)
+					return
+				}
+				_, _ = w.Write(body)
+			})
+
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(test.args.body))
+			request.ContentLength = test.args.contentLength
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, request)
+
+			assert.Equal(t, test.want.statusCode, recorder.Code)
+			assert.Equal(t, test.want.body, recorder.Body.String())
+		})
+	}
+}
+
+func TestIsBodyTooLarge(t *testing.T) {
+	assert.True(t, IsBodyTooLarge(fmt.Errorf("read: %w", &http.MaxBytesError{Limit: 8})))
+	assert.False(t, IsBodyTooLarge(errors.New("read failed")))
+	assert.False(t, IsBodyTooLarge(nil))
+}
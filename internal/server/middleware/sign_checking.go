@@ -2,15 +2,38 @@ package middleware
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
+	"errors"
 	"io"
 	"net/http"
+	"time"
+
+	"github.com/e1m0re/grdn/internal/service/signing"
 )
 
-// SignChecking executes check of requests sign.
-func SignChecking(key string) func(next http.Handler) http.Handler {
+// nonceCacheSize limits count of nonces remembered for replay protection.
+const nonceCacheSize = 100_000
+
+// errInvalidSign is the error of the request with missing or wrong sign, timestamp or nonce.
+var errInvalidSign = errors.New("invalid sign")
+
+// newNonceCache returns cache of nonces if replay protection is enabled by positive maxSkew.
+func newNonceCache(maxSkew time.Duration) signing.NonceCache {
+	if maxSkew <= 0 {
+		return nil
+	}
+
+	return signing.NewNonceCache(nonceCacheSize)
+}
+
+// SignChecking executes check of requests sign. The key is selected by the HashSHA256-KeyID header, requests without
+// the header are checked with the legacy key (with empty ID).
+//
+// If the request has timestamp and nonce headers, they are covered by the sum. If maxSkew is positive, they are
+// required: requests with timestamp differing from the server time more than maxSkew and requests with already seen
+// nonces are rejected.
+func SignChecking(keys signing.KeySet, maxSkew time.Duration) func(next http.Handler) http.Handler {
+	nonces := newNonceCache(maxSkew)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if r.Method == "GET" {
@@ -18,29 +41,52 @@ func SignChecking(key string) func(next http.Handler) http.Handler {
 				return
 			}
 
-			ctrlSum := r.Header.Get("HashSHA256")
-			if ctrlSum == "" {
-				w.WriteHeader(http.StatusBadRequest)
+			err := verifySign(r, keys, r.Header.Get(signing.KeyIDHeader), nonces, maxSkew)
+			if IsBodyTooLarge(err) {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
 				return
 			}
-
-			body, err := io.ReadAll(r.Body)
 			if err != nil {
 				w.WriteHeader(http.StatusBadRequest)
 				return
 			}
-			r.Body.Close()
-			r.Body = io.NopCloser(bytes.NewBuffer(body))
-
-			h := hmac.New(sha256.New, []byte(key))
-			h.Write(body)
-			sum := base64.StdEncoding.EncodeToString(h.Sum(nil))
-			if sum != ctrlSum {
-				w.WriteHeader(http.StatusBadRequest)
-				return
-			}
 
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+// verifySign checks the sum of the request body made with the key. The body is read and replaced with a copy.
+// Timestamp and nonce are checked if nonces cache is passed. The error of reading the body is returned as is.
+func verifySign(r *http.Request, keys signing.KeySet, keyID string, nonces signing.NonceCache, maxSkew time.Duration) error {
+	ctrlSum := r.Header.Get(signing.HashHeader)
+	if ctrlSum == "" {
+		return errInvalidSign
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	payload := body
+	timestamp := r.Header.Get(signing.TimestampHeader)
+	nonce := r.Header.Get(signing.NonceHeader)
+	if timestamp != "" || nonce != "" || nonces != nil {
+		if timestamp == "" || nonce == "" {
+			return errInvalidSign
+		}
+		payload = signing.SignedPayload(timestamp, nonce, body)
+	}
+
+	if !keys.Verify(keyID, payload, ctrlSum) {
+		return errInvalidSign
+	}
+	if nonces != nil && !signing.CheckReplay(nonces, timestamp, nonce, maxSkew) {
+		return errInvalidSign
+	}
+
+	return nil
+}
@@ -5,15 +5,19 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/service/signing"
 )
 
 func TestSignChecking(t *testing.T) {
 	type args struct {
+		keys        signing.KeySet
 		method      string
-		key         string
+		keyID       string
 		headerName  string
 		headerValue string
 		body        []byte
@@ -29,7 +33,7 @@ func TestSignChecking(t *testing.T) {
 		{
 			name: "successfully case (GET request)",
 			args: args{
-				key:         "secret key",
+				keys:        signing.KeySet{"": []byte("secret key")},
 				method:      "GET",
 				headerName:  "HashSHA256",
 				headerValue: "3fokgzYfs1ICaJVHrp2rNKo03KSMs8uGEfaYL9+AiKA=",
@@ -42,7 +46,7 @@ func TestSignChecking(t *testing.T) {
 		{
 			name: "Request without sum in header",
 			args: args{
-				key:        "",
+				keys:       nil,
 				method:     "POST",
 				headerName: "",
 				body:       make([]byte, 0),
@@ -54,7 +58,7 @@ func TestSignChecking(t *testing.T) {
 		{
 			name: "Request without body invalid sum",
 			args: args{
-				key:         "",
+				keys:        nil,
 				method:      "POST",
 				headerName:  "HashSHA256",
 				headerValue: "qwerty",
@@ -67,8 +71,25 @@ func TestSignChecking(t *testing.T) {
 		{
 			name: "successfully case (POST request)",
 			args: args{
-				key:         "secret key",
+				keys:        signing.KeySet{"": []byte("secret key")},
+				method:      "POST",
+				headerName:  "HashSHA256",
+				headerValue: "LtF60KMiLpS1xdCaUmFOtdvGucz6Y/T+MNI3vtciKHQ=",
+				body:        []byte("request body"),
+			},
+			want: want{
+				statusCode: http.StatusOK,
+			},
+		},
+		{
+			name: "successfully case (rotated key)",
+			args: args{
+				keys: signing.KeySet{
+					"2024-04": []byte("old secret"),
+					"2024-05": []byte("secret key"),
+				},
 				method:      "POST",
+				keyID:       "2024-05",
 				headerName:  "HashSHA256",
 				headerValue: "LtF60KMiLpS1xdCaUmFOtdvGucz6Y/T+MNI3vtciKHQ=",
 				body:        []byte("request body"),
@@ -77,13 +98,64 @@ func TestSignChecking(t *testing.T) {
 				statusCode: http.StatusOK,
 			},
 		},
+		{
+			name: "Sum made with other key",
+			args: args{
+				keys: signing.KeySet{
+					"2024-04": []byte("old secret"),
+					"2024-05": []byte("secret key"),
+				},
+				method:      "POST",
+				keyID:       "2024-04",
+				headerName:  "HashSHA256",
+				headerValue: "LtF60KMiLpS1xdCaUmFOtdvGucz6Y/T+MNI3vtciKHQ=",
+				body:        []byte("request body"),
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Unknown key",
+			args: args{
+				keys: signing.KeySet{
+					"2024-04": []byte("old secret"),
+					"2024-05": []byte("secret key"),
+				},
+				method:      "POST",
+				keyID:       "2024-03",
+				headerName:  "HashSHA256",
+				headerValue: "LtF60KMiLpS1xdCaUmFOtdvGucz6Y/T+MNI3vtciKHQ=",
+				body:        []byte("request body"),
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Request without key id",
+			args: args{
+				keys: signing.KeySet{
+					"2024-04": []byte("old secret"),
+					"2024-05": []byte("secret key"),
+				},
+				method:      "POST",
+				keyID:       "",
+				headerName:  "HashSHA256",
+				headerValue: "LtF60KMiLpS1xdCaUmFOtdvGucz6Y/T+MNI3vtciKHQ=",
+				body:        []byte("request body"),
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			recorder := httptest.NewRecorder()
 
 			r := chi.NewRouter()
-			r.Use(SignChecking(test.args.key))
+			r.Use(SignChecking(test.args.keys, 0))
 			r.Get("/", func(w http.ResponseWriter, r *http.Request) {})
 			r.Post("/", func(w http.ResponseWriter, r *http.Request) {})
 
@@ -91,6 +163,95 @@ func TestSignChecking(t *testing.T) {
 			if len(test.args.headerName) > 0 {
 				request.Header.Set(test.args.headerName, test.args.headerValue)
 			}
+			if len(test.args.keyID) > 0 {
+				request.Header.Set(signing.KeyIDHeader, test.args.keyID)
+			}
+
+			r.ServeHTTP(recorder, request)
+			response := recorder.Result()
+
+			require.Equal(t, test.want.statusCode, response.StatusCode)
+
+			response.Body.Close()
+		})
+	}
+}
+
+func TestSignChecking_replayProtection(t *testing.T) {
+	keys := signing.KeySet{"": []byte("secret key")}
+	body := []byte("request body")
+	now := time.Now()
+
+	type args struct {
+		timestamp string
+		nonce     string
+	}
+	type want struct {
+		statusCode int
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "successfully case",
+			args: args{timestamp: signing.FormatTimestamp(now), nonce: "nonce1"},
+			want: want{statusCode: http.StatusOK},
+		},
+		{
+			name: "replayed request",
+			args: args{timestamp: signing.FormatTimestamp(now), nonce: "nonce1"},
+			want: want{statusCode: http.StatusBadRequest},
+		},
+		{
+			name: "request with new nonce",
+			args: args{timestamp: signing.FormatTimestamp(now), nonce: "nonce2"},
+			want: want{statusCode: http.StatusOK},
+		},
+		{
+			name: "request without timestamp and nonce",
+			args: args{},
+			want: want{statusCode: http.StatusBadRequest},
+		},
+		{
+			name: "request without nonce",
+			args: args{timestamp: signing.FormatTimestamp(now)},
+			want: want{statusCode: http.StatusBadRequest},
+		},
+		{
+			name: "too old request",
+			args: args{timestamp: signing.FormatTimestamp(now.Add(-2 * time.Minute)), nonce: "nonce3"},
+			want: want{statusCode: http.StatusBadRequest},
+		},
+		{
+			name: "request from future",
+			args: args{timestamp: signing.FormatTimestamp(now.Add(2 * time.Minute)), nonce: "nonce4"},
+			want: want{statusCode: http.StatusBadRequest},
+		},
+		{
+			name: "invalid timestamp",
+			args: args{timestamp: "yesterday", nonce: "nonce5"},
+			want: want{statusCode: http.StatusBadRequest},
+		},
+	}
+
+	r := chi.NewRouter()
+	r.Use(SignChecking(keys, time.Minute))
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			request := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+			payload := body
+			if len(test.args.timestamp) > 0 || len(test.args.nonce) > 0 {
+				request.Header.Set(signing.TimestampHeader, test.args.timestamp)
+				request.Header.Set(signing.NonceHeader, test.args.nonce)
+				payload = signing.SignedPayload(test.args.timestamp, test.args.nonce, body)
+			}
+			request.Header.Set(signing.HashHeader, signing.Sum(keys[""], payload))
 
 			r.ServeHTTP(recorder, request)
 			response := recorder.Result()
@@ -1,21 +1,68 @@
 package middleware
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
+	"bytes"
 	"net/http"
+
+	"github.com/e1m0re/grdn/internal/service/identity"
+	"github.com/e1m0re/grdn/internal/service/signing"
 )
 
-// SignResponse signs server responses.
-func SignResponse(key string) func(next http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		h := hmac.New(sha256.New, []byte(key))
+// bufferedResponseWriter keeps the response in memory until it is signed.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	body   bytes.Buffer
+	status int
+}
+
+func (bw *bufferedResponseWriter) WriteHeader(code int) {
+	if bw.status == 0 {
+		bw.status = code
+	}
+}
+
+func (bw *bufferedResponseWriter) Write(b []byte) (int, error) {
+	if bw.status == 0 {
+		bw.status = http.StatusOK
+	}
+
+	return bw.body.Write(b)
+}
 
+// SignResponse signs server responses with the key of the authenticated agent (see AgentAuth) if it exists or the key
+// selected by the HashSHA256-KeyID header of the request (the legacy key if the header is missing). Responses are not
+// signed if the key is unknown.
+//
+// The response is buffered and HMAC-SHA256 sum of its body is set to the HashSHA256 header before the body is sent.
+// The body is signed before content encoding applied by outer middlewares.
+func SignResponse(keys signing.KeySet) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			h.Write([]byte(r.URL.Path))
-			w.Header().Set("HashSHA256", base64.StdEncoding.EncodeToString(h.Sum(nil)))
-			next.ServeHTTP(w, r)
+			keyID := identity.AgentIDFromContext(r.Context())
+			if _, ok := keys[keyID]; !ok || keyID == "" {
+				// Agents identified by client certificates may sign with shared keys.
+				keyID = r.Header.Get(signing.KeyIDHeader)
+			}
+			if _, ok := keys[keyID]; !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			bw := &bufferedResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(bw, r)
+
+			body := bw.body.Bytes()
+			sum, _ := keys.Sum(keyID, body)
+			w.Header().Set(signing.HashHeader, sum)
+			if keyID != "" {
+				w.Header().Set(signing.KeyIDHeader, keyID)
+			}
+
+			if bw.status == 0 {
+				bw.status = http.StatusOK
+			}
+			w.WriteHeader(bw.status)
+			_, _ = w.Write(body)
 		})
 	}
 }
@@ -2,22 +2,32 @@ package middleware
 
 import (
 	"bytes"
+	"compress/gzip"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/service/identity"
+	"github.com/e1m0re/grdn/internal/service/signing"
 )
 
 func TestSignResponse(t *testing.T) {
 	type args struct {
-		key string
+		keys       signing.KeySet
+		keyID      string
+		body       string
+		statusCode int
 	}
 	type want struct {
-		headerName    string
-		headerContent string
-		statusCode    int
+		sum        string
+		keyID      string
+		statusCode int
 	}
 	tests := []struct {
 		name string
@@ -27,33 +37,170 @@ func TestSignResponse(t *testing.T) {
 		{
 			name: "successfully case",
 			args: args{
-				key: "secret key",
+				keys:       signing.KeySet{"": []byte("secret key")},
+				statusCode: http.StatusOK,
+				body:       "response body",
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				sum:        signing.Sum([]byte("secret key"), []byte("response body")),
+			},
+		},
+		{
+			name: "empty body",
+			args: args{
+				keys: signing.KeySet{"": []byte("secret key")},
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				sum:        signing.Sum([]byte("secret key"), nil),
+			},
+		},
+		{
+			name: "error response",
+			args: args{
+				keys:       signing.KeySet{"": []byte("secret key")},
+				statusCode: http.StatusNotFound,
+				body:       "Not found.",
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				sum:        signing.Sum([]byte("secret key"), []byte("Not found.")),
+			},
+		},
+		{
+			name: "rotated key",
+			args: args{
+				keys: signing.KeySet{
+					"2024-04": []byte("old secret"),
+					"2024-05": []byte("secret key"),
+				},
+				keyID: "2024-05",
+				body:  "response body",
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				sum:        signing.Sum([]byte("secret key"), []byte("response body")),
+				keyID:      "2024-05",
+			},
+		},
+		{
+			name: "unknown key",
+			args: args{
+				keys:  signing.KeySet{"2024-05": []byte("secret key")},
+				keyID: "2024-03",
+				body:  "response body",
 			},
 			want: want{
-				statusCode:    200,
-				headerName:    "HashSHA256",
-				headerContent: "I5/FHTlJaYQFYx9mBuu5XcBOf8aVGxxUGK9GHnV4dZo=",
+				statusCode: http.StatusOK,
 			},
 		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-
-			recorder := httptest.NewRecorder()
-
 			r := chi.NewRouter()
-			r.Use(SignResponse(test.args.key))
-			r.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+			r.Use(SignResponse(test.args.keys))
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				if test.args.statusCode != 0 {
+					w.WriteHeader(test.args.statusCode)
+				}
+				_, _ = w.Write([]byte(test.args.body))
+			})
 
-			request := httptest.NewRequest("GET", "/", bytes.NewReader([]byte{}))
+			// The signature must not depend on previous requests.
+			for i := 0; i < 2; i++ {
+				recorder := httptest.NewRecorder()
+				request := httptest.NewRequest("GET", "/", bytes.NewReader([]byte{}))
+				if len(test.args.keyID) > 0 {
+					request.Header.Set(signing.KeyIDHeader, test.args.keyID)
+				}
 
-			r.ServeHTTP(recorder, request)
-			response := recorder.Result()
+				r.ServeHTTP(recorder, request)
+				response := recorder.Result()
 
-			require.Equal(t, test.want.statusCode, response.StatusCode)
-			require.Equal(t, test.want.headerContent, response.Header.Get(test.want.headerName))
+				require.Equal(t, test.want.statusCode, response.StatusCode)
+				assert.Equal(t, test.want.sum, response.Header.Get(signing.HashHeader))
+				assert.Equal(t, test.want.keyID, response.Header.Get(signing.KeyIDHeader))
+				assert.Equal(t, test.args.body, recorder.Body.String())
 
-			response.Body.Close()
+				response.Body.Close()
+			}
 		})
 	}
 }
+
+func TestSignResponse_compressed(t *testing.T) {
+	keys := signing.KeySet{"": []byte("secret key")}
+	body := bytes.Repeat([]byte(`{"id":"PollCount","type":"counter","delta":1}`), 100)
+
+	r := chi.NewRouter()
+	r.Use(middleware.Compress(5, "application/json"))
+	r.Use(SignResponse(keys))
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	})
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+
+	r.ServeHTTP(recorder, request)
+	response := recorder.Result()
+	defer response.Body.Close()
+
+	require.Equal(t, "gzip", response.Header.Get("Content-Encoding"))
+	zr, err := gzip.NewReader(response.Body)
+	require.NoError(t, err)
+	decoded, err := io.ReadAll(zr)
+	require.NoError(t, err)
+
+	assert.Equal(t, body, decoded)
+	assert.True(t, keys.Verify("", decoded, response.Header.Get(signing.HashHeader)))
+}
+
+func TestSignResponse_agent(t *testing.T) {
+	keys := signing.KeySet{"web-1": []byte("web secret")}
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(identity.WithAgentID(r.Context(), "web-1")))
+		})
+	})
+	r.Use(SignResponse(keys))
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("response body"))
+	})
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("POST", "/", nil))
+	response := recorder.Result()
+	defer response.Body.Close()
+
+	assert.Equal(t, signing.Sum([]byte("web secret"), []byte("response body")), response.Header.Get(signing.HashHeader))
+	assert.Equal(t, "web-1", response.Header.Get(signing.KeyIDHeader))
+}
+
+func TestSignResponse_agentWithSharedKey(t *testing.T) {
+	keys := signing.KeySet{"": []byte("shared secret")}
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(identity.WithAgentID(r.Context(), "web-1")))
+		})
+	})
+	r.Use(SignResponse(keys))
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("response body"))
+	})
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("POST", "/", nil))
+	response := recorder.Result()
+	defer response.Body.Close()
+
+	assert.Equal(t, signing.Sum([]byte("shared secret"), []byte("response body")), response.Header.Get(signing.HashHeader))
+	assert.Empty(t, response.Header.Get(signing.KeyIDHeader))
+}
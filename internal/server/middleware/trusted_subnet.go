@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+)
+
+// RealIPHeader is the name of the header containing IP address of the agent outbound interface.
+const RealIPHeader = "X-Real-IP"
+
+// TrustedSubnet rejects requests which come from addresses outside the subnet with 403 status. Both the address
+// passed by the agent in the X-Real-IP header and the remote address of the connection must belong to the subnet.
+// GET requests are checked only if checkReads is set.
+func TrustedSubnet(subnet *net.IPNet, checkReads bool) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "GET" && !checkReads {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			realIP := r.Header.Get(RealIPHeader)
+			if !inSubnet(subnet, realIP) || !inSubnet(subnet, remoteIP(r)) {
+				slog.Warn("request from untrusted address is rejected",
+					slog.String("real_ip", realIP),
+					slog.String("remote_addr", r.RemoteAddr),
+				)
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// TrustedRemoteSubnet rejects requests whose connection comes from addresses outside the subnet with 403 status. It
+// protects endpoints of third-party protocols: their clients don't pass the X-Real-IP header, so only the remote
+// address is checked like StatsD and Graphite listeners do.
+func TrustedRemoteSubnet(subnet *net.IPNet) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !inSubnet(subnet, remoteIP(r)) {
+				slog.Warn("request from untrusted address is rejected", slog.String("remote_addr", r.RemoteAddr))
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// remoteIP returns IP address of the request connection without port.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func inSubnet(subnet *net.IPNet, address string) bool {
+	ip := net.ParseIP(address)
+
+	return ip != nil && subnet.Contains(ip)
+}
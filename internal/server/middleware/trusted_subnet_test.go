@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedSubnet(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.0.0/24")
+	require.NoError(t, err)
+
+	type args struct {
+		method     string
+		realIP     string
+		remoteAddr string
+		checkReads bool
+	}
+	tests := []struct {
+		name string
+		args args
+		want int
+	}{
+		{
+			name: "POST from trusted subnet",
+			args: args{method: http.MethodPost, realIP: "10.0.0.15", remoteAddr: "10.0.0.15:51234"},
+			want: http.StatusOK,
+		},
+		{
+			name: "POST without X-Real-IP",
+			args: args{method: http.MethodPost, remoteAddr: "10.0.0.15:51234"},
+			want: http.StatusForbidden,
+		},
+		{
+			name: "POST with invalid X-Real-IP",
+			args: args{method: http.MethodPost, realIP: "agent", remoteAddr: "10.0.0.15:51234"},
+			want: http.StatusForbidden,
+		},
+		{
+			name: "POST with X-Real-IP outside subnet",
+			args: args{method: http.MethodPost, realIP: "192.168.1.15", remoteAddr: "10.0.0.15:51234"},
+			want: http.StatusForbidden,
+		},
+		{
+			name: "POST with spoofed X-Real-IP",
+			args: args{method: http.MethodPost, realIP: "10.0.0.15", remoteAddr: "192.168.1.15:51234"},
+			want: http.StatusForbidden,
+		},
+		{
+			name: "GET from any address",
+			args: args{method: http.MethodGet, remoteAddr: "192.168.1.15:51234"},
+			want: http.StatusOK,
+		},
+		{
+			name: "GET from untrusted address with checked reads",
+			args: args{method: http.MethodGet, remoteAddr: "192.168.1.15:51234", checkReads: true},
+			want: http.StatusForbidden,
+		},
+		{
+			name: "GET from trusted subnet with checked reads",
+			args: args{method: http.MethodGet, realIP: "10.0.0.15", remoteAddr: "10.0.0.15:51234", checkReads: true},
+			want: http.StatusOK,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Use(TrustedSubnet(subnet, test.args.checkReads))
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+			r.Post("/", func(w http.ResponseWriter, r *http.Request) {})
+
+			request := httptest.NewRequest(test.args.method, "/", nil)
+			request.RemoteAddr = test.args.remoteAddr
+			if len(test.args.realIP) > 0 {
+				request.Header.Set(RealIPHeader, test.args.realIP)
+			}
+
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, request)
+			response := recorder.Result()
+			defer response.Body.Close()
+
+			assert.Equal(t, test.want, response.StatusCode)
+		})
+	}
+}
+
+func TestTrustedRemoteSubnet(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.0.0/24")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		want       int
+	}{
+		{
+			name:       "POST from trusted subnet without X-Real-IP",
+			remoteAddr: "10.0.0.15:51234",
+			want:       http.StatusOK,
+		},
+		{
+			name:       "POST from untrusted address",
+			remoteAddr: "192.168.1.15:51234",
+			want:       http.StatusForbidden,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Use(TrustedRemoteSubnet(subnet))
+			r.Post("/", func(w http.ResponseWriter, r *http.Request) {})
+
+			request := httptest.NewRequest(http.MethodPost, "/", nil)
+			request.RemoteAddr = test.remoteAddr
+
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, request)
+			response := recorder.Result()
+			defer response.Body.Close()
+
+			assert.Equal(t, test.want, response.StatusCode)
+		})
+	}
+}
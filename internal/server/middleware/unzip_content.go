@@ -6,8 +6,9 @@ import (
 	"strings"
 )
 
-// UnzipContent extracts requests body.
-func UnzipContent() func(next http.Handler) http.Handler {
+// UnzipContent extracts requests body. The extracted body is limited to maxSize bytes, so small compressed body
+// can't be expanded to arbitrary amount of data.
+func UnzipContent(maxSize int64) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(
 			func(w http.ResponseWriter, r *http.Request) {
@@ -19,7 +20,7 @@ func UnzipContent() func(next http.Handler) http.Handler {
 						w.WriteHeader(http.StatusInternalServerError)
 						return
 					}
-					r.Body = gr
+					r.Body = http.MaxBytesReader(w, gr, maxSize)
 				}
 
 				next.ServeHTTP(w, r)
@@ -38,6 +38,17 @@ func TestUnzipContent(t *testing.T) {
 				statusCode: 200,
 			},
 		},
+		{
+			name: "Extracted body exceeds limit",
+			args: args{
+				body:           bytes.Repeat([]byte("a"), 1024),
+				compressedBody: true,
+			},
+			want: want{
+				body:       []byte("request body too large\n"),
+				statusCode: http.StatusRequestEntityTooLarge,
+			},
+		},
 		{
 			name: "successfully case (with compress)",
 			args: args{
@@ -55,9 +66,13 @@ func TestUnzipContent(t *testing.T) {
 			recorder := httptest.NewRecorder()
 
 			r := chi.NewRouter()
-			r.Use(UnzipContent())
+			r.Use(UnzipContent(64))
 			r.Post("/", func(w http.ResponseWriter, r *http.Request) {
 				b, err := io.ReadAll(r.Body)
+				if IsBodyTooLarge(err) {
+					http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+					return
+				}
 				if err != nil {
 					panic(err)
 				}
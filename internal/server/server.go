@@ -2,16 +2,24 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
 
 	appHandler "github.com/e1m0re/grdn/internal/api"
+	"github.com/e1m0re/grdn/internal/api/graphite"
+	"github.com/e1m0re/grdn/internal/api/statsd"
 	"github.com/e1m0re/grdn/internal/server/config"
 	"github.com/e1m0re/grdn/internal/service"
+	"github.com/e1m0re/grdn/internal/service/certs"
+	"github.com/e1m0re/grdn/internal/service/ingest"
+	"github.com/e1m0re/grdn/internal/service/signing"
 	"github.com/e1m0re/grdn/internal/storage/store"
 )
 
@@ -25,6 +33,12 @@ type srv struct {
 	cfg        *config.Config
 	httpServer *http.Server
 	services   *service.ServerServices
+	// grpcServer is nil if gRPC server is disabled.
+	grpcServer *grpc.Server
+	// statsdServer is nil if StatsD listener is disabled.
+	statsdServer *statsd.Server
+	// graphiteServer is nil if Graphite listener is disabled.
+	graphiteServer *graphite.Server
 }
 
 // Start runs server.
@@ -35,6 +49,40 @@ func (srv *srv) Start(ctx context.Context) error {
 		return srv.startHTTPServer()
 	})
 
+	if srv.grpcServer != nil {
+		grp.Go(func() error {
+			return srv.startGRPCServer()
+		})
+	}
+
+	if srv.statsdServer != nil {
+		grp.Go(func() error {
+			return srv.startStatsDServer(ctx)
+		})
+	}
+
+	if srv.graphiteServer != nil {
+		grp.Go(func() error {
+			return srv.startGraphiteServer(ctx)
+		})
+	}
+
+	if srv.services.IngestBatcher != nil {
+		grp.Go(func() error {
+			return srv.services.IngestBatcher.Start(ctx)
+		})
+	}
+
+	if srv.services.AlertsNotifier != nil {
+		grp.Go(func() error {
+			return srv.services.AlertsNotifier.Start(ctx)
+		})
+	}
+
+	grp.Go(func() error {
+		return srv.services.AlertsManager.Start(ctx)
+	})
+
 	grp.Go(func() error {
 		<-ctx.Done()
 
@@ -46,7 +94,13 @@ func (srv *srv) Start(ctx context.Context) error {
 
 func (srv *srv) startHTTPServer() error {
 	slog.Info(fmt.Sprintf("Running server on %s", srv.cfg.ServerAddr))
-	err := srv.httpServer.ListenAndServe()
+	var err error
+	if srv.httpServer.TLSConfig != nil {
+		// Certificates are already loaded into TLS config.
+		err = srv.httpServer.ListenAndServeTLS("", "")
+	} else {
+		err = srv.httpServer.ListenAndServe()
+	}
 	if err != nil && errors.Is(err, http.ErrServerClosed) {
 		return nil
 	}
@@ -54,13 +108,55 @@ func (srv *srv) startHTTPServer() error {
 	return err
 }
 
+func (srv *srv) startGRPCServer() error {
+	slog.Info(fmt.Sprintf("Running gRPC server on %s", srv.cfg.GRPCAddr))
+	listener, err := net.Listen("tcp", srv.cfg.GRPCAddr)
+	if err != nil {
+		return err
+	}
+
+	return srv.grpcServer.Serve(listener)
+}
+
+func (srv *srv) startStatsDServer(ctx context.Context) error {
+	slog.Info(fmt.Sprintf("Running StatsD listener on %s", srv.cfg.StatsDAddr))
+	conn, err := net.ListenPacket("udp", srv.cfg.StatsDAddr)
+	if err != nil {
+		return err
+	}
+
+	return srv.statsdServer.Serve(ctx, conn)
+}
+
+func (srv *srv) startGraphiteServer(ctx context.Context) error {
+	slog.Info(fmt.Sprintf("Running Graphite listener on %s", srv.cfg.GraphiteAddr))
+	listener, err := net.Listen("tcp", srv.cfg.GraphiteAddr)
+	if err != nil {
+		return err
+	}
+
+	return srv.graphiteServer.Serve(ctx, listener)
+}
+
 func (srv *srv) shutdown(ctx context.Context) error {
+	if srv.grpcServer != nil {
+		srv.grpcServer.GracefulStop()
+	}
+
 	err := srv.httpServer.Shutdown(ctx)
 	if err != nil {
 		slog.Error("failed to shutdown http server")
 		return err
 	}
 
+	if srv.services.IngestBatcher != nil {
+		// The context is already done, but the rest of samples must be stored before the storage is saved.
+		err = srv.services.IngestBatcher.Flush(context.WithoutCancel(ctx))
+		if err != nil {
+			slog.Error("failed to flush ingested samples", slog.String("error", err.Error()))
+		}
+	}
+
 	err = srv.services.StorageService.Save(ctx)
 	if err != nil {
 		slog.Error(err.Error())
@@ -79,16 +175,96 @@ func (srv *srv) shutdown(ctx context.Context) error {
 }
 
 // NewServer is srv constructor.
-func NewServer(cfg *config.Config, s store.Store) Server {
-	services := service.NewServerServices(s)
-	handler := appHandler.NewHandler(services)
+func NewServer(cfg *config.Config, s store.Store) (Server, error) {
+	services, err := service.NewServerServices(cfg, s)
+	if err != nil {
+		return nil, err
+	}
+
+	signKeys, err := loadSignKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var trustedSubnet *net.IPNet
+	if len(cfg.TrustedSubnet) > 0 {
+		_, trustedSubnet, err = net.ParseCIDR(cfg.TrustedSubnet)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted subnet: %w", err)
+		}
+	}
+
+	var tlsConfig *tls.Config
+	if len(cfg.TLSCertFile) > 0 || len(cfg.TLSKeyFile) > 0 || len(cfg.TLSClientCAFile) > 0 {
+		tlsConfig, err = certs.ServerConfig(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS config: %w", err)
+		}
+	}
+
+	var grpcServer *grpc.Server
+	if len(cfg.GRPCAddr) > 0 {
+		grpcServer = newGRPCServer(services, signKeys, cfg.SignMaxSkew, trustedSubnet, tlsConfig)
+	}
+
+	var statsdServer *statsd.Server
+	if len(cfg.StatsDAddr) > 0 {
+		statsdServer = statsd.NewServer(services.IngestBatcher, trustedSubnet)
+	}
+
+	rule := ingest.TypeRule{CounterSuffix: cfg.CounterSuffix, CounterFields: cfg.CounterFields}
+	var graphiteServer *graphite.Server
+	if len(cfg.GraphiteAddr) > 0 {
+		graphiteServer = graphite.NewServer(services.IngestBatcher, rule, trustedSubnet)
+	}
+
+	var ingestOptions *appHandler.IngestOptions
+	if cfg.Influx || cfg.PrometheusWrite || cfg.OTLP {
+		ingestOptions = &appHandler.IngestOptions{
+			Rule:            rule,
+			Influx:          cfg.Influx,
+			PrometheusWrite: cfg.PrometheusWrite,
+			OTLP:            cfg.OTLP,
+			Insecure:        cfg.IngestInsecure,
+		}
+	}
+
+	handler := appHandler.NewHandler(services, cfg.StaleThreshold, ingestOptions)
 
 	return &srv{
 		cfg: cfg,
 		httpServer: &http.Server{
-			Addr:    cfg.ServerAddr,
-			Handler: handler.NewRouter(cfg.Key, cfg.PrivateKeyFile),
+			Addr:      cfg.ServerAddr,
+			Handler:   handler.NewRouter(signKeys, cfg.SignMaxSkew, cfg.PrivateKeyFile, trustedSubnet, cfg.TrustedSubnetReads),
+			TLSConfig: tlsConfig,
 		},
-		services: services,
+		services:       services,
+		grpcServer:     grpcServer,
+		statsdServer:   statsdServer,
+		graphiteServer: graphiteServer,
+	}, nil
+}
+
+// loadSignKeys returns set of active sign keys: the legacy key (with empty ID) and rotated keys from env and file.
+func loadSignKeys(cfg *config.Config) (signing.KeySet, error) {
+	keys := make(signing.KeySet)
+	if len(cfg.Key) > 0 {
+		keys[""] = []byte(cfg.Key)
+	}
+
+	rotated, err := signing.ParseKeys(cfg.SignKeys)
+	if err != nil {
+		return nil, err
 	}
+	keys.Add(rotated)
+
+	if len(cfg.SignKeysFile) > 0 {
+		rotated, err = signing.LoadKeys(cfg.SignKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		keys.Add(rotated)
+	}
+
+	return keys, nil
 }
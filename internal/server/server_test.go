@@ -1,17 +1,100 @@
 package server
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/e1m0re/grdn/internal/server/config"
+	"github.com/e1m0re/grdn/internal/service/signing"
 	"github.com/e1m0re/grdn/internal/storage/store/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewServer(t *testing.T) {
 	cfg := config.Config{}
 	s := mocks.NewStore(t)
 
-	srv := NewServer(&cfg, s)
+	srv, err := NewServer(&cfg, s)
+	require.NoError(t, err)
 	assert.Implements(t, (*Server)(nil), srv)
+
+	cfg.AlertRulesFile = "/tmp/TestNewServer_unknown_rules_file"
+	srv, err = NewServer(&cfg, s)
+	require.Error(t, err)
+	assert.Nil(t, srv)
+
+	cfg = config.Config{AgentsFile: "/tmp/TestNewServer_unknown_agents_file"}
+	srv, err = NewServer(&cfg, s)
+	require.Error(t, err)
+	assert.Nil(t, srv)
+
+	cfg = config.Config{TrustedSubnet: "10.0.0.0/33"}
+	srv, err = NewServer(&cfg, s)
+	require.Error(t, err)
+	assert.Nil(t, srv)
+
+	cfg = config.Config{TLSClientCAFile: "/tmp/TestNewServer_unknown_ca_file"}
+	srv, err = NewServer(&cfg, s)
+	require.Error(t, err)
+	assert.Nil(t, srv)
+}
+
+func Test_loadSignKeys(t *testing.T) {
+	keysFile := filepath.Join(t.TempDir(), "sign_keys.json")
+	err := os.WriteFile(keysFile, []byte(`{"2024-05": "new secret", "2024-06": "next secret"}`), 0600)
+	require.NoError(t, err)
+
+	type want struct {
+		keys     signing.KeySet
+		hasError bool
+	}
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want want
+	}{
+		{
+			name: "signing disabled",
+			cfg:  &config.Config{},
+			want: want{keys: signing.KeySet{}},
+		},
+		{
+			name: "legacy key",
+			cfg:  &config.Config{Key: "secret key"},
+			want: want{keys: signing.KeySet{"": []byte("secret key")}},
+		},
+		{
+			name: "rotated keys from env and file",
+			cfg: &config.Config{
+				Key:          "secret key",
+				SignKeys:     "2024-04:old secret,2024-05:replaced secret",
+				SignKeysFile: keysFile,
+			},
+			want: want{keys: signing.KeySet{
+				"":        []byte("secret key"),
+				"2024-04": []byte("old secret"),
+				"2024-05": []byte("new secret"),
+				"2024-06": []byte("next secret"),
+			}},
+		},
+		{
+			name: "invalid keys",
+			cfg:  &config.Config{SignKeys: "secret"},
+			want: want{hasError: true},
+		},
+		{
+			name: "keys file not found",
+			cfg:  &config.Config{SignKeysFile: keysFile + ".missing"},
+			want: want{hasError: true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys, err := loadSignKeys(test.cfg)
+			assert.Equal(t, test.want.hasError, err != nil)
+			assert.Equal(t, test.want.keys, keys)
+		})
+	}
 }
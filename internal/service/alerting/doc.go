@@ -0,0 +1,2 @@
+// Package alerting implements checking of alerting rules against metrics values.
+package alerting
@@ -0,0 +1,201 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service/metrics"
+)
+
+// Manager is the interface that contains all operations for alerting.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.43.1 --name=Manager
+type Manager interface {
+	// Evaluate checks all rules against current metrics values and updates alerts states.
+	Evaluate(ctx context.Context) error
+
+	// GetAlerts returns states of all rules.
+	GetAlerts() []models.Alert
+
+	// Start evaluates rules on the fixed interval until the context is done.
+	Start(ctx context.Context) error
+}
+
+type alertsManager struct {
+	metricsManager metrics.Manager
+	notifier       Notifier
+	rules          []Rule
+	alerts         []models.Alert
+	interval       time.Duration
+	reportInterval time.Duration
+	mx             sync.RWMutex
+}
+
+// NewManager returns new instance of alerts manager. The reportInterval is the expected interval of agents reports
+// used by silent rules. Notifier is optional and may be nil.
+func NewManager(metricsManager metrics.Manager, rules []Rule, interval, reportInterval time.Duration, notifier Notifier) Manager {
+	alerts := make([]models.Alert, len(rules))
+	for i, rule := range rules {
+		alerts[i] = models.Alert{
+			Rule:  rule.Expr,
+			State: models.AlertStateInactive,
+		}
+	}
+
+	return &alertsManager{
+		metricsManager: metricsManager,
+		notifier:       notifier,
+		rules:          rules,
+		alerts:         alerts,
+		interval:       interval,
+		reportInterval: reportInterval,
+	}
+}
+
+// Evaluate checks all rules against current metrics values and updates alerts states.
+func (am *alertsManager) Evaluate(ctx context.Context) error {
+	return am.evaluate(ctx, time.Now())
+}
+
+func (am *alertsManager) evaluate(ctx context.Context, now time.Time) error {
+	metrics, err := am.metricsManager.GetAllMetrics(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	for i, rule := range am.rules {
+		value, labels := am.seriesValue(rule, rule.series(*metrics), now)
+
+		am.mx.Lock()
+		prevState := am.alerts[i].State
+		alert := nextState(am.alerts[i], rule, value, now)
+		if value != nil {
+			alert.Labels = labels
+		}
+		am.alerts[i] = alert
+		am.mx.Unlock()
+
+		if am.notifier == nil || alert.State == prevState {
+			continue
+		}
+
+		if alert.State == models.AlertStateFiring || alert.State == models.AlertStateResolved {
+			err = am.notifier.Notify(ctx, alert)
+			if err != nil {
+				errs = errors.Join(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+// seriesValue returns the value checked by the rule and labels of the series it belongs to. The rule is met if any of
+// the series meets its condition, the value of the first such series is returned then. Otherwise, the value of the
+// first series is returned. Returns nil if there are no series.
+func (am *alertsManager) seriesValue(rule Rule, series models.MetricsList, now time.Time) (*float64, models.Labels) {
+	var value *float64
+	var labels models.Labels
+	for _, metric := range series {
+		v := am.ruleValue(rule, metric, now)
+		if v == nil {
+			continue
+		}
+		if rule.Check(*v) {
+			return v, metric.Labels
+		}
+		if value == nil {
+			value, labels = v, metric.Labels
+		}
+	}
+
+	return value, labels
+}
+
+// ruleValue returns the value checked by the rule: the metric value or the number of report intervals elapsed since
+// the last update of the metric for silent rules. Returns nil if the metric is missing.
+func (am *alertsManager) ruleValue(rule Rule, metric *models.Metric, now time.Time) *float64 {
+	if metric == nil {
+		return nil
+	}
+
+	value := metric.FloatValue()
+	if rule.Operator == SilentOperator {
+		lastSeen := metric.LastSeenTime()
+		if lastSeen == nil || am.reportInterval <= 0 {
+			return nil
+		}
+
+		value = float64(now.Sub(*lastSeen)) / float64(am.reportInterval)
+	}
+
+	return &value
+}
+
+// nextState returns new state of the alert depending on the current value. Missing value doesn't meet any rule.
+func nextState(alert models.Alert, rule Rule, value *float64, now time.Time) models.Alert {
+	active := false
+	if value != nil {
+		alert.Value = value
+		active = rule.Check(*value)
+	}
+
+	switch {
+	case active && (alert.State == models.AlertStateInactive || alert.State == models.AlertStateResolved):
+		alert.ActiveAt = &now
+		alert.FiredAt = nil
+		alert.ResolvedAt = nil
+		alert.State = models.AlertStatePending
+		if rule.For == 0 {
+			alert.FiredAt = &now
+			alert.State = models.AlertStateFiring
+		}
+	case active && alert.State == models.AlertStatePending && now.Sub(*alert.ActiveAt) >= rule.For:
+		alert.FiredAt = &now
+		alert.State = models.AlertStateFiring
+	case !active && alert.State == models.AlertStatePending:
+		alert.ActiveAt = nil
+		alert.State = models.AlertStateInactive
+	case !active && alert.State == models.AlertStateFiring:
+		alert.ResolvedAt = &now
+		alert.State = models.AlertStateResolved
+	}
+
+	return alert
+}
+
+// GetAlerts returns states of all rules.
+func (am *alertsManager) GetAlerts() []models.Alert {
+	am.mx.RLock()
+	defer am.mx.RUnlock()
+
+	result := make([]models.Alert, len(am.alerts))
+	copy(result, am.alerts)
+
+	return result
+}
+
+// Start evaluates rules on the fixed interval until the context is done.
+func (am *alertsManager) Start(ctx context.Context) error {
+	if len(am.rules) == 0 {
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("[alerting.Start] Stopping active job")
+			return nil
+		case <-time.After(am.interval):
+			err := am.Evaluate(ctx)
+			if err != nil {
+				slog.Error("[alerting.Start] rules evaluation failed", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/models"
+	alertingMocks "github.com/e1m0re/grdn/internal/service/alerting/mocks"
+	"github.com/e1m0re/grdn/internal/service/metrics/mocks"
+)
+
+func Test_alertsManager_evaluate(t *testing.T) {
+	start := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+	high := float64(6e8)
+	low := float64(1e8)
+	gauge := func(v *float64) *models.Metric {
+		return &models.Metric{Value: v, MType: models.GaugeType, ID: "HeapAlloc"}
+	}
+	rule, err := ParseRule("gauge HeapAlloc > 5e8 for 2m")
+	require.NoError(t, err)
+
+	steps := []struct {
+		err    error
+		metric *models.Metric
+		state  models.AlertState
+		offset time.Duration
+	}{
+		{metric: nil, offset: 0, state: models.AlertStateInactive},
+		{metric: gauge(&high), offset: time.Minute, state: models.AlertStatePending},
+		{metric: gauge(&low), offset: 2 * time.Minute, state: models.AlertStateInactive},
+		{metric: gauge(&high), offset: 3 * time.Minute, state: models.AlertStatePending},
+		{metric: gauge(&high), offset: 4 * time.Minute, state: models.AlertStatePending},
+		{err: errors.New("something wrong"), offset: 4*time.Minute + 30*time.Second, state: models.AlertStatePending},
+		{metric: gauge(&high), offset: 5 * time.Minute, state: models.AlertStateFiring},
+		{metric: gauge(&high), offset: 6 * time.Minute, state: models.AlertStateFiring},
+		{metric: gauge(&low), offset: 7 * time.Minute, state: models.AlertStateResolved},
+		{metric: gauge(&low), offset: 8 * time.Minute, state: models.AlertStateResolved},
+		{metric: gauge(&high), offset: 9 * time.Minute, state: models.AlertStatePending},
+	}
+
+	mockMetricsManager := mocks.NewManager(t)
+	am := NewManager(mockMetricsManager, []Rule{rule}, time.Second, 10*time.Second, nil).(*alertsManager)
+	for _, step := range steps {
+		var metrics *models.MetricsList
+		if step.err == nil {
+			metrics = &models.MetricsList{}
+			if step.metric != nil {
+				*metrics = append(*metrics, step.metric)
+			}
+		}
+		call := mockMetricsManager.
+			On("GetAllMetrics", mock.Anything).
+			Return(metrics, step.err).
+			Once()
+
+		err := am.evaluate(context.Background(), start.Add(step.offset))
+		if step.err != nil {
+			assert.ErrorIs(t, err, step.err)
+		} else {
+			assert.NoError(t, err)
+		}
+
+		alerts := am.GetAlerts()
+		require.Len(t, alerts, 1)
+		assert.Equal(t, step.state, alerts[0].State, "at %s", step.offset)
+		call.Unset()
+	}
+
+	alert := am.GetAlerts()[0]
+	assert.Equal(t, start.Add(9*time.Minute), *alert.ActiveAt)
+	assert.Nil(t, alert.FiredAt)
+	assert.Nil(t, alert.ResolvedAt)
+	assert.Equal(t, high, *alert.Value)
+}
+
+func Test_alertsManager_evaluateNotifications(t *testing.T) {
+	start := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+	high := float64(6e8)
+	low := float64(1e8)
+	rule, err := ParseRule("gauge HeapAlloc > 5e8")
+	require.NoError(t, err)
+
+	mockMetricsManager := mocks.NewManager(t)
+	mockNotifier := alertingMocks.NewNotifier(t)
+	mockNotifier.
+		On("Notify", mock.Anything, mock.Anything).
+		Return(nil)
+	am := NewManager(mockMetricsManager, []Rule{rule}, time.Second, 10*time.Second, mockNotifier).(*alertsManager)
+
+	values := []float64{low, high, high, low, low}
+	for i, value := range values {
+		v := value
+		call := mockMetricsManager.
+			On("GetAllMetrics", mock.Anything).
+			Return(&models.MetricsList{{Value: &v, MType: models.GaugeType, ID: "HeapAlloc"}}, nil).
+			Once()
+
+		err = am.evaluate(context.Background(), start.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, err)
+		call.Unset()
+	}
+
+	firedAt := start.Add(time.Minute)
+	resolvedAt := start.Add(3 * time.Minute)
+	mockNotifier.AssertNumberOfCalls(t, "Notify", 2)
+	mockNotifier.AssertCalled(t, "Notify", mock.Anything, models.Alert{
+		ActiveAt: &firedAt,
+		FiredAt:  &firedAt,
+		Value:    &high,
+		Rule:     "gauge HeapAlloc > 5e8",
+		State:    models.AlertStateFiring,
+	})
+	mockNotifier.AssertCalled(t, "Notify", mock.Anything, models.Alert{
+		ActiveAt:   &firedAt,
+		FiredAt:    &firedAt,
+		ResolvedAt: &resolvedAt,
+		Value:      &low,
+		Rule:       "gauge HeapAlloc > 5e8",
+		State:      models.AlertStateResolved,
+	})
+}
+
+func Test_alertsManager_evaluateLabeledSeries(t *testing.T) {
+	now := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+	gauge := func(value float64, labels models.Labels) *models.Metric {
+		return &models.Metric{Value: &value, MType: models.GaugeType, ID: "CPUutilization", Labels: labels}
+	}
+	metrics := &models.MetricsList{
+		gauge(99, models.Labels{"cpu": "0", "host": "web2"}),
+		gauge(95, models.Labels{"cpu": "1", "host": "web1"}),
+		gauge(50, models.Labels{"cpu": "0", "host": "web1"}),
+		{Value: func() *float64 { v := 100.0; return &v }(), MType: models.GaugeType, ID: "HeapAlloc"},
+	}
+	tests := []struct {
+		labels models.Labels
+		name   string
+		expr   string
+		state  models.AlertState
+		value  float64
+	}{
+		{
+			name:   "Series matching label matcher",
+			expr:   "gauge CPUutilization{host=web1} > 90",
+			state:  models.AlertStateFiring,
+			value:  95,
+			labels: models.Labels{"cpu": "1", "host": "web1"},
+		},
+		{
+			name:   "Series below threshold",
+			expr:   "gauge CPUutilization{cpu=0,host=web1} > 90",
+			state:  models.AlertStateInactive,
+			value:  50,
+			labels: models.Labels{"cpu": "0", "host": "web1"},
+		},
+		{
+			name:   "All series of the metric",
+			expr:   "gauge CPUutilization > 96",
+			state:  models.AlertStateFiring,
+			value:  99,
+			labels: models.Labels{"cpu": "0", "host": "web2"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule, err := ParseRule(test.expr)
+			require.NoError(t, err)
+
+			mockMetricsManager := mocks.NewManager(t)
+			mockMetricsManager.
+				On("GetAllMetrics", mock.Anything).
+				Return(metrics, nil)
+			am := NewManager(mockMetricsManager, []Rule{rule}, time.Second, 10*time.Second, nil).(*alertsManager)
+
+			require.NoError(t, am.evaluate(context.Background(), now))
+			alert := am.GetAlerts()[0]
+			assert.Equal(t, test.state, alert.State)
+			assert.Equal(t, test.value, *alert.Value)
+			assert.Equal(t, test.labels, alert.Labels)
+		})
+	}
+}
+
+func Test_nextState(t *testing.T) {
+	now := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+	value := float64(100)
+	rule, err := ParseRule("counter PollCount >= 100")
+	require.NoError(t, err)
+
+	got := nextState(models.Alert{Rule: rule.Expr, State: models.AlertStateInactive}, rule, &value, now)
+
+	assert.Equal(t, models.Alert{
+		ActiveAt: &now,
+		FiredAt:  &now,
+		Value:    &value,
+		Rule:     "counter PollCount >= 100",
+		State:    models.AlertStateFiring,
+	}, got)
+}
+
+func Test_alertsManager_ruleValue(t *testing.T) {
+	now := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+	updatedAt := now.Add(-35 * time.Second)
+	value := float64(100.1)
+	metric := &models.Metric{Value: &value, Timestamp: &updatedAt, MType: models.GaugeType, ID: "HeapAlloc"}
+	type args struct {
+		metric *models.Metric
+		expr   string
+	}
+	tests := []struct {
+		want *float64
+		args args
+		name string
+	}{
+		{
+			name: "Missing metric",
+			args: args{metric: nil, expr: "gauge HeapAlloc > 100"},
+			want: nil,
+		},
+		{
+			name: "Metric value",
+			args: args{metric: metric, expr: "gauge HeapAlloc > 100"},
+			want: &value,
+		},
+		{
+			name: "Silent rule",
+			args: args{metric: metric, expr: "gauge HeapAlloc silent 3"},
+			want: func() *float64 { v := 3.5; return &v }(),
+		},
+		{
+			name: "Silent rule without update time",
+			args: args{metric: &models.Metric{Value: &value, MType: models.GaugeType, ID: "HeapAlloc"}, expr: "gauge HeapAlloc silent 3"},
+			want: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule, err := ParseRule(test.args.expr)
+			require.NoError(t, err)
+
+			am := NewManager(mocks.NewManager(t), []Rule{rule}, time.Second, 10*time.Second, nil).(*alertsManager)
+			assert.Equal(t, test.want, am.ruleValue(rule, test.args.metric, now))
+		})
+	}
+}
+
+func Test_alertsManager_evaluateSilentRule(t *testing.T) {
+	start := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+	value := float64(100.1)
+	rule, err := ParseRule("gauge HeapAlloc silent 3")
+	require.NoError(t, err)
+
+	mockMetricsManager := mocks.NewManager(t)
+	mockMetricsManager.
+		On("GetAllMetrics", mock.Anything).
+		Return(&models.MetricsList{{Value: &value, Timestamp: &start, MType: models.GaugeType, ID: "HeapAlloc"}}, nil)
+
+	am := NewManager(mockMetricsManager, []Rule{rule}, time.Second, 10*time.Second, nil).(*alertsManager)
+
+	require.NoError(t, am.evaluate(context.Background(), start.Add(20*time.Second)))
+	assert.Equal(t, models.AlertStateInactive, am.GetAlerts()[0].State)
+
+	require.NoError(t, am.evaluate(context.Background(), start.Add(30*time.Second)))
+	assert.Equal(t, models.AlertStateFiring, am.GetAlerts()[0].State)
+}
+
+func Test_alertsManager_Start(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	am := NewManager(mocks.NewManager(t), []Rule{{MType: models.GaugeType, MName: "HeapAlloc"}}, time.Hour, 10*time.Second, nil)
+	assert.NoError(t, am.Start(ctx))
+}
@@ -0,0 +1,2 @@
+// Package mocks defines mocks for alerts manager.
+package mocks
@@ -0,0 +1,190 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/utils"
+)
+
+const (
+	defaultWebhookAttempts   = 3
+	defaultWebhookDelay      = time.Second
+	defaultWebhookTimeout    = 5 * time.Second
+	defaultWebhookRedelivery = time.Minute
+	defaultWebhookQueueSize  = 1000
+)
+
+// ErrQueueFull is the error returned when the notification can't be queued because the webhook is not available
+// for a long time.
+var ErrQueueFull = errors.New("notifications queue is full")
+
+// Notifier is the interface that sends notifications about alerts states changes.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.43.1 --name=Notifier
+type Notifier interface {
+	// Notify queues notification about fired or resolved alert.
+	Notify(ctx context.Context, alert models.Alert) error
+
+	// Start delivers queued notifications until the context is done.
+	Start(ctx context.Context) error
+}
+
+// notification is the signed payload of the alert.
+type notification struct {
+	sum     string
+	payload []byte
+}
+
+type webhookNotifier struct {
+	client *http.Client
+	// queues contains notifications waiting for delivery to each url in order of alerts states changes.
+	queues map[string][]notification
+	// wake signals the delivery loop about queued notifications.
+	wake       chan struct{}
+	urls       []string
+	key        []byte
+	attempts   uint
+	delay      time.Duration
+	redelivery time.Duration
+	queueSize  int
+	mx         sync.Mutex
+}
+
+// NewWebhookNotifier returns notifier which posts alerts in JSON to the specified urls.
+// If the key is not empty, the payload is signed with HMAC-SHA256 and the sum is passed in the HashSHA256 header.
+func NewWebhookNotifier(urls []string, key []byte) Notifier {
+	return &webhookNotifier{
+		client:     &http.Client{Timeout: defaultWebhookTimeout},
+		queues:     make(map[string][]notification, len(urls)),
+		wake:       make(chan struct{}, 1),
+		urls:       urls,
+		key:        key,
+		attempts:   defaultWebhookAttempts,
+		delay:      defaultWebhookDelay,
+		redelivery: defaultWebhookRedelivery,
+		queueSize:  defaultWebhookQueueSize,
+	}
+}
+
+// Notify queues notification about fired or resolved alert for all urls, it doesn't wait for delivery.
+func (wn *webhookNotifier) Notify(ctx context.Context, alert models.Alert) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	n := notification{payload: payload}
+	if len(wn.key) > 0 {
+		h := hmac.New(sha256.New, wn.key)
+		h.Write(payload)
+		n.sum = base64.StdEncoding.EncodeToString(h.Sum(nil))
+	}
+
+	var errs error
+	wn.mx.Lock()
+	for _, url := range wn.urls {
+		if len(wn.queues[url]) >= wn.queueSize {
+			errs = errors.Join(errs, fmt.Errorf("webhook %s: %w", url, ErrQueueFull))
+			continue
+		}
+
+		wn.queues[url] = append(wn.queues[url], n)
+	}
+	wn.mx.Unlock()
+
+	select {
+	case wn.wake <- struct{}{}:
+	default:
+	}
+
+	return errs
+}
+
+// Start delivers queued notifications until the context is done. Notifications which failed all attempts stay in
+// the queue and are redelivered with the interval, so the webhook receives all notifications in order once it is
+// available again.
+func (wn *webhookNotifier) Start(ctx context.Context) error {
+	ticker := time.NewTicker(wn.redelivery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wn.wake:
+		case <-ticker.C:
+		}
+
+		wn.deliver(ctx)
+	}
+}
+
+// deliver sends queued notifications to each url until the url fails.
+func (wn *webhookNotifier) deliver(ctx context.Context) {
+	for _, url := range wn.urls {
+		for {
+			wn.mx.Lock()
+			queue := wn.queues[url]
+			wn.mx.Unlock()
+			if len(queue) == 0 {
+				break
+			}
+
+			n := queue[0]
+			err := utils.RetryFuncWithBackoff(ctx, func() error {
+				return wn.post(ctx, url, n.payload, n.sum)
+			}, wn.attempts, wn.delay)
+			if err != nil {
+				slog.Error("[alerting.deliver] webhook notification failed",
+					slog.String("url", url),
+					slog.Int("queued", len(queue)),
+					slog.String("error", err.Error()),
+				)
+				break
+			}
+
+			// Only the delivery loop removes notifications, so the head of the queue is the delivered one.
+			wn.mx.Lock()
+			wn.queues[url] = wn.queues[url][1:]
+			wn.mx.Unlock()
+		}
+	}
+}
+
+func (wn *webhookNotifier) post(ctx context.Context, url string, payload []byte, sum string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	if len(sum) > 0 {
+		request.Header.Set("HashSHA256", sum)
+	}
+
+	response, err := wn.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package alerting
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+type webhookReceiver struct {
+	bodies   [][]byte
+	sums     []string
+	failures int
+	calls    int
+	mx       sync.Mutex
+}
+
+func (wr *webhookReceiver) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	wr.mx.Lock()
+	defer wr.mx.Unlock()
+
+	wr.calls++
+	if wr.calls <= wr.failures {
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	body, _ := io.ReadAll(request.Body)
+	wr.bodies = append(wr.bodies, body)
+	wr.sums = append(wr.sums, request.Header.Get("HashSHA256"))
+}
+
+func Test_webhookNotifier_Notify(t *testing.T) {
+	firedAt := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+	resolvedAt := firedAt.Add(time.Minute)
+	value := float64(6e8)
+	firing := models.Alert{
+		ActiveAt: &firedAt,
+		FiredAt:  &firedAt,
+		Value:    &value,
+		Rule:     "gauge HeapAlloc > 5e8",
+		State:    models.AlertStateFiring,
+	}
+	resolved := firing
+	resolved.ResolvedAt = &resolvedAt
+	resolved.State = models.AlertStateResolved
+
+	type args struct {
+		key    []byte
+		alerts []models.Alert
+		rounds int
+	}
+	type want struct {
+		alerts []models.Alert
+		calls  int
+		queued int
+	}
+	tests := []struct {
+		name     string
+		receiver *webhookReceiver
+		args     args
+		want     want
+	}{
+		{
+			name:     "Successfully case",
+			receiver: &webhookReceiver{},
+			args:     args{alerts: []models.Alert{firing, resolved}, rounds: 1},
+			want:     want{alerts: []models.Alert{firing, resolved}, calls: 2},
+		},
+		{
+			name:     "Signed payload",
+			receiver: &webhookReceiver{},
+			args:     args{key: []byte("secret"), alerts: []models.Alert{firing}, rounds: 1},
+			want:     want{alerts: []models.Alert{firing}, calls: 1},
+		},
+		{
+			name:     "Successfully case after retries",
+			receiver: &webhookReceiver{failures: 2},
+			args:     args{alerts: []models.Alert{firing}, rounds: 1},
+			want:     want{alerts: []models.Alert{firing}, calls: 3},
+		},
+		{
+			name:     "All attempts failed",
+			receiver: &webhookReceiver{failures: 5},
+			args:     args{alerts: []models.Alert{firing, resolved}, rounds: 1},
+			want:     want{calls: 3, queued: 2},
+		},
+		{
+			name:     "Failed notifications are redelivered in order",
+			receiver: &webhookReceiver{failures: 3},
+			args:     args{alerts: []models.Alert{firing, resolved}, rounds: 2},
+			want:     want{alerts: []models.Alert{firing, resolved}, calls: 5},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			receiver := httptest.NewServer(test.receiver)
+			defer receiver.Close()
+
+			notifier := NewWebhookNotifier([]string{receiver.URL}, test.args.key).(*webhookNotifier)
+			notifier.delay = time.Millisecond
+
+			for _, alert := range test.args.alerts {
+				require.NoError(t, notifier.Notify(context.Background(), alert))
+			}
+			assert.Equal(t, 0, test.receiver.calls)
+
+			for i := 0; i < test.args.rounds; i++ {
+				notifier.deliver(context.Background())
+			}
+
+			assert.Equal(t, test.want.calls, test.receiver.calls)
+			assert.Len(t, notifier.queues[receiver.URL], test.want.queued)
+			require.Len(t, test.receiver.bodies, len(test.want.alerts))
+			for i, alert := range test.want.alerts {
+				expected, err := json.Marshal(alert)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expected), string(test.receiver.bodies[i]))
+
+				if len(test.args.key) == 0 {
+					assert.Empty(t, test.receiver.sums[i])
+					continue
+				}
+
+				h := hmac.New(sha256.New, test.args.key)
+				h.Write(test.receiver.bodies[i])
+				assert.Equal(t, base64.StdEncoding.EncodeToString(h.Sum(nil)), test.receiver.sums[i])
+			}
+		})
+	}
+}
+
+func Test_webhookNotifier_NotifyQueueFull(t *testing.T) {
+	notifier := NewWebhookNotifier([]string{"http://127.0.0.1:9000/hook"}, nil).(*webhookNotifier)
+	notifier.queueSize = 1
+
+	alert := models.Alert{Rule: "gauge HeapAlloc > 5e8", State: models.AlertStateFiring}
+	require.NoError(t, notifier.Notify(context.Background(), alert))
+	require.ErrorIs(t, notifier.Notify(context.Background(), alert), ErrQueueFull)
+	assert.Len(t, notifier.queues["http://127.0.0.1:9000/hook"], 1)
+}
+
+func Test_webhookNotifier_Start(t *testing.T) {
+	wr := &webhookReceiver{}
+	receiver := httptest.NewServer(wr)
+	defer receiver.Close()
+
+	notifier := NewWebhookNotifier([]string{receiver.URL}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- notifier.Start(ctx)
+	}()
+
+	require.NoError(t, notifier.Notify(ctx, models.Alert{Rule: "gauge HeapAlloc > 5e8", State: models.AlertStateFiring}))
+	assert.Eventually(t, func() bool {
+		wr.mx.Lock()
+		defer wr.mx.Unlock()
+
+		return len(wr.bodies) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
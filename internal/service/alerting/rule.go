@@ -0,0 +1,190 @@
+package alerting
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+// ErrInvalidRule is the error returned when the rule expression cannot be parsed.
+var ErrInvalidRule = errors.New("invalid alerting rule")
+
+// SilentOperator is the operator of rules which fire when the metric was not updated for the threshold number of agents
+// report intervals, e.g. "gauge HeapAlloc silent 3".
+const SilentOperator = "silent"
+
+// Rule is the threshold alerting rule, e.g. "gauge HeapAlloc > 5e8 for 2m". The rule checks all series of the metric
+// with labels matching Labels, e.g. "gauge CPUutilization{cpu=0} > 90".
+type Rule struct {
+	Labels    models.Labels
+	Expr      string
+	MType     models.MetricType
+	MName     models.MetricName
+	Operator  string
+	Threshold float64
+	For       time.Duration
+}
+
+// ParseRule parses the rule expression in format "<type> <name>[{<label>=<value>,...}] <operator> <threshold>
+// [for <duration>]". Label values may be quoted, they can't contain spaces and commas. Supported operators are >, >=, <, <=, ==, != and silent.
+func ParseRule(expr string) (Rule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 4 && len(fields) != 6 {
+		return Rule{}, fmt.Errorf("%w: %q", ErrInvalidRule, expr)
+	}
+
+	rule := Rule{
+		Expr:     strings.Join(fields, " "),
+		MType:    fields[0],
+		Operator: fields[2],
+	}
+
+	var err error
+	rule.MName, rule.Labels, err = parseSelector(fields[1])
+	if err != nil {
+		return Rule{}, fmt.Errorf("%w: %q: %s", ErrInvalidRule, expr, err)
+	}
+
+	if rule.MType != models.GaugeType && rule.MType != models.CounterType {
+		return Rule{}, fmt.Errorf("%w: %q: unknown metric type %q", ErrInvalidRule, expr, rule.MType)
+	}
+
+	switch rule.Operator {
+	case ">", ">=", "<", "<=", "==", "!=", SilentOperator:
+	default:
+		return Rule{}, fmt.Errorf("%w: %q: unknown operator %q", ErrInvalidRule, expr, rule.Operator)
+	}
+
+	rule.Threshold, err = strconv.ParseFloat(fields[3], 64)
+	if err != nil {
+		return Rule{}, fmt.Errorf("%w: %q: %s", ErrInvalidRule, expr, err)
+	}
+
+	if len(fields) == 6 {
+		if fields[4] != "for" {
+			return Rule{}, fmt.Errorf("%w: %q: unexpected %q", ErrInvalidRule, expr, fields[4])
+		}
+
+		rule.For, err = time.ParseDuration(fields[5])
+		if err != nil {
+			return Rule{}, fmt.Errorf("%w: %q: %s", ErrInvalidRule, expr, err)
+		}
+	}
+
+	return rule, nil
+}
+
+// parseSelector parses the metric name with optional label matcher in format name{label1=value1,label2="value 2"}.
+func parseSelector(selector string) (models.MetricName, models.Labels, error) {
+	name, matcher, ok := strings.Cut(selector, "{")
+	if len(name) == 0 {
+		return "", nil, errors.New("empty metric name")
+	}
+	if !ok {
+		return name, nil, nil
+	}
+
+	matcher, ok = strings.CutSuffix(matcher, "}")
+	if !ok {
+		return "", nil, fmt.Errorf("unterminated label matcher %q", selector)
+	}
+
+	var labels models.Labels
+	for _, pair := range strings.Split(matcher, ",") {
+		if len(pair) == 0 {
+			continue
+		}
+
+		label, value, found := strings.Cut(pair, "=")
+		if !found || len(label) == 0 {
+			return "", nil, fmt.Errorf("invalid label matcher %q", pair)
+		}
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid label matcher %q", pair)
+			}
+			value = unquoted
+		}
+
+		if labels == nil {
+			labels = make(models.Labels)
+		}
+		labels[label] = value
+	}
+
+	return name, labels, nil
+}
+
+// series returns metrics checked by the rule sorted by labels: metrics with the type and the name of the rule and
+// labels matching its label matcher.
+func (r Rule) series(metrics models.MetricsList) models.MetricsList {
+	result := make(models.MetricsList, 0)
+	for _, metric := range metrics {
+		if metric.MType == r.MType && metric.ID == r.MName && metric.Labels.Matches(r.Labels) {
+			result = append(result, metric)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Labels.String() < result[j].Labels.String()
+	})
+
+	return result
+}
+
+// LoadRules reads rules from the file. Each line of the file contains one rule, empty lines and lines starting with # are ignored.
+func LoadRules(path string) ([]Rule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules := make([]Rule, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule, err := ParseRule(line)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, scanner.Err()
+}
+
+// Check returns true if the value meets condition of the rule. For silent rules the value is the number of report
+// intervals elapsed since the last update of the metric.
+func (r Rule) Check(value float64) bool {
+	switch r.Operator {
+	case ">":
+		return value > r.Threshold
+	case ">=":
+		return value >= r.Threshold
+	case "<":
+		return value < r.Threshold
+	case "<=":
+		return value <= r.Threshold
+	case "==":
+		return value == r.Threshold
+	case "!=":
+		return value != r.Threshold
+	case SilentOperator:
+		return value >= r.Threshold
+	default:
+		return false
+	}
+}
@@ -0,0 +1,175 @@
+package alerting
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+func TestParseRule(t *testing.T) {
+	type want struct {
+		err  error
+		rule Rule
+	}
+	tests := []struct {
+		name string
+		expr string
+		want want
+	}{
+		{
+			name: "not enough fields",
+			expr: "gauge HeapAlloc >",
+			want: want{err: ErrInvalidRule},
+		},
+		{
+			name: "unknown metric type",
+			expr: "histogram HeapAlloc > 5e8",
+			want: want{err: ErrInvalidRule},
+		},
+		{
+			name: "unknown operator",
+			expr: "gauge HeapAlloc => 5e8",
+			want: want{err: ErrInvalidRule},
+		},
+		{
+			name: "invalid threshold",
+			expr: "gauge HeapAlloc > five",
+			want: want{err: ErrInvalidRule},
+		},
+		{
+			name: "invalid duration",
+			expr: "gauge HeapAlloc > 5e8 for two minutes",
+			want: want{err: ErrInvalidRule},
+		},
+		{
+			name: "unterminated label matcher",
+			expr: "gauge CPUutilization{cpu=0 > 90",
+			want: want{err: ErrInvalidRule},
+		},
+		{
+			name: "label matcher without value",
+			expr: "gauge CPUutilization{cpu} > 90",
+			want: want{err: ErrInvalidRule},
+		},
+		{
+			name: "label matcher without name",
+			expr: "gauge {cpu=0} > 90",
+			want: want{err: ErrInvalidRule},
+		},
+		{
+			name: "invalid quoted label value",
+			expr: `gauge CPUutilization{cpu="0} > 90`,
+			want: want{err: ErrInvalidRule},
+		},
+		{
+			name: "successfully case without duration",
+			expr: "counter PollCount >= 100",
+			want: want{
+				rule: Rule{
+					Expr:      "counter PollCount >= 100",
+					MType:     models.CounterType,
+					MName:     "PollCount",
+					Operator:  ">=",
+					Threshold: 100,
+				},
+			},
+		},
+		{
+			name: "successfully case silent rule",
+			expr: "gauge HeapAlloc silent 3 for 1m",
+			want: want{
+				rule: Rule{
+					Expr:      "gauge HeapAlloc silent 3 for 1m",
+					MType:     models.GaugeType,
+					MName:     "HeapAlloc",
+					Operator:  SilentOperator,
+					Threshold: 3,
+					For:       time.Minute,
+				},
+			},
+		},
+		{
+			name: "successfully case with label matchers",
+			expr: `gauge CPUutilization{cpu=0,host="web1"} > 90`,
+			want: want{
+				rule: Rule{
+					Expr:      `gauge CPUutilization{cpu=0,host="web1"} > 90`,
+					MType:     models.GaugeType,
+					MName:     "CPUutilization",
+					Labels:    models.Labels{"cpu": "0", "host": "web1"},
+					Operator:  ">",
+					Threshold: 90,
+				},
+			},
+		},
+		{
+			name: "successfully case with duration",
+			expr: "  gauge  HeapAlloc > 5e8 for 2m ",
+			want: want{
+				rule: Rule{
+					Expr:      "gauge HeapAlloc > 5e8 for 2m",
+					MType:     models.GaugeType,
+					MName:     "HeapAlloc",
+					Operator:  ">",
+					Threshold: 5e8,
+					For:       2 * time.Minute,
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseRule(test.expr)
+			require.True(t, errors.Is(err, test.want.err), err)
+			assert.Equal(t, test.want.rule, got)
+		})
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	const path = "/tmp/TestLoadRules.rules"
+	err := os.WriteFile(path, []byte("# memory\ngauge HeapAlloc > 5e8 for 2m\n\ncounter PollCount > 100\n"), 0666)
+	require.NoError(t, err)
+
+	rules, err := LoadRules(path)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "gauge HeapAlloc > 5e8 for 2m", rules[0].Expr)
+	assert.Equal(t, "counter PollCount > 100", rules[1].Expr)
+
+	err = os.WriteFile(path, []byte("gauge HeapAlloc\n"), 0666)
+	require.NoError(t, err)
+
+	_, err = LoadRules(path)
+	require.ErrorIs(t, err, ErrInvalidRule)
+}
+
+func TestRule_Check(t *testing.T) {
+	tests := []struct {
+		operator string
+		value    float64
+		want     bool
+	}{
+		{operator: ">", value: 11, want: true},
+		{operator: ">", value: 10, want: false},
+		{operator: ">=", value: 10, want: true},
+		{operator: "<", value: 9, want: true},
+		{operator: "<=", value: 11, want: false},
+		{operator: "==", value: 10, want: true},
+		{operator: "!=", value: 10, want: false},
+		{operator: "silent", value: 10, want: true},
+		{operator: "?", value: 10, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.operator, func(t *testing.T) {
+			r := Rule{Operator: test.operator, Threshold: 10}
+			assert.Equal(t, test.want, r.Check(test.value))
+		})
+	}
+}
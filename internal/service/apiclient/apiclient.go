@@ -4,11 +4,28 @@ package apiclient
 import (
 	"bytes"
 	"compress/gzip"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"time"
+
+	"github.com/e1m0re/grdn/internal/service/identity"
+	"github.com/e1m0re/grdn/internal/service/signing"
+)
+
+// requestTimeout limits the time of the request to the server including reading of the response, so the hung server
+// doesn't block senders of the agent.
+const requestTimeout = 30 * time.Second
+
+var (
+	// ErrServerUnavailable is the error returned when the server failed to process the request, so it can be repeated later.
+	ErrServerUnavailable = errors.New("server unavailable")
+	// ErrInvalidResponseSign is the error returned when the sum of the server response doesn't match its body.
+	ErrInvalidResponseSign = errors.New("invalid response sign")
 )
 
 func compressBody(content *[]byte) (*bytes.Buffer, error) {
@@ -36,15 +53,34 @@ type APIClient interface {
 type client struct {
 	client  *http.Client
 	baseURL string
+	agentID string
+	token   string
+	keyID   string
 	key     []byte
+	// verifyResponses enables check of the server responses sums.
+	verifyResponses bool
 }
 
-// NewAPIClient is client constructor.
-func NewAPIClient(baseURL string, key []byte) APIClient {
+// NewAPIClient is client constructor. The agentID and the bearer token identify the agent if they are not empty.
+// Requests are signed if the key is not empty, the keyID is sent to the server to select the key among rotated ones
+// (empty keyID means the legacy key). If verifyResponses is set, responses of the server must be signed with the same key.
+// The tlsConfig is used for HTTPS connections if it is not nil.
+func NewAPIClient(baseURL string, agentID string, token string, keyID string, key []byte, verifyResponses bool, tlsConfig *tls.Config) APIClient {
+	httpClient := &http.Client{Timeout: requestTimeout}
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		httpClient.Transport = transport
+	}
+
 	return &client{
-		client:  &http.Client{},
-		baseURL: baseURL,
-		key:     key,
+		client:          httpClient,
+		baseURL:         baseURL,
+		agentID:         agentID,
+		token:           token,
+		keyID:           keyID,
+		key:             key,
+		verifyResponses: verifyResponses,
 	}
 }
 
@@ -57,16 +93,15 @@ func (api *client) DoRequest(request *http.Request) (*http.Response, error) {
 			slog.String("method", request.Method),
 			slog.String("error", err.Error()),
 		)
+		return nil, err
 	}
 
 	// todo внимательно посмотреть на обработку ответов с кодом > 400
-	if err == nil && response.StatusCode < 500 {
-		return response, err
+	if response.StatusCode < 500 {
+		return response, nil
 	}
 
-	if response != nil {
-		response.Body.Close()
-	}
+	response.Body.Close()
 
 	return response, request.Context().Err()
 }
@@ -87,17 +122,83 @@ func (api *client) SendMetricsData(data *[]byte) error {
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Content-Encoding", "gzip")
 
+	// The server may accept requests only from the trusted subnet of agents.
+	realIP, err := outboundIP(request.URL.Host)
+	if err != nil {
+		slog.Warn("failed to detect outbound address", slog.String("error", err.Error()))
+	} else {
+		request.Header.Set("X-Real-IP", realIP)
+	}
+
+	if len(api.agentID) > 0 {
+		request.Header.Set(identity.AgentIDHeader, api.agentID)
+	}
+	if len(api.token) > 0 {
+		request.Header.Set("Authorization", "Bearer "+api.token)
+	}
+
 	if len(api.key) > 0 {
-		h := hmac.New(sha256.New, api.key)
-		h.Write(rawData)
-		sum := base64.StdEncoding.EncodeToString(h.Sum(nil))
-		request.Header.Set("HashSHA256", sum)
+		// Timestamp and nonce are signed together with the body, so the server can reject replayed requests.
+		var nonce string
+		nonce, err = signing.NewNonce()
+		if err != nil {
+			return err
+		}
+		timestamp := signing.FormatTimestamp(time.Now())
+
+		request.Header.Set(signing.TimestampHeader, timestamp)
+		request.Header.Set(signing.NonceHeader, nonce)
+		request.Header.Set(signing.HashHeader, signing.Sum(api.key, signing.SignedPayload(timestamp, nonce, rawData)))
+		if len(api.keyID) > 0 {
+			request.Header.Set(signing.KeyIDHeader, api.keyID)
+		}
 	}
 
 	response, err := api.DoRequest(request)
-	if response != nil {
-		defer response.Body.Close()
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 500 {
+		return fmt.Errorf("%w: status code %d", ErrServerUnavailable, response.StatusCode)
+	}
+
+	if api.verifyResponses && len(api.key) > 0 {
+		return api.verifyResponse(response)
+	}
+
+	return nil
+}
+
+// verifyResponse checks the sum of the response body passed in the HashSHA256 header.
+func (api *client) verifyResponse(response *http.Response) error {
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	keys := signing.KeySet{api.keyID: api.key}
+	sum := response.Header.Get(signing.HashHeader)
+	if sum == "" || !keys.Verify(api.keyID, body, sum) {
+		return ErrInvalidResponseSign
+	}
+
+	return nil
+}
+
+// outboundIP returns address of the interface used to connect to the host. UDP dial doesn't send any packets, it only
+// selects the route.
+func outboundIP(host string) (string, error) {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "80")
+	}
+
+	conn, err := net.Dial("udp", host)
+	if err != nil {
+		return "", err
 	}
+	defer conn.Close()
 
-	return err
+	return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
 }
@@ -1,11 +1,19 @@
 package apiclient
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"github.com/stretchr/testify/assert"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"syscall"
 	"testing"
+	"time"
+
+	"github.com/e1m0re/grdn/internal/service/identity"
+	"github.com/e1m0re/grdn/internal/service/signing"
 )
 
 func TestAPIClient_DoRequest(t *testing.T) {
@@ -101,7 +109,7 @@ func TestAPIClient_DoRequest(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			defer func() { test.fields.testServer.Close() }()
-			apiClient := NewAPIClient(test.fields.testServer.URL, nil)
+			apiClient := NewAPIClient(test.fields.testServer.URL, "", "", "", nil, false, nil)
 			got, err := apiClient.DoRequest(test.args.request(test.fields.testServer))
 			if got != nil {
 				defer got.Body.Close()
@@ -114,8 +122,13 @@ func TestAPIClient_DoRequest(t *testing.T) {
 
 func TestAPIClient_SendMetricsData(t *testing.T) {
 	type fields struct {
-		testServer *httptest.Server
-		key        []byte
+		testServer      *httptest.Server
+		agentID         string
+		token           string
+		keyID           string
+		key             []byte
+		verifyResponses bool
+		tls             bool
 	}
 	type args struct {
 		data []byte
@@ -137,7 +150,19 @@ func TestAPIClient_SendMetricsData(t *testing.T) {
 				})),
 			},
 			args: args{data: make([]byte, 0)},
-			want: want{err: nil},
+			want: want{err: ErrServerUnavailable},
+		},
+		{
+			name: "server is down",
+			fields: fields{
+				testServer: func() *httptest.Server {
+					server := httptest.NewServer(http.NotFoundHandler())
+					server.Close()
+					return server
+				}(),
+			},
+			args: args{data: make([]byte, 0)},
+			want: want{err: syscall.ECONNREFUSED},
 		},
 		{
 			name: "Successfully case with encrypt",
@@ -150,13 +175,147 @@ func TestAPIClient_SendMetricsData(t *testing.T) {
 			args: args{data: make([]byte, 0)},
 			want: want{err: nil},
 		},
+		{
+			name: "Successfully case with rotated key",
+			fields: fields{
+				keyID: "2024-05",
+				key:   []byte("new secret"),
+				testServer: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					keys := signing.KeySet{"2024-04": []byte("old secret"), "2024-05": []byte("new secret")}
+					payload := signing.SignedPayload(r.Header.Get(signing.TimestampHeader), r.Header.Get(signing.NonceHeader), []byte("data"))
+					if !keys.Verify(r.Header.Get(signing.KeyIDHeader), payload, r.Header.Get(signing.HashHeader)) {
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+				})),
+			},
+			args: args{data: []byte("data")},
+			want: want{err: nil},
+		},
+		{
+			name: "Successfully case with agent credentials",
+			fields: fields{
+				agentID: "web-1",
+				token:   "token",
+				key:     []byte("agent secret"),
+				testServer: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					payload := signing.SignedPayload(r.Header.Get(signing.TimestampHeader), r.Header.Get(signing.NonceHeader), []byte("data"))
+					if r.Header.Get(identity.AgentIDHeader) != "web-1" ||
+						r.Header.Get("Authorization") != "Bearer token" ||
+						r.Header.Get(signing.HashHeader) != signing.Sum([]byte("agent secret"), payload) {
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+				})),
+			},
+			args: args{data: []byte("data")},
+			want: want{err: nil},
+		},
+		{
+			name: "Successfully case with real IP",
+			fields: fields{
+				testServer: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.Header.Get("X-Real-IP") != "127.0.0.1" {
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+				})),
+			},
+			args: args{data: []byte("data")},
+			want: want{err: nil},
+		},
+		{
+			name: "Successfully case over TLS",
+			fields: fields{
+				tls: true,
+				testServer: httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				})),
+			},
+			args: args{data: []byte("data")},
+			want: want{err: nil},
+		},
+		{
+			name: "Successfully case with verified response",
+			fields: fields{
+				keyID:           "2024-05",
+				key:             []byte("new secret"),
+				verifyResponses: true,
+				testServer: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set(signing.HashHeader, signing.Sum([]byte("new secret"), []byte("response body")))
+					_, _ = w.Write([]byte("response body"))
+				})),
+			},
+			args: args{data: []byte("data")},
+			want: want{err: nil},
+		},
+		{
+			name: "Response without sign",
+			fields: fields{
+				key:             []byte("key"),
+				verifyResponses: true,
+				testServer: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte("response body"))
+				})),
+			},
+			args: args{data: []byte("data")},
+			want: want{err: ErrInvalidResponseSign},
+		},
+		{
+			name: "Response with invalid sign",
+			fields: fields{
+				key:             []byte("key"),
+				verifyResponses: true,
+				testServer: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set(signing.HashHeader, signing.Sum([]byte("key"), []byte("response body")))
+					_, _ = w.Write([]byte("tampered response body"))
+				})),
+			},
+			args: args{data: []byte("data")},
+			want: want{err: ErrInvalidResponseSign},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			defer func() { test.fields.testServer.Close() }()
-			apiClient := NewAPIClient(test.fields.testServer.URL, test.fields.key)
+			var tlsConfig *tls.Config
+			if test.fields.tls {
+				roots := x509.NewCertPool()
+				roots.AddCert(test.fields.testServer.Certificate())
+				tlsConfig = &tls.Config{RootCAs: roots}
+			}
+			apiClient := NewAPIClient(test.fields.testServer.URL, test.fields.agentID, test.fields.token, test.fields.keyID, test.fields.key, test.fields.verifyResponses, tlsConfig)
 			err := apiClient.SendMetricsData(&test.args.data)
-			assert.Equal(t, test.want.err, err)
+			if test.want.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, test.want.err)
 		})
 	}
 }
+
+func TestAPIClient_SendMetricsData_timeout(t *testing.T) {
+	released := make(chan struct{})
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-released:
+		}
+	}))
+	defer testServer.Close()
+	defer close(released)
+
+	api := NewAPIClient(testServer.URL, "", "", "", nil, false, nil)
+	assert.Equal(t, requestTimeout, api.(*client).client.Timeout)
+
+	// The hung server doesn't block the sender longer than the timeout.
+	api.(*client).client.Timeout = 50 * time.Millisecond
+	data := []byte("data")
+	var netErr net.Error
+	assert.ErrorAs(t, api.SendMetricsData(&data), &netErr)
+	assert.True(t, netErr.Timeout())
+}
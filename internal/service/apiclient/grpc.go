@@ -0,0 +1,131 @@
+package apiclient
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/e1m0re/grdn/internal/models"
+	pb "github.com/e1m0re/grdn/internal/proto"
+	"github.com/e1m0re/grdn/internal/service/identity"
+	"github.com/e1m0re/grdn/internal/service/signing"
+)
+
+type grpcClient struct {
+	client     pb.MetricsClient
+	serverAddr string
+	agentID    string
+	token      string
+	keyID      string
+	key        []byte
+}
+
+// NewGRPCClient is constructor of the client sending metrics via gRPC Metrics service. The credentials are passed
+// in the call metadata the same way NewAPIClient passes them in headers. Calls are compressed with gzip. The connection
+// is not encrypted if tlsConfig is nil.
+func NewGRPCClient(serverAddr string, agentID string, token string, keyID string, key []byte, tlsConfig *tls.Config) (APIClient, error) {
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(serverAddr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &grpcClient{
+		client:     pb.NewMetricsClient(conn),
+		serverAddr: serverAddr,
+		agentID:    agentID,
+		token:      token,
+		keyID:      keyID,
+		key:        key,
+	}, nil
+}
+
+// DoRequest is not supported by gRPC client.
+func (api *grpcClient) DoRequest(_ *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("%w: HTTP request via gRPC client", errors.ErrUnsupported)
+}
+
+// SendMetricsData sends metrics data to server. The data is JSON encoded list of metrics. Each report is sent with
+// the unary Update call: UpdateStream acknowledges messages only when the stream is closed, so the agent couldn't
+// tell which reports to spool on failure, and its messages are not signed one by one.
+func (api *grpcClient) SendMetricsData(data *[]byte) error {
+	var metrics models.MetricsList
+	if err := json.Unmarshal(*data, &metrics); err != nil {
+		return err
+	}
+
+	request := &pb.UpdateRequest{Metrics: pb.FromModels(metrics)}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	ctx, err := api.outgoingContext(ctx, pb.Metrics_Update_FullMethodName, request)
+	if err != nil {
+		return err
+	}
+
+	_, err = api.client.Update(ctx, request)
+	if status.Code(err) == codes.Unavailable {
+		return fmt.Errorf("%w: %s", ErrServerUnavailable, err.Error())
+	}
+
+	return err
+}
+
+// outgoingContext returns context with metadata of the call: the agent address, its credentials and the sum of the call.
+func (api *grpcClient) outgoingContext(ctx context.Context, fullMethod string, request any) (context.Context, error) {
+	md := metadata.MD{}
+
+	realIP, err := outboundIP(api.serverAddr)
+	if err != nil {
+		slog.Warn("failed to detect outbound address", slog.String("error", err.Error()))
+	} else {
+		md.Set("x-real-ip", realIP)
+	}
+
+	if len(api.agentID) > 0 {
+		md.Set(identity.AgentIDHeader, api.agentID)
+	}
+	if len(api.token) > 0 {
+		md.Set("authorization", "Bearer "+api.token)
+	}
+
+	if len(api.key) > 0 {
+		data, err := pb.SignedData(fullMethod, request)
+		if err != nil {
+			return nil, err
+		}
+		nonce, err := signing.NewNonce()
+		if err != nil {
+			return nil, err
+		}
+		timestamp := signing.FormatTimestamp(time.Now())
+
+		md.Set(signing.TimestampHeader, timestamp)
+		md.Set(signing.NonceHeader, nonce)
+		md.Set(signing.HashHeader, signing.Sum(api.key, signing.SignedPayload(timestamp, nonce, data)))
+		if len(api.keyID) > 0 {
+			md.Set(signing.KeyIDHeader, api.keyID)
+		}
+	}
+
+	return metadata.NewOutgoingContext(ctx, md), nil
+}
@@ -0,0 +1,83 @@
+package apiclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/e1m0re/grdn/internal/proto"
+	"github.com/e1m0re/grdn/internal/service/signing"
+)
+
+// testMetricsServer remembers metadata and metrics of the last call.
+type testMetricsServer struct {
+	pb.UnimplementedMetricsServer
+	md      metadata.MD
+	metrics []*pb.Metric
+}
+
+func (s *testMetricsServer) Update(ctx context.Context, request *pb.UpdateRequest) (*pb.UpdateResponse, error) {
+	s.md, _ = metadata.FromIncomingContext(ctx)
+	s.metrics = request.GetMetrics()
+
+	return &pb.UpdateResponse{Count: int64(len(s.metrics))}, nil
+}
+
+func TestGRPCClient_SendMetricsData(t *testing.T) {
+	metricsServer := &testMetricsServer{}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	pb.RegisterMetricsServer(server, metricsServer)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	client, err := NewGRPCClient(listener.Addr().String(), "web-1", "token", "2024-05", []byte("secret"), nil)
+	require.NoError(t, err)
+
+	data := []byte("not json")
+	require.Error(t, client.SendMetricsData(&data))
+
+	data = []byte(`[{"id":"PollCount","type":"counter","delta":5}]`)
+	require.NoError(t, client.SendMetricsData(&data))
+
+	request := &pb.UpdateRequest{Metrics: metricsServer.metrics}
+	assert.Len(t, request.Metrics, 1)
+	assert.Equal(t, []string{"127.0.0.1"}, metricsServer.md.Get("x-real-ip"))
+	assert.Equal(t, []string{"web-1"}, metricsServer.md.Get("x-agent-id"))
+	assert.Equal(t, []string{"Bearer token"}, metricsServer.md.Get("authorization"))
+	assert.Equal(t, []string{"2024-05"}, metricsServer.md.Get(signing.KeyIDHeader))
+
+	signed, err := pb.SignedData(pb.Metrics_Update_FullMethodName, request)
+	require.NoError(t, err)
+	payload := signing.SignedPayload(metricsServer.md.Get(signing.TimestampHeader)[0], metricsServer.md.Get(signing.NonceHeader)[0], signed)
+	assert.Equal(t, []string{signing.Sum([]byte("secret"), payload)}, metricsServer.md.Get(signing.HashHeader))
+}
+
+func TestGRPCClient_SendMetricsData_unavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	client, err := NewGRPCClient(addr, "", "", "", nil, nil)
+	require.NoError(t, err)
+
+	data := []byte(`[{"id":"PollCount","type":"counter","delta":5}]`)
+	assert.ErrorIs(t, client.SendMetricsData(&data), ErrServerUnavailable)
+}
+
+func TestGRPCClient_DoRequest(t *testing.T) {
+	client, err := NewGRPCClient("127.0.0.1:3200", "", "", "", nil, nil)
+	require.NoError(t, err)
+
+	_, err = client.DoRequest(&http.Request{})
+	assert.ErrorIs(t, err, errors.ErrUnsupported)
+}
@@ -0,0 +1,92 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// ErrNoCertificates is the error returned when the CA file doesn't contain any PEM certificates.
+var ErrNoCertificates = errors.New("no certificates found")
+
+// LoadCertPool returns pool of the certificates from the PEM file.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%w in %s", ErrNoCertificates, path)
+	}
+
+	return pool, nil
+}
+
+// ServerConfig returns TLS configuration of the server with the certificate and the key. If clientCAFile is not empty
+// clients must present certificates issued by its CA (mutual TLS).
+func ServerConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if len(clientCAFile) > 0 {
+		config.ClientCAs, err = LoadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
+
+// ClientConfig returns TLS configuration of the agent. The server certificate is verified with caFile if it is not
+// empty (system roots otherwise), the client certificate is presented if certFile and keyFile are not empty.
+func ClientConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if len(caFile) > 0 {
+		var err error
+		config.RootCAs, err = LoadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(certFile) > 0 || len(keyFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// PeerCommonName returns common name of the verified client certificate of the request.
+func PeerCommonName(r *http.Request) (string, bool) {
+	return CommonName(r.TLS)
+}
+
+// CommonName returns common name of the verified peer certificate of the connection.
+func CommonName(state *tls.ConnectionState) (string, bool) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+
+	return state.VerifiedChains[0][0].Subject.CommonName, true
+}
@@ -0,0 +1,219 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// generateCert writes certificate with the common name signed by the parent (self-signed if parent is nil).
+func generateCert(t *testing.T, dir string, commonName string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	result := &testCert{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, commonName+".crt"),
+		keyFile:  filepath.Join(dir, commonName+".key"),
+	}
+	require.NoError(t, os.WriteFile(result.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(result.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+
+	return result
+}
+
+func TestLoadCertPool(t *testing.T) {
+	dir := t.TempDir()
+	ca := generateCert(t, dir, "ca", nil)
+	emptyFile := filepath.Join(dir, "empty.pem")
+	require.NoError(t, os.WriteFile(emptyFile, []byte("not a certificate"), 0600))
+
+	_, err := LoadCertPool(filepath.Join(dir, "unknown.pem"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = LoadCertPool(emptyFile)
+	assert.ErrorIs(t, err, ErrNoCertificates)
+
+	pool, err := LoadCertPool(ca.certFile)
+	require.NoError(t, err)
+	assert.NotNil(t, pool)
+}
+
+func TestServerConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := generateCert(t, dir, "ca", nil)
+	server := generateCert(t, dir, "server", ca)
+
+	_, err := ServerConfig(server.certFile, filepath.Join(dir, "unknown.key"), "")
+	assert.Error(t, err)
+
+	_, err = ServerConfig(server.certFile, server.keyFile, filepath.Join(dir, "unknown.pem"))
+	assert.Error(t, err)
+
+	config, err := ServerConfig(server.certFile, server.keyFile, "")
+	require.NoError(t, err)
+	assert.Len(t, config.Certificates, 1)
+	assert.Equal(t, tls.NoClientCert, config.ClientAuth)
+
+	config, err = ServerConfig(server.certFile, server.keyFile, ca.certFile)
+	require.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, config.ClientAuth)
+	assert.NotNil(t, config.ClientCAs)
+}
+
+func TestClientConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := generateCert(t, dir, "ca", nil)
+	agent := generateCert(t, dir, "web-1", ca)
+
+	config, err := ClientConfig("", "", "")
+	require.NoError(t, err)
+	assert.Nil(t, config.RootCAs)
+	assert.Empty(t, config.Certificates)
+
+	_, err = ClientConfig(filepath.Join(dir, "unknown.pem"), "", "")
+	assert.Error(t, err)
+
+	_, err = ClientConfig(ca.certFile, agent.certFile, "")
+	assert.Error(t, err)
+
+	config, err = ClientConfig(ca.certFile, agent.certFile, agent.keyFile)
+	require.NoError(t, err)
+	assert.NotNil(t, config.RootCAs)
+	assert.Len(t, config.Certificates, 1)
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := generateCert(t, dir, "ca", nil)
+	server := generateCert(t, dir, "server", ca)
+	agent := generateCert(t, dir, "web-1", ca)
+	otherCA := generateCert(t, dir, "other-ca", nil)
+	stranger := generateCert(t, dir, "stranger", otherCA)
+
+	serverConfig, err := ServerConfig(server.certFile, server.keyFile, ca.certFile)
+	require.NoError(t, err)
+	testServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		commonName, _ := PeerCommonName(r)
+		_, _ = w.Write([]byte(commonName))
+	}))
+	testServer.TLS = serverConfig
+	testServer.StartTLS()
+	defer testServer.Close()
+
+	tests := []struct {
+		name     string
+		caFile   string
+		certFile string
+		keyFile  string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "agent with certificate",
+			caFile:   ca.certFile,
+			certFile: agent.certFile,
+			keyFile:  agent.keyFile,
+			want:     "web-1",
+		},
+		{
+			name:    "agent without certificate",
+			caFile:  ca.certFile,
+			wantErr: true,
+		},
+		{
+			name:     "agent with certificate of unknown CA",
+			caFile:   ca.certFile,
+			certFile: stranger.certFile,
+			keyFile:  stranger.keyFile,
+			wantErr:  true,
+		},
+		{
+			name:     "agent doesn't trust server CA",
+			caFile:   otherCA.certFile,
+			certFile: agent.certFile,
+			keyFile:  agent.keyFile,
+			wantErr:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clientConfig, err := ClientConfig(test.caFile, test.certFile, test.keyFile)
+			require.NoError(t, err)
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig}}
+
+			response, err := client.Get(testServer.URL)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer response.Body.Close()
+
+			body, err := io.ReadAll(response.Body)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, string(body))
+		})
+	}
+}
+
+func TestPeerCommonName(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	_, ok := PeerCommonName(request)
+	assert.False(t, ok)
+
+	request.TLS = &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "web-1"}}}},
+	}
+	commonName, ok := PeerCommonName(request)
+	assert.True(t, ok)
+	assert.Equal(t, "web-1", commonName)
+}
@@ -0,0 +1,2 @@
+// Package certs builds TLS configurations of the server and the agent from PEM files.
+package certs
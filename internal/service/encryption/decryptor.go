@@ -9,7 +9,7 @@ import (
 
 //go:generate go run github.com/vektra/mockery/v2@v2.43.1 --name=Decryptor
 type Decryptor interface {
-	// Decrypt decrypts specified bytes with RSA OAEP.
+	// Decrypt decrypts specified bytes encrypted by Encryptor.
 	Decrypt(ciphertext []byte) ([]byte, error)
 }
 
@@ -34,11 +34,30 @@ func NewDecryptor(keyFile string) (Decryptor, error) {
 	}, nil
 }
 
-// Decrypt decrypts specified bytes with RSA OAEP.
+// Decrypt decrypts specified bytes encrypted by Encryptor: unwraps AES key with RSA OAEP and decrypts payload with it.
 func (d *decryptor) Decrypt(ciphertext []byte) ([]byte, error) {
 	if d.privateKey == nil {
 		return nil, errors.New("RSA private key not specified")
 	}
 
-	return d.privateKey.Decrypt(nil, ciphertext, &rsa.OAEPOptions{Hash: crypto.SHA256})
+	header, wrappedKey, rest, err := parseEnvelope(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := d.privateKey.Decrypt(nil, wrappedKey, &rsa.OAEPOptions{Hash: crypto.SHA256})
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) < gcm.NonceSize() {
+		return nil, ErrMalformedEnvelope
+	}
+
+	return gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], header)
 }
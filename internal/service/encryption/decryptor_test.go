@@ -1,6 +1,7 @@
 package encryption
 
 import (
+	"bytes"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
@@ -24,6 +25,7 @@ func Test_decryptor_Decrypt(t *testing.T) {
 		privateKey func() *rsa.PrivateKey
 	}
 	type args struct {
+		corrupt   func(ciphertext []byte) []byte
 		plaintext []byte
 	}
 	type want struct {
@@ -77,12 +79,146 @@ func Test_decryptor_Decrypt(t *testing.T) {
 				err: nil,
 			},
 		},
+		{
+			name: "empty ciphertext",
+			fields: fields{
+				publicKey: func() *rsa.PublicKey {
+					block, _ := pem.Decode([]byte(publicKey))
+					key, _ := x509.ParsePKCS1PublicKey(block.Bytes)
+
+					return key
+				},
+				privateKey: func() *rsa.PrivateKey {
+					block, _ := pem.Decode([]byte(privateKey))
+					key, _ := x509.ParsePKCS1PrivateKey(block.Bytes)
+
+					return key
+				},
+			},
+			args: args{
+				plaintext: []byte("Hello world!"),
+				corrupt: func(ciphertext []byte) []byte {
+					return nil
+				},
+			},
+			want: want{
+				err: ErrMalformedEnvelope,
+			},
+		},
+		{
+			name: "unsupported version",
+			fields: fields{
+				publicKey: func() *rsa.PublicKey {
+					block, _ := pem.Decode([]byte(publicKey))
+					key, _ := x509.ParsePKCS1PublicKey(block.Bytes)
+
+					return key
+				},
+				privateKey: func() *rsa.PrivateKey {
+					block, _ := pem.Decode([]byte(privateKey))
+					key, _ := x509.ParsePKCS1PrivateKey(block.Bytes)
+
+					return key
+				},
+			},
+			args: args{
+				plaintext: []byte("Hello world!"),
+				corrupt: func(ciphertext []byte) []byte {
+					ciphertext[0] = 2
+
+					return ciphertext
+				},
+			},
+			want: want{
+				err: ErrUnsupportedVersion,
+			},
+		},
+		{
+			name: "truncated header",
+			fields: fields{
+				publicKey: func() *rsa.PublicKey {
+					block, _ := pem.Decode([]byte(publicKey))
+					key, _ := x509.ParsePKCS1PublicKey(block.Bytes)
+
+					return key
+				},
+				privateKey: func() *rsa.PrivateKey {
+					block, _ := pem.Decode([]byte(privateKey))
+					key, _ := x509.ParsePKCS1PrivateKey(block.Bytes)
+
+					return key
+				},
+			},
+			args: args{
+				plaintext: []byte("Hello world!"),
+				corrupt: func(ciphertext []byte) []byte {
+					return ciphertext[:100]
+				},
+			},
+			want: want{
+				err: ErrMalformedEnvelope,
+			},
+		},
+		{
+			name: "tampered payload",
+			fields: fields{
+				publicKey: func() *rsa.PublicKey {
+					block, _ := pem.Decode([]byte(publicKey))
+					key, _ := x509.ParsePKCS1PublicKey(block.Bytes)
+
+					return key
+				},
+				privateKey: func() *rsa.PrivateKey {
+					block, _ := pem.Decode([]byte(privateKey))
+					key, _ := x509.ParsePKCS1PrivateKey(block.Bytes)
+
+					return key
+				},
+			},
+			args: args{
+				plaintext: []byte("Hello world!"),
+				corrupt: func(ciphertext []byte) []byte {
+					ciphertext[len(ciphertext)-1] ^= 0xff
+
+					return ciphertext
+				},
+			},
+			want: want{
+				err: errors.New("cipher: message authentication failed"),
+			},
+		},
+		{
+			name: "payload larger than RSA key",
+			fields: fields{
+				publicKey: func() *rsa.PublicKey {
+					block, _ := pem.Decode([]byte(publicKey))
+					key, _ := x509.ParsePKCS1PublicKey(block.Bytes)
+
+					return key
+				},
+				privateKey: func() *rsa.PrivateKey {
+					block, _ := pem.Decode([]byte(privateKey))
+					key, _ := x509.ParsePKCS1PrivateKey(block.Bytes)
+
+					return key
+				},
+			},
+			args: args{
+				plaintext: bytes.Repeat([]byte("Hello world!"), 10000),
+			},
+			want: want{
+				err: nil,
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			e := &encryptor{publicKey: test.fields.publicKey()}
 			ciphertext, err := e.Encrypt(test.args.plaintext)
 			require.Nil(t, err)
+			if test.args.corrupt != nil {
+				ciphertext = test.args.corrupt(ciphertext)
+			}
 
 			d := &decryptor{privateKey: test.fields.privateKey()}
 			got, err := d.Decrypt(ciphertext)
@@ -10,7 +10,7 @@ import (
 
 //go:generate go run github.com/vektra/mockery/v2@v2.43.1 --name=Encryptor
 type Encryptor interface {
-	// Encrypt encrypts specified bytes with RSA OAEP.
+	// Encrypt encrypts specified bytes with random AES-GCM key wrapped with RSA OAEP.
 	Encrypt(plaintext []byte) ([]byte, error)
 }
 
@@ -35,11 +35,31 @@ func NewEncryptor(keyFile string) (Encryptor, error) {
 	}, nil
 }
 
-// Encrypt encrypts specified bytes with RSA OAEP.
+// Encrypt encrypts specified bytes with random AES-GCM key wrapped with RSA OAEP.
 func (e *encryptor) Encrypt(plaintext []byte) ([]byte, error) {
 	if e.publicKey == nil {
 		return nil, errors.New("RSA public key not specified")
 	}
 
-	return rsa.EncryptOAEP(sha256.New(), rand.Reader, e.publicKey, plaintext, nil)
+	key := make([]byte, aesKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, e.publicKey, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return sealEnvelope(gcm, wrappedKey, nonce, plaintext), nil
 }
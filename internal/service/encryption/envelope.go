@@ -0,0 +1,69 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+)
+
+// Envelope layout of the encrypted payload:
+//
+//	version (1 byte) | wrapped key length (2 bytes, big endian) | wrapped key | nonce | AES-GCM ciphertext
+//
+// The payload is encrypted with a random AES-256 key which is wrapped with RSA-OAEP. The header (version and the
+// wrapped key) is authenticated as additional data of AES-GCM.
+const (
+	envelopeVersion1 = byte(1)
+	aesKeySize       = 32
+	headerFixedSize  = 3
+)
+
+var (
+	// ErrUnsupportedVersion is the error returned when the envelope version is unknown.
+	ErrUnsupportedVersion = errors.New("unsupported envelope version")
+	// ErrMalformedEnvelope is the error returned when the ciphertext is too short to contain the envelope.
+	ErrMalformedEnvelope = errors.New("malformed envelope")
+)
+
+// newGCM returns AES-GCM cipher with the specified key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// sealEnvelope builds envelope with the wrapped key, nonce and ciphertext of the plaintext.
+func sealEnvelope(gcm cipher.AEAD, wrappedKey, nonce, plaintext []byte) []byte {
+	header := make([]byte, headerFixedSize, headerFixedSize+len(wrappedKey))
+	header[0] = envelopeVersion1
+	binary.BigEndian.PutUint16(header[1:], uint16(len(wrappedKey)))
+	header = append(header, wrappedKey...)
+
+	result := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+gcm.Overhead())
+	result = append(result, header...)
+	result = append(result, nonce...)
+
+	return gcm.Seal(result, nonce, plaintext, header)
+}
+
+// parseEnvelope splits the envelope into the header and the wrapped key. The rest is nonce and AES-GCM ciphertext.
+func parseEnvelope(envelope []byte) (header, wrappedKey, rest []byte, err error) {
+	if len(envelope) < headerFixedSize {
+		return nil, nil, nil, ErrMalformedEnvelope
+	}
+
+	if envelope[0] != envelopeVersion1 {
+		return nil, nil, nil, ErrUnsupportedVersion
+	}
+
+	headerSize := headerFixedSize + int(binary.BigEndian.Uint16(envelope[1:headerFixedSize]))
+	if len(envelope) < headerSize {
+		return nil, nil, nil, ErrMalformedEnvelope
+	}
+
+	return envelope[:headerSize], envelope[headerFixedSize:headerSize], envelope[headerSize:], nil
+}
@@ -0,0 +1,17 @@
+package identity
+
+import "context"
+
+type agentIDKey struct{}
+
+// WithAgentID returns copy of the context with ID of the authenticated agent.
+func WithAgentID(ctx context.Context, agentID string) context.Context {
+	return context.WithValue(ctx, agentIDKey{}, agentID)
+}
+
+// AgentIDFromContext returns ID of the authenticated agent or empty string if the request is anonymous.
+func AgentIDFromContext(ctx context.Context) string {
+	agentID, _ := ctx.Value(agentIDKey{}).(string)
+
+	return agentID
+}
@@ -0,0 +1,2 @@
+// Package identity implements registry of agents credentials and passing of the agent identity in the context.
+package identity
@@ -0,0 +1,77 @@
+// Code generated by mockery v2.43.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	identity "github.com/e1m0re/grdn/internal/service/identity"
+	mock "github.com/stretchr/testify/mock"
+
+	signing "github.com/e1m0re/grdn/internal/service/signing"
+)
+
+// Registry is an autogenerated mock type for the Registry type
+type Registry struct {
+	mock.Mock
+}
+
+// Get provides a mock function with given fields: agentID
+func (_m *Registry) Get(agentID string) (identity.Credentials, bool) {
+	ret := _m.Called(agentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 identity.Credentials
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string) (identity.Credentials, bool)); ok {
+		return rf(agentID)
+	}
+	if rf, ok := ret.Get(0).(func(string) identity.Credentials); ok {
+		r0 = rf(agentID)
+	} else {
+		r0 = ret.Get(0).(identity.Credentials)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(agentID)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// Keys provides a mock function with given fields:
+func (_m *Registry) Keys() signing.KeySet {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Keys")
+	}
+
+	var r0 signing.KeySet
+	if rf, ok := ret.Get(0).(func() signing.KeySet); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(signing.KeySet)
+		}
+	}
+
+	return r0
+}
+
+// NewRegistry creates a new instance of Registry. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRegistry(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Registry {
+	mock := &Registry{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,85 @@
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/e1m0re/grdn/internal/service/signing"
+)
+
+// AgentIDHeader is the name of the header containing ID of the agent.
+const AgentIDHeader = "X-Agent-ID"
+
+// Credentials contains secrets of the agent. At least one of them must be set, the agent must present all set ones.
+type Credentials struct {
+	// Key is the HMAC-SHA256 key used by the agent to sign requests.
+	Key string `json:"key"`
+	// Token is the bearer token passed by the agent in the Authorization header.
+	Token string `json:"token"`
+}
+
+// Registry is the interface of the registry of known agents.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.43.1 --name=Registry
+type Registry interface {
+	// Get returns credentials of the agent.
+	Get(agentID string) (Credentials, bool)
+	// Keys returns sign keys of agents by their IDs.
+	Keys() signing.KeySet
+}
+
+type registry struct {
+	agents map[string]Credentials
+}
+
+// NewRegistry returns registry of specified agents.
+func NewRegistry(agents map[string]Credentials) (Registry, error) {
+	for id, credentials := range agents {
+		if id == "" {
+			return nil, fmt.Errorf("agent id cannot be empty")
+		}
+
+		if credentials.Key == "" && credentials.Token == "" {
+			return nil, fmt.Errorf("agent %q has neither key nor token", id)
+		}
+	}
+
+	return &registry{agents: agents}, nil
+}
+
+// LoadRegistry reads agents from JSON file containing object with agents IDs as names and credentials as values:
+//
+//	{"web-1": {"key": "secret"}, "db-1": {"token": "token"}}
+func LoadRegistry(path string) (Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	agents := make(map[string]Credentials)
+	if err = json.Unmarshal(data, &agents); err != nil {
+		return nil, fmt.Errorf("invalid agents file %s: %w", path, err)
+	}
+
+	return NewRegistry(agents)
+}
+
+// Get returns credentials of the agent.
+func (r *registry) Get(agentID string) (Credentials, bool) {
+	credentials, ok := r.agents[agentID]
+
+	return credentials, ok
+}
+
+// Keys returns sign keys of agents by their IDs.
+func (r *registry) Keys() signing.KeySet {
+	result := make(signing.KeySet)
+	for id, credentials := range r.agents {
+		if credentials.Key != "" {
+			result[id] = []byte(credentials.Key)
+		}
+	}
+
+	return result
+}
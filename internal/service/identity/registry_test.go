@@ -0,0 +1,91 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/service/signing"
+)
+
+func TestLoadRegistry(t *testing.T) {
+	dir := t.TempDir()
+
+	type want struct {
+		agents   map[string]Credentials
+		hasError bool
+	}
+	tests := []struct {
+		name    string
+		content string
+		want    want
+	}{
+		{
+			name:    "successfully case",
+			content: `{"web-1": {"key": "secret"}, "db-1": {"token": "token"}, "api-1": {"key": "api secret", "token": "api token"}}`,
+			want: want{agents: map[string]Credentials{
+				"web-1": {Key: "secret"},
+				"db-1":  {Token: "token"},
+				"api-1": {Key: "api secret", Token: "api token"},
+			}},
+		},
+		{
+			name:    "invalid json",
+			content: `["web-1"]`,
+			want:    want{hasError: true},
+		},
+		{
+			name:    "agent without credentials",
+			content: `{"web-1": {}}`,
+			want:    want{hasError: true},
+		},
+		{
+			name:    "empty agent id",
+			content: `{"": {"key": "secret"}}`,
+			want:    want{hasError: true},
+		},
+	}
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("agents%d.json", i))
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0600))
+
+			got, err := LoadRegistry(path)
+			assert.Equal(t, test.want.hasError, err != nil)
+			if test.want.hasError {
+				return
+			}
+
+			for id, credentials := range test.want.agents {
+				actual, ok := got.Get(id)
+				assert.True(t, ok)
+				assert.Equal(t, credentials, actual)
+			}
+			_, ok := got.Get("unknown")
+			assert.False(t, ok)
+		})
+	}
+
+	_, err := LoadRegistry(filepath.Join(dir, "missing.json"))
+	require.Error(t, err)
+}
+
+func TestRegistry_Keys(t *testing.T) {
+	r, err := NewRegistry(map[string]Credentials{
+		"web-1": {Key: "secret"},
+		"db-1":  {Token: "token"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, signing.KeySet{"web-1": []byte("secret")}, r.Keys())
+}
+
+func TestAgentIDFromContext(t *testing.T) {
+	assert.Equal(t, "", AgentIDFromContext(context.Background()))
+	assert.Equal(t, "web-1", AgentIDFromContext(WithAgentID(context.Background(), "web-1")))
+}
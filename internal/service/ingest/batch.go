@@ -0,0 +1,163 @@
+package ingest
+
+import (
+	"context"
+	"time"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service/metrics"
+	"github.com/e1m0re/grdn/internal/utils"
+)
+
+type gaugeSample struct {
+	value float64
+	// relative is true if the value is the delta to the current value of the gauge.
+	relative bool
+}
+
+// seriesKey identifies the metric in the batch. Labels are formatted to make the key comparable.
+type seriesKey struct {
+	name   models.MetricName
+	labels string
+}
+
+// Batch merges samples of metrics: deltas of counters are summed up, the last value of gauge wins. Samples of the
+// same metric can't be passed to the metrics manager in one update as it reads the current value only once. Metrics
+// are distinguished by names and labels.
+type Batch struct {
+	counters map[seriesKey]int64
+	// totals contains cumulative values of counters, they are converted to deltas to the stored values.
+	totals map[seriesKey]int64
+	gauges map[seriesKey]gaugeSample
+	labels map[seriesKey]models.Labels
+	// times contains the time of the latest sample of metrics received with time.
+	times map[seriesKey]time.Time
+}
+
+// NewBatch returns empty batch.
+func NewBatch() *Batch {
+	return &Batch{
+		counters: make(map[seriesKey]int64),
+		totals:   make(map[seriesKey]int64),
+		gauges:   make(map[seriesKey]gaugeSample),
+		labels:   make(map[seriesKey]models.Labels),
+		times:    make(map[seriesKey]time.Time),
+	}
+}
+
+// key returns the key of the metric and remembers its labels. Empty labels are stored as nil.
+func (b *Batch) key(name models.MetricName, labels models.Labels) seriesKey {
+	key := seriesKey{name: name, labels: labels.String()}
+	if len(labels) > 0 {
+		b.labels[key] = labels
+	}
+
+	return key
+}
+
+// AddCounter adds the delta to the counter.
+func (b *Batch) AddCounter(name models.MetricName, labels models.Labels, delta int64) {
+	key := b.key(name, labels)
+	if _, ok := b.totals[key]; ok {
+		b.totals[key] += delta
+		return
+	}
+
+	b.counters[key] += delta
+}
+
+// SetCounter sets the cumulative value of the counter, deltas added before are discarded.
+func (b *Batch) SetCounter(name models.MetricName, labels models.Labels, total int64) {
+	key := b.key(name, labels)
+	delete(b.counters, key)
+	b.totals[key] = total
+}
+
+// AddGaugeDelta changes the gauge by the delta relative to its current value.
+func (b *Batch) AddGaugeDelta(name models.MetricName, labels models.Labels, delta float64) {
+	key := b.key(name, labels)
+	sample, ok := b.gauges[key]
+	if !ok {
+		sample.relative = true
+	}
+	sample.value += delta
+	b.gauges[key] = sample
+}
+
+// SetGauge sets the gauge value.
+func (b *Batch) SetGauge(name models.MetricName, labels models.Labels, value float64) {
+	b.gauges[b.key(name, labels)] = gaugeSample{value: value}
+}
+
+// SetTime sets the time of the sample of the metric, the latest time wins. Metrics without time are stored with the
+// time of receipt.
+func (b *Batch) SetTime(name models.MetricName, labels models.Labels, ts time.Time) {
+	key := b.key(name, labels)
+	if ts.After(b.times[key]) {
+		b.times[key] = ts
+	}
+}
+
+// Len returns number of metrics in the batch.
+func (b *Batch) Len() int {
+	return len(b.counters) + len(b.totals) + len(b.gauges)
+}
+
+// Store updates metrics of the batch in the metrics manager. Relative gauges and cumulative counters are converted
+// using the stored values, so the batch is converted by the metrics manager with other updates excluded. The batch
+// is converted again on each attempt of the update.
+func (b *Batch) Store(ctx context.Context, metricsManager metrics.Manager) error {
+	return utils.RetryFunc(ctx, func() error {
+		return metricsManager.UpdateMetricsFunc(ctx, func(ctx context.Context) (models.MetricsList, error) {
+			return b.Metrics(ctx, metricsManager)
+		})
+	})
+}
+
+// Metrics returns metrics of the batch. Relative gauges are applied to the values stored by the metrics manager,
+// the missing gauge is considered to be zero. Cumulative counters are converted to deltas to the stored values, so the
+// stored counter follows the source one including its resets.
+func (b *Batch) Metrics(ctx context.Context, metricsManager metrics.Manager) (models.MetricsList, error) {
+	list := make(models.MetricsList, 0, b.Len())
+	for key, delta := range b.counters {
+		delta := delta
+		list = append(list, &models.Metric{Delta: &delta, MType: models.CounterType, ID: key.name, Labels: b.labels[key], Timestamp: b.time(key)})
+	}
+	for key, total := range b.totals {
+		current, err := metricsManager.GetMetric(ctx, models.CounterType, key.name, b.labels[key])
+		if err != nil {
+			return nil, err
+		}
+
+		delta := total
+		if current != nil && current.Delta != nil {
+			delta -= *current.Delta
+		}
+		list = append(list, &models.Metric{Delta: &delta, MType: models.CounterType, ID: key.name, Labels: b.labels[key], Timestamp: b.time(key)})
+	}
+	for key, sample := range b.gauges {
+		value := sample.value
+		if sample.relative {
+			current, err := metricsManager.GetMetric(ctx, models.GaugeType, key.name, b.labels[key])
+			if err != nil {
+				return nil, err
+			}
+			if current != nil {
+				value += current.FloatValue()
+			}
+		}
+		list = append(list, &models.Metric{Value: &value, MType: models.GaugeType, ID: key.name, Labels: b.labels[key], Timestamp: b.time(key)})
+	}
+
+	return list, nil
+}
+
+// time returns the time of the latest sample of the metric or nil if samples were received without time.
+func (b *Batch) time(key seriesKey) *time.Time {
+	ts, ok := b.times[key]
+	if !ok {
+		return nil
+	}
+
+	return &ts
+}
@@ -0,0 +1,192 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service/metrics"
+	"github.com/e1m0re/grdn/internal/service/metrics/mocks"
+	"github.com/e1m0re/grdn/internal/storage/store/memory"
+)
+
+func TestBatch_Metrics(t *testing.T) {
+	stored := int64(40)
+	increased := int64(2)
+	reset := int64(-37)
+	total := int64(7)
+
+	type want struct {
+		err     error
+		metrics models.MetricsList
+	}
+	tests := []struct {
+		mockMetricsManagerFunc func(mockMetricsManager *mocks.Manager)
+		name                   string
+		want                   want
+		total                  int64
+	}{
+		{
+			name:  "new counter",
+			total: 7,
+			mockMetricsManagerFunc: func(mockMetricsManager *mocks.Manager) {
+				mockMetricsManager.
+					On("GetMetric", mock.Anything, models.CounterType, "requests_total", mock.AnythingOfType("models.Labels")).
+					Return(nil, nil)
+			},
+			want: want{
+				metrics: models.MetricsList{{Delta: &total, MType: models.CounterType, ID: "requests_total"}},
+			},
+		},
+		{
+			name:  "counter increased",
+			total: 42,
+			mockMetricsManagerFunc: func(mockMetricsManager *mocks.Manager) {
+				mockMetricsManager.
+					On("GetMetric", mock.Anything, models.CounterType, "requests_total", mock.AnythingOfType("models.Labels")).
+					Return(&models.Metric{Delta: &stored, MType: models.CounterType, ID: "requests_total"}, nil)
+			},
+			want: want{
+				metrics: models.MetricsList{{Delta: &increased, MType: models.CounterType, ID: "requests_total"}},
+			},
+		},
+		{
+			name:  "counter reset",
+			total: 3,
+			mockMetricsManagerFunc: func(mockMetricsManager *mocks.Manager) {
+				mockMetricsManager.
+					On("GetMetric", mock.Anything, models.CounterType, "requests_total", mock.AnythingOfType("models.Labels")).
+					Return(&models.Metric{Delta: &stored, MType: models.CounterType, ID: "requests_total"}, nil)
+			},
+			want: want{
+				metrics: models.MetricsList{{Delta: &reset, MType: models.CounterType, ID: "requests_total"}},
+			},
+		},
+		{
+			name:  "GetMetric failed",
+			total: 3,
+			mockMetricsManagerFunc: func(mockMetricsManager *mocks.Manager) {
+				mockMetricsManager.
+					On("GetMetric", mock.Anything, models.CounterType, "requests_total", mock.AnythingOfType("models.Labels")).
+					Return(nil, errors.New("some error"))
+			},
+			want: want{
+				err: errors.New("some error"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockMetricsManager := mocks.NewManager(t)
+			tt.mockMetricsManagerFunc(mockMetricsManager)
+
+			batch := NewBatch()
+			// Deltas added before the cumulative value are discarded, deltas added after it are summed up.
+			batch.AddCounter("requests_total", nil, 100)
+			batch.SetCounter("requests_total", nil, tt.total-2)
+			batch.AddCounter("requests_total", nil, 2)
+			assert.Equal(t, 1, batch.Len())
+
+			got, err := batch.Metrics(context.Background(), mockMetricsManager)
+			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.metrics, got)
+		})
+	}
+}
+
+func TestBatch_labels(t *testing.T) {
+	batch := NewBatch()
+	batch.AddCounter("requests_total", models.Labels{"method": "GET"}, 1)
+	batch.AddCounter("requests_total", models.Labels{"method": "GET"}, 2)
+	batch.AddCounter("requests_total", models.Labels{"method": "POST"}, 3)
+	batch.AddCounter("requests_total", nil, 4)
+	batch.SetGauge("load", models.Labels{}, 0.5)
+	assert.Equal(t, 4, batch.Len())
+
+	get := int64(3)
+	post := int64(3)
+	unlabeled := int64(4)
+	load := 0.5
+	got, err := batch.Metrics(context.Background(), mocks.NewManager(t))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, models.MetricsList{
+		{Delta: &get, MType: models.CounterType, ID: "requests_total", Labels: models.Labels{"method": "GET"}},
+		{Delta: &post, MType: models.CounterType, ID: "requests_total", Labels: models.Labels{"method": "POST"}},
+		{Delta: &unlabeled, MType: models.CounterType, ID: "requests_total"},
+		{Value: &load, MType: models.GaugeType, ID: "load"},
+	}, got)
+}
+
+func TestBatch_SetTime(t *testing.T) {
+	earlier := time.Unix(1729150000, 0)
+	later := earlier.Add(time.Minute)
+	batch := NewBatch()
+	batch.AddCounter("requests_total", nil, 1)
+	batch.SetTime("requests_total", nil, later)
+	batch.AddCounter("requests_total", nil, 2)
+	batch.SetTime("requests_total", nil, earlier)
+	batch.SetGauge("load", nil, 0.5)
+	batch.SetTime("load", nil, time.Time{})
+	batch.SetGauge("memory", nil, 1.5)
+
+	requests := int64(3)
+	load := 0.5
+	memory := 1.5
+	got, err := batch.Metrics(context.Background(), mocks.NewManager(t))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, models.MetricsList{
+		{Delta: &requests, MType: models.CounterType, ID: "requests_total", Timestamp: &later},
+		{Value: &load, MType: models.GaugeType, ID: "load"},
+		{Value: &memory, MType: models.GaugeType, ID: "memory"},
+	}, got)
+}
+
+func TestBatch_Store(t *testing.T) {
+	ctx := context.Background()
+	memStore, err := memory.NewStore(ctx, "", false, 0)
+	require.NoError(t, err)
+	metricsManager := slowReadManager{Manager: metrics.NewMetricsManager(memStore)}
+
+	// Concurrent batches carry increasing cumulative values of the same counter and deltas of the same gauge, slow
+	// reads make their conversions interleave unless they are serialized.
+	const batches = 50
+	var wg sync.WaitGroup
+	for i := 1; i <= batches; i++ {
+		wg.Add(1)
+		go func(total int64) {
+			defer wg.Done()
+			batch := NewBatch()
+			batch.SetCounter("requests_total", nil, total)
+			batch.AddGaugeDelta("in_flight", nil, 1)
+			assert.NoError(t, batch.Store(ctx, metricsManager))
+		}(int64(i))
+	}
+	wg.Wait()
+
+	counter, err := metricsManager.GetMetric(ctx, models.CounterType, "requests_total", nil)
+	require.NoError(t, err)
+	require.NotNil(t, counter)
+	assert.LessOrEqual(t, *counter.Delta, int64(batches))
+
+	gauge, err := metricsManager.GetMetric(ctx, models.GaugeType, "in_flight", nil)
+	require.NoError(t, err)
+	require.NotNil(t, gauge)
+	assert.Equal(t, float64(batches), *gauge.Value)
+}
+
+// slowReadManager delays reading of metrics.
+type slowReadManager struct {
+	metrics.Manager
+}
+
+func (m slowReadManager) GetMetric(ctx context.Context, mType models.MetricType, mName models.MetricName, labels models.Labels) (*models.Metric, error) {
+	time.Sleep(time.Millisecond)
+	return m.Manager.GetMetric(ctx, mType, mName, labels)
+}
@@ -0,0 +1,116 @@
+package ingest
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service/metrics"
+)
+
+// Batcher is the interface of the buffer of samples received by listeners. Samples are merged into a batch which is
+// flushed to the metrics manager periodically.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.43.1 --name=Batcher
+type Batcher interface {
+	// AddCounter adds the delta to the counter.
+	AddCounter(name models.MetricName, labels models.Labels, delta int64)
+
+	// AddGaugeDelta changes the gauge by the delta relative to its current value.
+	AddGaugeDelta(name models.MetricName, labels models.Labels, delta float64)
+
+	// Flush sends the batch to the metrics manager.
+	Flush(ctx context.Context) error
+
+	// SetGauge sets the gauge value.
+	SetGauge(name models.MetricName, labels models.Labels, value float64)
+
+	// SetTime sets the time of the sample of the metric, the latest time wins.
+	SetTime(name models.MetricName, labels models.Labels, ts time.Time)
+
+	// Start flushes batches with the interval until the context is done.
+	Start(ctx context.Context) error
+}
+
+type batcher struct {
+	metricsManager metrics.Manager
+	batch          *Batch
+	interval       time.Duration
+	mx             sync.Mutex
+}
+
+// NewBatcher returns new instance of batcher flushing batches with the interval.
+func NewBatcher(metricsManager metrics.Manager, interval time.Duration) Batcher {
+	return &batcher{
+		metricsManager: metricsManager,
+		batch:          NewBatch(),
+		interval:       interval,
+	}
+}
+
+// AddCounter adds the delta to the counter.
+func (b *batcher) AddCounter(name models.MetricName, labels models.Labels, delta int64) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.batch.AddCounter(name, labels, delta)
+}
+
+// AddGaugeDelta changes the gauge by the delta relative to its current value.
+func (b *batcher) AddGaugeDelta(name models.MetricName, labels models.Labels, delta float64) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.batch.AddGaugeDelta(name, labels, delta)
+}
+
+// SetGauge sets the gauge value.
+func (b *batcher) SetGauge(name models.MetricName, labels models.Labels, value float64) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.batch.SetGauge(name, labels, value)
+}
+
+// SetTime sets the time of the sample of the metric, the latest time wins.
+func (b *batcher) SetTime(name models.MetricName, labels models.Labels, ts time.Time) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.batch.SetTime(name, labels, ts)
+}
+
+// Flush sends the batch to the metrics manager. The batch is dropped if the manager fails to update metrics.
+func (b *batcher) Flush(ctx context.Context) error {
+	b.mx.Lock()
+	batch := b.batch
+	b.batch = NewBatch()
+	b.mx.Unlock()
+
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	return batch.Store(ctx, b.metricsManager)
+}
+
+// Start flushes batches with the interval until the context is done. The rest of samples is not flushed on exit,
+// the owner of the batcher must call Flush after listeners are stopped.
+func (b *batcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			err := b.Flush(ctx)
+			if err != nil {
+				slog.Error("[ingest.Start] flush failed", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
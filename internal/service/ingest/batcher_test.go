@@ -0,0 +1,136 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service/metrics/mocks"
+)
+
+func Test_batcher_Flush(t *testing.T) {
+	delta := int64(5)
+	value := float64(1.5)
+	stored := float64(10)
+	relative := float64(12.5)
+
+	type want struct {
+		err     error
+		metrics models.MetricsList
+	}
+	tests := []struct {
+		fill                   func(b Batcher)
+		mockMetricsManagerFunc func(mockMetricsManager *mocks.Manager)
+		name                   string
+		want                   want
+	}{
+		{
+			name: "empty batch",
+			fill: func(b Batcher) {},
+			mockMetricsManagerFunc: func(mockMetricsManager *mocks.Manager) {
+			},
+			want: want{},
+		},
+		{
+			name: "counters and gauges are merged",
+			fill: func(b Batcher) {
+				b.AddCounter("requests", nil, 2)
+				b.AddCounter("requests", nil, 3)
+				b.SetGauge("load", nil, 7)
+				b.SetGauge("load", nil, 1)
+				b.AddGaugeDelta("load", nil, 0.5)
+			},
+			mockMetricsManagerFunc: func(mockMetricsManager *mocks.Manager) {
+				mockMetricsManager.
+					On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
+					Return(nil)
+			},
+			want: want{
+				metrics: models.MetricsList{
+					{Delta: &delta, MType: models.CounterType, ID: "requests"},
+					{Value: &value, MType: models.GaugeType, ID: "load"},
+				},
+			},
+		},
+		{
+			name: "relative gauge is applied to the stored value",
+			fill: func(b Batcher) {
+				b.AddGaugeDelta("load", nil, 2)
+				b.AddGaugeDelta("load", nil, 0.5)
+			},
+			mockMetricsManagerFunc: func(mockMetricsManager *mocks.Manager) {
+				mockMetricsManager.
+					On("GetMetric", mock.Anything, models.GaugeType, "load", mock.AnythingOfType("models.Labels")).
+					Return(&models.Metric{Value: &stored, MType: models.GaugeType, ID: "load"}, nil)
+				mockMetricsManager.
+					On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
+					Return(nil)
+			},
+			want: want{
+				metrics: models.MetricsList{
+					{Value: &relative, MType: models.GaugeType, ID: "load"},
+				},
+			},
+		},
+		{
+			name: "GetMetric failed",
+			fill: func(b Batcher) {
+				b.AddGaugeDelta("load", nil, 2)
+			},
+			mockMetricsManagerFunc: func(mockMetricsManager *mocks.Manager) {
+				mockMetricsManager.
+					On("GetMetric", mock.Anything, models.GaugeType, "load", mock.AnythingOfType("models.Labels")).
+					Return(nil, errors.New("some error"))
+			},
+			want: want{
+				err: errors.New("some error"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockMetricsManager := mocks.NewManager(t)
+			mockUpdateMetricsFunc(mockMetricsManager)
+			tt.mockMetricsManagerFunc(mockMetricsManager)
+
+			b := NewBatcher(mockMetricsManager, 0)
+			tt.fill(b)
+
+			err := b.Flush(context.Background())
+			if tt.want.err != nil {
+				// Failed conversions are retried with the update.
+				assert.ErrorContains(t, err, tt.want.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.want.metrics != nil {
+				mockMetricsManager.AssertCalled(t, "UpdateMetrics", mock.Anything, mock.MatchedBy(func(list models.MetricsList) bool {
+					return assert.ElementsMatch(t, tt.want.metrics, list)
+				}))
+			}
+
+			// The batch is reset after flush.
+			assert.NoError(t, b.Flush(context.Background()))
+		})
+	}
+}
+
+// mockUpdateMetricsFunc makes the mock build batches passed to UpdateMetricsFunc and update them with UpdateMetrics, so
+// tests can check updated metrics the same way for both methods.
+func mockUpdateMetricsFunc(mockMetricsManager *mocks.Manager) {
+	mockMetricsManager.
+		On("UpdateMetricsFunc", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(ctx context.Context) (models.MetricsList, error)) error {
+			list, err := fn(ctx)
+			if err != nil {
+				return err
+			}
+
+			return mockMetricsManager.UpdateMetrics(ctx, list)
+		}).
+		Maybe()
+}
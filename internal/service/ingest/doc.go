@@ -0,0 +1,2 @@
+// Package ingest contains business logic shared by listeners of third-party metrics protocols.
+package ingest
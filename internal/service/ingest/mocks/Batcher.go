@@ -0,0 +1,88 @@
+// Code generated by mockery v2.43.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "github.com/e1m0re/grdn/internal/models"
+
+	time "time"
+)
+
+// Batcher is an autogenerated mock type for the Batcher type
+type Batcher struct {
+	mock.Mock
+}
+
+// AddCounter provides a mock function with given fields: name, labels, delta
+func (_m *Batcher) AddCounter(name string, labels models.Labels, delta int64) {
+	_m.Called(name, labels, delta)
+}
+
+// AddGaugeDelta provides a mock function with given fields: name, labels, delta
+func (_m *Batcher) AddGaugeDelta(name string, labels models.Labels, delta float64) {
+	_m.Called(name, labels, delta)
+}
+
+// Flush provides a mock function with given fields: ctx
+func (_m *Batcher) Flush(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Flush")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetGauge provides a mock function with given fields: name, labels, value
+func (_m *Batcher) SetGauge(name string, labels models.Labels, value float64) {
+	_m.Called(name, labels, value)
+}
+
+// SetTime provides a mock function with given fields: name, labels, ts
+func (_m *Batcher) SetTime(name string, labels models.Labels, ts time.Time) {
+	_m.Called(name, labels, ts)
+}
+
+// Start provides a mock function with given fields: ctx
+func (_m *Batcher) Start(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Start")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewBatcher creates a new instance of Batcher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewBatcher(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Batcher {
+	mock := &Batcher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
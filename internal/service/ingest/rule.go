@@ -0,0 +1,71 @@
+package ingest
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+// Sink is the receiver of ingested samples. It is implemented by Batch and Batcher.
+type Sink interface {
+	// AddCounter adds the delta to the counter.
+	AddCounter(name models.MetricName, labels models.Labels, delta int64)
+
+	// SetGauge sets the gauge value.
+	SetGauge(name models.MetricName, labels models.Labels, value float64)
+
+	// SetTime sets the time of the sample of the metric, the latest time wins.
+	SetTime(name models.MetricName, labels models.Labels, ts time.Time)
+}
+
+// TypeRule decides types of metrics received in protocols which don't distinguish counters and gauges. Metrics are
+// gauges unless the name ends with CounterSuffix or the field of InfluxDB line is one of CounterFields.
+type TypeRule struct {
+	CounterSuffix string
+	CounterFields []string
+}
+
+// MetricType returns type of the metric with the name. The field is the name of InfluxDB field, it is empty for
+// other protocols.
+func (r TypeRule) MetricType(name models.MetricName, field string) models.MetricType {
+	if len(r.CounterSuffix) > 0 && strings.HasSuffix(name, r.CounterSuffix) {
+		return models.CounterType
+	}
+	if len(field) > 0 && slices.Contains(r.CounterFields, field) {
+		return models.CounterType
+	}
+
+	return models.GaugeType
+}
+
+// Add adds the value of the sample to the sink according to the type of the metric. Values of counters are deltas,
+// they are rounded to integers. Zero time of the sample means the time of receipt.
+func (r TypeRule) Add(sink Sink, name models.MetricName, field string, value float64, ts time.Time) {
+	if r.MetricType(name, field) == models.CounterType {
+		sink.AddCounter(name, nil, int64(math.Round(value)))
+	} else {
+		sink.SetGauge(name, nil, value)
+	}
+
+	if !ts.IsZero() {
+		sink.SetTime(name, nil, ts)
+	}
+}
+
+// LineError is the error of parsing the line of text protocol.
+type LineError struct {
+	Err  error
+	Line int
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
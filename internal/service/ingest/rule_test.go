@@ -0,0 +1,72 @@
+package ingest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+func TestTypeRule_MetricType(t *testing.T) {
+	rule := TypeRule{CounterSuffix: "_total", CounterFields: []string{"count"}}
+	tests := []struct {
+		name  string
+		mName models.MetricName
+		field string
+		want  models.MetricType
+	}{
+		{
+			name:  "gauge by default",
+			mName: "servers.web1.load",
+			want:  models.GaugeType,
+		},
+		{
+			name:  "counter by suffix",
+			mName: "requests_total",
+			want:  models.CounterType,
+		},
+		{
+			name:  "counter by field",
+			mName: "http_count",
+			field: "count",
+			want:  models.CounterType,
+		},
+		{
+			name:  "gauge field",
+			mName: "cpu_usage",
+			field: "usage",
+			want:  models.GaugeType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rule.MetricType(tt.mName, tt.field))
+		})
+	}
+
+	assert.Equal(t, models.GaugeType, TypeRule{}.MetricType("requests_total", "count"))
+}
+
+func TestTypeRule_Add(t *testing.T) {
+	rule := TypeRule{CounterSuffix: "_total"}
+	batch := NewBatch()
+	sampleTime := time.Unix(1729150000, 0)
+	rule.Add(batch, "requests_total", "", 2.6, time.Time{})
+	rule.Add(batch, "requests_total", "", 1, time.Time{})
+	rule.Add(batch, "load", "", 0.5, sampleTime)
+
+	assert.Equal(t, map[seriesKey]int64{{name: "requests_total"}: 4}, batch.counters)
+	assert.Equal(t, map[seriesKey]gaugeSample{{name: "load"}: {value: 0.5}}, batch.gauges)
+	assert.Equal(t, map[seriesKey]time.Time{{name: "load"}: sampleTime}, batch.times)
+}
+
+func TestLineError(t *testing.T) {
+	someErr := errors.New("some error")
+	err := error(&LineError{Line: 3, Err: someErr})
+
+	assert.EqualError(t, err, "line 3: some error")
+	assert.ErrorIs(t, err, someErr)
+}
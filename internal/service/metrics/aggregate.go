@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"math"
+	"time"
+
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+// aggregate splits the samples ordered by time into buckets of the specified step starting from the specified moment.
+// Empty buckets are omitted.
+func aggregate(samples models.MetricsList, from time.Time, step time.Duration) []models.Bucket {
+	result := make([]models.Bucket, 0)
+
+	var (
+		bucket *models.Bucket
+		prev   *models.Metric
+		ref    *models.Metric
+	)
+	for _, sample := range samples {
+		if sample.Timestamp == nil || sample.Timestamp.Before(from) {
+			prev = sample
+			continue
+		}
+
+		start := from.Add(sample.Timestamp.Sub(from) / step * step)
+		if bucket == nil || !bucket.Start.Equal(start) {
+			if bucket != nil {
+				result = append(result, *bucket)
+			}
+			bucket = &models.Bucket{
+				Start: start,
+				Min:   math.Inf(1),
+				Max:   math.Inf(-1),
+			}
+			// The rate is calculated relative to the last sample of the previous bucket if it exists.
+			ref = prev
+			if ref == nil {
+				ref = sample
+			}
+		}
+
+		value := sample.FloatValue()
+		bucket.Count++
+		bucket.Sum += value
+		bucket.Min = math.Min(bucket.Min, value)
+		bucket.Max = math.Max(bucket.Max, value)
+		bucket.Avg = bucket.Sum / float64(bucket.Count)
+		bucket.Last = value
+		if elapsed := sample.Timestamp.Sub(*ref.Timestamp).Seconds(); elapsed > 0 {
+			bucket.Rate = (value - ref.FloatValue()) / elapsed
+		}
+
+		prev = sample
+	}
+
+	if bucket != nil {
+		result = append(result, *bucket)
+	}
+
+	return result
+}
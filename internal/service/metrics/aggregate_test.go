@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+func Test_aggregate(t *testing.T) {
+	from := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+	sample := func(offset time.Duration, delta int64) *models.Metric {
+		ts := from.Add(offset)
+		return &models.Metric{
+			Delta:     &delta,
+			Timestamp: &ts,
+			MType:     models.CounterType,
+			ID:        "PollCount",
+		}
+	}
+	type args struct {
+		samples models.MetricsList
+		step    time.Duration
+	}
+	tests := []struct {
+		name string
+		want []models.Bucket
+		args args
+	}{
+		{
+			name: "Empty samples list",
+			args: args{
+				samples: models.MetricsList{},
+				step:    time.Minute,
+			},
+			want: []models.Bucket{},
+		},
+		{
+			name: "Successfully case",
+			args: args{
+				samples: models.MetricsList{
+					sample(-10*time.Second, 0),
+					sample(10*time.Second, 10),
+					sample(50*time.Second, 30),
+					sample(3*time.Minute, 95),
+				},
+				step: time.Minute,
+			},
+			want: []models.Bucket{
+				{
+					Start: from,
+					Min:   10,
+					Max:   30,
+					Avg:   20,
+					Sum:   40,
+					Last:  30,
+					Rate:  0.5,
+					Count: 2,
+				},
+				{
+					Start: from.Add(3 * time.Minute),
+					Min:   95,
+					Max:   95,
+					Avg:   95,
+					Sum:   95,
+					Last:  95,
+					Rate:  0.5,
+					Count: 1,
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, aggregate(test.args.samples, from, test.args.step))
+		})
+	}
+}
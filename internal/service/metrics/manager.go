@@ -2,8 +2,12 @@ package metrics
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"time"
 
 	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service/identity"
 	"github.com/e1m0re/grdn/internal/storage"
 	"github.com/e1m0re/grdn/internal/storage/store"
 )
@@ -15,24 +19,49 @@ type Manager interface {
 	// GetAllMetrics returns result of all metrics.
 	GetAllMetrics(ctx context.Context) (*models.MetricsList, error)
 
-	// GetMetric returns an object Metric. Returns nil,nil if metric not found.
-	GetMetric(ctx context.Context, mType models.MetricType, mName models.MetricName) (*models.Metric, error)
+	// GetMetric returns an object Metric with exactly the specified labels. Returns nil,nil if metric not found.
+	GetMetric(ctx context.Context, mType models.MetricType, mName models.MetricName, labels models.Labels) (*models.Metric, error)
+
+	// GetMetricHistory returns samples of the metric recorded in the time window [from, to], ordered by time.
+	GetMetricHistory(ctx context.Context, mType models.MetricType, mName models.MetricName, labels models.Labels, from, to time.Time) (*models.MetricsList, error)
+
+	// QueryMetric returns values of the metric in the time window [from, to] aggregated by buckets of the specified step.
+	QueryMetric(ctx context.Context, mType models.MetricType, mName models.MetricName, labels models.Labels, from, to time.Time, step time.Duration) ([]models.Bucket, error)
 
 	// UpdateMetric performs updates to the value of the specified result in the store.
 	UpdateMetric(ctx context.Context, metric models.Metric) error
 
 	// UpdateMetrics performs batch updates of result values in the store.
 	UpdateMetrics(ctx context.Context, metrics models.MetricsList) error
+
+	// UpdateMetricsFunc performs batch updates of metrics returned by fn. Other updates are excluded while fn is
+	// called and the batch is updated, so updates made of the stored values (e.g. cumulative counters converted to
+	// deltas) can't be interleaved with other writers.
+	UpdateMetricsFunc(ctx context.Context, fn func(ctx context.Context) (models.MetricsList, error)) error
 }
 
+// MaxQueryBuckets is the maximum number of buckets that can be returned by QueryMetric.
+const MaxQueryBuckets = 11000
+
+var (
+	// ErrInvalidTimeRange is the error returned when the time range of the query is invalid.
+	ErrInvalidTimeRange = errors.New("invalid time range")
+	// ErrInvalidStep is the error returned when the step of the query is invalid.
+	ErrInvalidStep = errors.New("invalid step")
+)
+
 type metricsManager struct {
 	store store.Store
+	now   func() time.Time
+	// mx serializes updates: the stored value is read, merged with the update and written back.
+	mx sync.Mutex
 }
 
 // NewMetricsManager returns new instance of metrics manager.
 func NewMetricsManager(s store.Store) Manager {
 	return &metricsManager{
 		store: s,
+		now:   time.Now,
 	}
 }
 
@@ -41,22 +70,48 @@ func (mm *metricsManager) GetAllMetrics(ctx context.Context) (*models.MetricsLis
 	return mm.store.GetAllMetrics(ctx)
 }
 
-// GetMetric returns an object Metric. Returns nil,nil if metric not found.
-func (mm *metricsManager) GetMetric(ctx context.Context, mType models.MetricType, mName models.MetricName) (*models.Metric, error) {
-	return mm.store.GetMetric(ctx, mType, mName)
+// GetMetric returns an object Metric with exactly the specified labels. Returns nil,nil if metric not found.
+func (mm *metricsManager) GetMetric(ctx context.Context, mType models.MetricType, mName models.MetricName, labels models.Labels) (*models.Metric, error) {
+	return mm.store.GetMetric(ctx, mType, mName, labels)
+}
+
+// GetMetricHistory returns samples of the metric recorded in the time window [from, to], ordered by time.
+func (mm *metricsManager) GetMetricHistory(ctx context.Context, mType models.MetricType, mName models.MetricName, labels models.Labels, from, to time.Time) (*models.MetricsList, error) {
+	return mm.store.GetMetricHistory(ctx, mType, mName, labels, from, to)
+}
+
+// QueryMetric returns values of the metric in the time window [from, to] aggregated by buckets of the specified step.
+func (mm *metricsManager) QueryMetric(ctx context.Context, mType models.MetricType, mName models.MetricName, labels models.Labels, from, to time.Time, step time.Duration) ([]models.Bucket, error) {
+	switch {
+	case mType != models.GaugeType && mType != models.CounterType:
+		return nil, storage.ErrUnknownMetricType
+	case !from.Before(to):
+		return nil, ErrInvalidTimeRange
+	case step <= 0 || to.Sub(from)/step >= MaxQueryBuckets:
+		return nil, ErrInvalidStep
+	}
+
+	// The previous step is loaded as well to calculate the rate of the first bucket.
+	samples, err := mm.store.GetMetricHistory(ctx, mType, mName, labels, from.Add(-step), to)
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregate(*samples, from, step), nil
 }
 
 func (mm *metricsManager) processUpdateMetric(ctx context.Context, metric models.Metric) (*models.Metric, error) {
-	cm, err := mm.store.GetMetric(ctx, metric.MType, metric.ID)
+	cm, err := mm.store.GetMetric(ctx, metric.MType, metric.ID, metric.Labels)
 	if err != nil {
 		return nil, err
 	}
 	if cm == nil {
 		cm = &models.Metric{
-			Value: nil,
-			Delta: nil,
-			MType: metric.MType,
-			ID:    metric.ID,
+			Value:  nil,
+			Delta:  nil,
+			Labels: metric.Labels,
+			MType:  metric.MType,
+			ID:     metric.ID,
 		}
 	}
 
@@ -70,37 +125,84 @@ func (mm *metricsManager) processUpdateMetric(ctx context.Context, metric models
 		} else {
 			cm.Delta = metric.Delta
 		}
+	case models.HistogramType:
+		// Histograms contain observations since the previous update, they are added to the stored buckets.
+		if err = metric.Histogram.Validate(); err != nil {
+			return nil, err
+		}
+		cm.Histogram = cm.Histogram.Merge(metric.Histogram)
+	case models.SummaryType:
+		if err = metric.Summary.Validate(); err != nil {
+			return nil, err
+		}
+		cm.Summary = metric.Summary
 	default:
 		return nil, storage.ErrUnknownMetricType
 	}
 
+	// The writer is taken from the authenticated request only, the agent can't claim other ID in the metric.
+	cm.AgentID = identity.AgentIDFromContext(ctx)
+
+	// The time of the last update is used to detect stale metrics of silent agents. It is always taken from the server
+	// clock, so agents can't hide or skew their silence with own timestamps. The history is recorded at the time of
+	// the sample, samples without time or from the future are recorded at the time of receipt.
+	now := mm.now()
+	cm.LastSeen = &now
+	cm.Timestamp = &now
+	if metric.Timestamp != nil && !metric.Timestamp.After(now) {
+		timestamp := *metric.Timestamp
+		cm.Timestamp = &timestamp
+	}
+
 	return cm, nil
 }
 
 // UpdateMetric performs updates to the value of the specified result in the store.
 func (mm *metricsManager) UpdateMetric(ctx context.Context, metric models.Metric) error {
-	cm, err := mm.processUpdateMetric(ctx, metric)
+	mm.mx.Lock()
+	defer mm.mx.Unlock()
+
+	return mm.updateMetrics(ctx, models.MetricsList{&metric})
+}
+
+// UpdateMetrics performs batch updates of result values in the store.
+func (mm *metricsManager) UpdateMetrics(ctx context.Context, metrics models.MetricsList) error {
+	mm.mx.Lock()
+	defer mm.mx.Unlock()
+
+	return mm.updateMetrics(ctx, metrics)
+}
+
+// UpdateMetricsFunc performs batch updates of metrics returned by fn. Other updates are excluded while fn is called
+// and the batch is updated.
+func (mm *metricsManager) UpdateMetricsFunc(ctx context.Context, fn func(ctx context.Context) (models.MetricsList, error)) error {
+	mm.mx.Lock()
+	defer mm.mx.Unlock()
+
+	metrics, err := fn(ctx)
 	if err != nil {
 		return err
 	}
 
-	return mm.store.UpdateMetrics(ctx, models.MetricsList{cm})
+	return mm.updateMetrics(ctx, metrics)
 }
 
-// UpdateMetrics performs batch updates of result values in the store.
-func (mm *metricsManager) UpdateMetrics(ctx context.Context, metrics models.MetricsList) error {
+// updateMetrics merges updates with the stored values and writes them to the store, it must be called with mx
+// locked. The passed list is not changed, so failed updates can be retried with it.
+func (mm *metricsManager) updateMetrics(ctx context.Context, metrics models.MetricsList) error {
 	if len(metrics) == 0 {
 		return nil
 	}
 
-	for i, metric := range metrics {
+	updates := make(models.MetricsList, 0, len(metrics))
+	for _, metric := range metrics {
 		cm, err := mm.processUpdateMetric(ctx, *metric)
 		if err != nil {
 			return err
 		}
 
-		metrics[i] = cm
+		updates = append(updates, cm)
 	}
 
-	return mm.store.UpdateMetrics(ctx, metrics)
+	return mm.store.UpdateMetrics(ctx, updates)
 }
@@ -3,13 +3,17 @@ package metrics
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service/identity"
+	"github.com/e1m0re/grdn/internal/storage"
 	"github.com/e1m0re/grdn/internal/storage/store"
 	"github.com/e1m0re/grdn/internal/storage/store/mocks"
 )
@@ -148,7 +152,7 @@ func Test_metricsManager_GetMetric(t *testing.T) {
 			mockStore: func() store.Store {
 				mockStore := mocks.NewStore(t)
 				mockStore.
-					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 					Return(nil, errors.New("something wrong"))
 
 				return mockStore
@@ -168,7 +172,7 @@ func Test_metricsManager_GetMetric(t *testing.T) {
 			mockStore: func() store.Store {
 				mockStore := mocks.NewStore(t)
 				mockStore.
-					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 					Return(nil, nil)
 
 				return mockStore
@@ -188,7 +192,7 @@ func Test_metricsManager_GetMetric(t *testing.T) {
 			mockStore: func() store.Store {
 				mockStore := mocks.NewStore(t)
 				mockStore.
-					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 					Return(&models.Metric{
 						Value: &value,
 						Delta: nil,
@@ -218,7 +222,7 @@ func Test_metricsManager_GetMetric(t *testing.T) {
 			mockStore: func() store.Store {
 				mockStore := mocks.NewStore(t)
 				mockStore.
-					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+					On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 					Return(&models.Metric{
 						Value: nil,
 						Delta: &delta,
@@ -242,7 +246,7 @@ func Test_metricsManager_GetMetric(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			mm := NewMetricsManager(test.mockStore())
-			got, err := mm.GetMetric(test.args.ctx, test.args.Type, test.args.Name)
+			got, err := mm.GetMetric(test.args.ctx, test.args.Type, test.args.Name, nil)
 			if len(test.want.errMsg) > 0 {
 				require.Errorf(t, err, test.want.errMsg)
 			}
@@ -252,6 +256,7 @@ func Test_metricsManager_GetMetric(t *testing.T) {
 }
 
 func Test_metricsManager_UpdateMetric(t *testing.T) {
+	now := time.Date(2024, 4, 11, 14, 0, 0, 0, time.UTC)
 	d := int64(100)
 	v := float64(100.1)
 	type fields struct {
@@ -276,7 +281,7 @@ func Test_metricsManager_UpdateMetric(t *testing.T) {
 				mockStore: func() store.Store {
 					mockStore := mocks.NewStore(t)
 					mockStore.
-						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 						Return(nil, errors.New("something wrong"))
 					return mockStore
 				},
@@ -295,7 +300,7 @@ func Test_metricsManager_UpdateMetric(t *testing.T) {
 				mockStore: func() store.Store {
 					mockStore := mocks.NewStore(t)
 					mockStore.
-						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 						Return(nil, nil)
 
 					return mockStore
@@ -315,7 +320,7 @@ func Test_metricsManager_UpdateMetric(t *testing.T) {
 				mockStore: func() store.Store {
 					mockStore := mocks.NewStore(t)
 					mockStore.
-						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 						Return(nil, nil)
 					mockStore.
 						On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
@@ -340,7 +345,7 @@ func Test_metricsManager_UpdateMetric(t *testing.T) {
 				mockStore: func() store.Store {
 					mockStore := mocks.NewStore(t)
 					mockStore.
-						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 						Return(nil, nil).
 						On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
 						Return(nil)
@@ -365,7 +370,7 @@ func Test_metricsManager_UpdateMetric(t *testing.T) {
 				mockStore: func() store.Store {
 					mockStore := mocks.NewStore(t)
 					mockStore.
-						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 						Return(&models.Metric{
 							Value: nil,
 							Delta: &d,
@@ -396,7 +401,7 @@ func Test_metricsManager_UpdateMetric(t *testing.T) {
 				mockStore: func() store.Store {
 					mockStore := mocks.NewStore(t)
 					mockStore.
-						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 						Return(nil, nil).
 						On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
 						Return(nil)
@@ -415,11 +420,263 @@ func Test_metricsManager_UpdateMetric(t *testing.T) {
 				err: nil,
 			},
 		},
+		{
+			name: "Update counter metric sets update timestamp",
+			fields: fields{
+				mockStore: func() store.Store {
+					ts := time.Date(2024, 4, 11, 13, 52, 24, 0, time.UTC)
+					mockStore := mocks.NewStore(t)
+					mockStore.
+						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
+						Return(&models.Metric{
+							Delta:     &d,
+							Timestamp: &ts,
+							MType:     models.CounterType,
+							ID:        "metric 1",
+						}, nil).
+						On("UpdateMetrics", mock.Anything, mock.MatchedBy(func(metrics models.MetricsList) bool {
+							return len(metrics) == 1 && metrics[0].Timestamp.Equal(now)
+						})).
+						Return(nil)
+
+					return mockStore
+				},
+			},
+			args: args{
+				ctx: context.Background(),
+				metric: models.Metric{
+					ID:    "metric 1",
+					MType: models.CounterType,
+					Delta: &d,
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		{
+			name: "invalid histogram",
+			fields: fields{
+				mockStore: func() store.Store {
+					mockStore := mocks.NewStore(t)
+					mockStore.
+						On("GetMetric", mock.Anything, models.HistogramType, "latency", models.Labels(nil)).
+						Return(nil, nil)
+
+					return mockStore
+				},
+			},
+			args: args{
+				ctx: context.Background(),
+				metric: models.Metric{
+					ID:    "latency",
+					MType: models.HistogramType,
+				},
+			},
+			want: want{
+				err: fmt.Errorf("%w: histogram is missing", models.ErrInvalidHistogram),
+			},
+		},
+		{
+			name: "Update histogram metric adds observations",
+			fields: fields{
+				mockStore: func() store.Store {
+					mockStore := mocks.NewStore(t)
+					mockStore.
+						On("GetMetric", mock.Anything, models.HistogramType, "latency", models.Labels(nil)).
+						Return(&models.Metric{
+							Histogram: &models.Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{1, 0, 0}, Sum: 0.05},
+							MType:     models.HistogramType,
+							ID:        "latency",
+						}, nil).
+						On("UpdateMetrics", mock.Anything, mock.MatchedBy(func(metrics models.MetricsList) bool {
+							return len(metrics) == 1 && assert.Equal(t, &models.Histogram{
+								Bounds: []float64{0.1, 1},
+								Counts: []uint64{1, 2, 1},
+								Sum:    3.05,
+							}, metrics[0].Histogram)
+						})).
+						Return(nil)
+
+					return mockStore
+				},
+			},
+			args: args{
+				ctx: context.Background(),
+				metric: models.Metric{
+					Histogram: &models.Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{0, 2, 1}, Sum: 3},
+					ID:        "latency",
+					MType:     models.HistogramType,
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		{
+			name: "Update histogram metric with other bounds replaces buckets",
+			fields: fields{
+				mockStore: func() store.Store {
+					mockStore := mocks.NewStore(t)
+					mockStore.
+						On("GetMetric", mock.Anything, models.HistogramType, "latency", models.Labels(nil)).
+						Return(&models.Metric{
+							Histogram: &models.Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{1, 0, 0}, Sum: 0.05},
+							MType:     models.HistogramType,
+							ID:        "latency",
+						}, nil).
+						On("UpdateMetrics", mock.Anything, mock.MatchedBy(func(metrics models.MetricsList) bool {
+							return len(metrics) == 1 && assert.Equal(t, &models.Histogram{
+								Bounds: []float64{0.5},
+								Counts: []uint64{2, 0},
+								Sum:    0.4,
+							}, metrics[0].Histogram)
+						})).
+						Return(nil)
+
+					return mockStore
+				},
+			},
+			args: args{
+				ctx: context.Background(),
+				metric: models.Metric{
+					Histogram: &models.Histogram{Bounds: []float64{0.5}, Counts: []uint64{2, 0}, Sum: 0.4},
+					ID:        "latency",
+					MType:     models.HistogramType,
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		{
+			name: "invalid summary",
+			fields: fields{
+				mockStore: func() store.Store {
+					mockStore := mocks.NewStore(t)
+					mockStore.
+						On("GetMetric", mock.Anything, models.SummaryType, "latency", models.Labels(nil)).
+						Return(nil, nil)
+
+					return mockStore
+				},
+			},
+			args: args{
+				ctx: context.Background(),
+				metric: models.Metric{
+					Summary: &models.Summary{Quantiles: []models.Quantile{{Quantile: 99, Value: 0.3}}},
+					ID:      "latency",
+					MType:   models.SummaryType,
+				},
+			},
+			want: want{
+				err: fmt.Errorf("%w: quantile 99 is out of range [0, 1]", models.ErrInvalidSummary),
+			},
+		},
+		{
+			name: "Update summary metric replaces quantiles",
+			fields: fields{
+				mockStore: func() store.Store {
+					mockStore := mocks.NewStore(t)
+					mockStore.
+						On("GetMetric", mock.Anything, models.SummaryType, "latency", models.Labels(nil)).
+						Return(&models.Metric{
+							Summary: &models.Summary{Quantiles: []models.Quantile{{Quantile: 0.5, Value: 0.1}}, Count: 10, Sum: 1},
+							MType:   models.SummaryType,
+							ID:      "latency",
+						}, nil).
+						On("UpdateMetrics", mock.Anything, mock.MatchedBy(func(metrics models.MetricsList) bool {
+							return len(metrics) == 1 && assert.Equal(t, &models.Summary{
+								Quantiles: []models.Quantile{{Quantile: 0.5, Value: 0.2}, {Quantile: 0.99, Value: 0.3}},
+								Count:     12,
+								Sum:       2.5,
+							}, metrics[0].Summary)
+						})).
+						Return(nil)
+
+					return mockStore
+				},
+			},
+			args: args{
+				ctx: context.Background(),
+				metric: models.Metric{
+					Summary: &models.Summary{
+						Quantiles: []models.Quantile{{Quantile: 0.5, Value: 0.2}, {Quantile: 0.99, Value: 0.3}},
+						Count:     12,
+						Sum:       2.5,
+					},
+					ID:    "latency",
+					MType: models.SummaryType,
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		{
+			name: "Update metric with labels",
+			fields: fields{
+				mockStore: func() store.Store {
+					mockStore := mocks.NewStore(t)
+					mockStore.
+						On("GetMetric", mock.Anything, models.GaugeType, "CPUutilization", models.Labels{"cpu": "3"}).
+						Return(nil, nil).
+						On("UpdateMetrics", mock.Anything, mock.MatchedBy(func(metrics models.MetricsList) bool {
+							return len(metrics) == 1 && metrics[0].Labels["cpu"] == "3"
+						})).
+						Return(nil)
+
+					return mockStore
+				},
+			},
+			args: args{
+				ctx: context.Background(),
+				metric: models.Metric{
+					ID:     "CPUutilization",
+					Labels: models.Labels{"cpu": "3"},
+					MType:  models.GaugeType,
+					Value:  &v,
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		{
+			name: "Update metric records authenticated agent",
+			fields: fields{
+				mockStore: func() store.Store {
+					mockStore := mocks.NewStore(t)
+					mockStore.
+						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
+						Return(nil, nil).
+						On("UpdateMetrics", mock.Anything, mock.MatchedBy(func(metrics models.MetricsList) bool {
+							return len(metrics) == 1 && metrics[0].AgentID == "web-1"
+						})).
+						Return(nil)
+
+					return mockStore
+				},
+			},
+			args: args{
+				ctx: identity.WithAgentID(context.Background(), "web-1"),
+				metric: models.Metric{
+					ID:      "metric 1",
+					MType:   models.GaugeType,
+					Value:   &v,
+					AgentID: "spoofed",
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			mm := &metricsManager{
 				store: test.fields.mockStore(),
+				now:   func() time.Time { return now },
 			}
 			err := mm.UpdateMetric(test.args.ctx, test.args.metric)
 			assert.Equal(t, test.want.err, err)
@@ -428,6 +685,7 @@ func Test_metricsManager_UpdateMetric(t *testing.T) {
 }
 
 func Test_metricsManager_UpdateMetrics(t *testing.T) {
+	now := time.Date(2024, 4, 11, 14, 0, 0, 0, time.UTC)
 	d := int64(100)
 	v := float64(100.1)
 	type fields struct {
@@ -469,7 +727,7 @@ func Test_metricsManager_UpdateMetrics(t *testing.T) {
 				mockStore: func() store.Store {
 					mockStore := mocks.NewStore(t)
 					mockStore.
-						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 						Return(nil, nil).
 						On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
 						Return(nil)
@@ -498,13 +756,64 @@ func Test_metricsManager_UpdateMetrics(t *testing.T) {
 				err: nil,
 			},
 		},
+		{
+			name: "Last seen time is set by server clock, history is recorded at sample time",
+			fields: fields{
+				mockStore: func() store.Store {
+					mockStore := mocks.NewStore(t)
+					mockStore.
+						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
+						Return(nil, nil).
+						On("UpdateMetrics", mock.Anything, mock.MatchedBy(func(metrics models.MetricsList) bool {
+							for _, metric := range metrics {
+								if !metric.LastSeen.Equal(now) {
+									return false
+								}
+							}
+							return len(metrics) == 3 &&
+								metrics[0].Timestamp.Equal(now) &&
+								metrics[1].Timestamp.Equal(time.Date(2024, 4, 11, 13, 52, 24, 0, time.UTC)) &&
+								metrics[2].Timestamp.Equal(now)
+						})).
+						Return(nil)
+
+					return mockStore
+				},
+			},
+			args: args{
+				ctx: context.Background(),
+				metrics: models.MetricsList{
+					&models.Metric{
+						Delta: &d,
+						MType: models.CounterType,
+						ID:    "metric 1",
+					},
+					&models.Metric{
+						Value:     &v,
+						Timestamp: func() *time.Time { ts := time.Date(2024, 4, 11, 13, 52, 24, 0, time.UTC); return &ts }(),
+						LastSeen:  func() *time.Time { ts := time.Date(2024, 4, 11, 13, 52, 24, 0, time.UTC); return &ts }(),
+						MType:     models.GaugeType,
+						ID:        "metric 2",
+					},
+					&models.Metric{
+						Value:     &v,
+						Timestamp: func() *time.Time { ts := now.Add(time.Hour); return &ts }(),
+						MType:     models.GaugeType,
+						ID:        "metric 3",
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
 		{
 			name: "GetMetric failed",
 			fields: fields{
 				mockStore: func() store.Store {
 					mockStore := mocks.NewStore(t)
 					mockStore.
-						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 						Return(nil, errors.New("something wrong"))
 
 					return mockStore
@@ -537,7 +846,7 @@ func Test_metricsManager_UpdateMetrics(t *testing.T) {
 				mockStore: func() store.Store {
 					mockStore := mocks.NewStore(t)
 					mockStore.
-						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+						On("GetMetric", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("models.Labels")).
 						Return(nil, nil).
 						On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
 						Return(errors.New("something wrong"))
@@ -571,9 +880,239 @@ func Test_metricsManager_UpdateMetrics(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			mm := &metricsManager{
 				store: test.fields.mockStore(),
+				now:   func() time.Time { return now },
 			}
 			err := mm.UpdateMetrics(test.args.ctx, test.args.metrics)
 			assert.Equal(t, test.want.err, err)
 		})
 	}
 }
+
+func Test_metricsManager_UpdateMetrics_keepsList(t *testing.T) {
+	stored := int64(10)
+	delta := int64(5)
+	metric := &models.Metric{Delta: &delta, MType: models.CounterType, ID: "requests"}
+	metrics := models.MetricsList{metric}
+
+	mockStore := mocks.NewStore(t)
+	mockStore.
+		On("GetMetric", mock.Anything, models.CounterType, "requests", models.Labels(nil)).
+		Return(&models.Metric{Delta: &stored, MType: models.CounterType, ID: "requests"}, nil)
+	mockStore.
+		On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
+		Return(errors.New("something wrong"))
+
+	mm := NewMetricsManager(mockStore)
+	err := mm.UpdateMetrics(context.Background(), metrics)
+	require.Error(t, err)
+
+	// The failed update can be retried with the same list, it still contains the passed delta.
+	assert.Same(t, metric, metrics[0])
+	assert.Equal(t, int64(5), *metrics[0].Delta)
+	assert.Equal(t, int64(10), stored)
+}
+
+func Test_metricsManager_UpdateMetricsFunc(t *testing.T) {
+	now := time.Date(2024, 4, 11, 14, 0, 0, 0, time.UTC)
+	stored := int64(10)
+	total := int64(15)
+
+	type want struct {
+		err     error
+		metrics models.MetricsList
+	}
+	tests := []struct {
+		mockStore func() *mocks.Store
+		fn        func(ctx context.Context, mm Manager) (models.MetricsList, error)
+		name      string
+		want      want
+	}{
+		{
+			name: "fn failed",
+			mockStore: func() *mocks.Store {
+				return mocks.NewStore(t)
+			},
+			fn: func(ctx context.Context, mm Manager) (models.MetricsList, error) {
+				return nil, errors.New("something wrong")
+			},
+			want: want{
+				err: errors.New("something wrong"),
+			},
+		},
+		{
+			name: "Cumulative counter is converted to delta (successfully case)",
+			mockStore: func() *mocks.Store {
+				mockStore := mocks.NewStore(t)
+				mockStore.
+					On("GetMetric", mock.Anything, models.CounterType, "requests", models.Labels(nil)).
+					Return(&models.Metric{Delta: &stored, MType: models.CounterType, ID: "requests"}, nil)
+				mockStore.
+					On("UpdateMetrics", mock.Anything, mock.AnythingOfType("models.MetricsList")).
+					Return(nil)
+
+				return mockStore
+			},
+			fn: func(ctx context.Context, mm Manager) (models.MetricsList, error) {
+				current, err := mm.GetMetric(ctx, models.CounterType, "requests", nil)
+				if err != nil {
+					return nil, err
+				}
+
+				delta := total - *current.Delta
+				return models.MetricsList{{Delta: &delta, MType: models.CounterType, ID: "requests"}}, nil
+			},
+			want: want{
+				metrics: models.MetricsList{{Delta: &total, MType: models.CounterType, ID: "requests", Timestamp: &now, LastSeen: &now}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockStore := test.mockStore()
+			mm := &metricsManager{
+				store: mockStore,
+				now:   func() time.Time { return now },
+			}
+			err := mm.UpdateMetricsFunc(context.Background(), func(ctx context.Context) (models.MetricsList, error) {
+				return test.fn(ctx, mm)
+			})
+			assert.Equal(t, test.want.err, err)
+			if test.want.metrics != nil {
+				mockStore.AssertCalled(t, "UpdateMetrics", mock.Anything, test.want.metrics)
+			}
+		})
+	}
+}
+
+func Test_metricsManager_QueryMetric(t *testing.T) {
+	from := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	ts := from.Add(time.Minute)
+	v := float64(100.1)
+	type args struct {
+		ctx    context.Context
+		from   time.Time
+		to     time.Time
+		labels models.Labels
+		mType  models.MetricType
+		mName  models.MetricName
+		step   time.Duration
+	}
+	type want struct {
+		err    error
+		result []models.Bucket
+	}
+	tests := []struct {
+		name      string
+		args      args
+		mockStore func() store.Store
+		want      want
+	}{
+		{
+			name: "unknown metric type",
+			args: args{
+				ctx:   context.Background(),
+				mType: "unknown",
+				mName: "metric1",
+				from:  from,
+				to:    to,
+				step:  time.Minute,
+			},
+			mockStore: func() store.Store {
+				return mocks.NewStore(t)
+			},
+			want: want{
+				err: storage.ErrUnknownMetricType,
+			},
+		},
+		{
+			name: "invalid time range",
+			args: args{
+				ctx:   context.Background(),
+				mType: models.GaugeType,
+				mName: "metric1",
+				from:  to,
+				to:    from,
+				step:  time.Minute,
+			},
+			mockStore: func() store.Store {
+				return mocks.NewStore(t)
+			},
+			want: want{
+				err: ErrInvalidTimeRange,
+			},
+		},
+		{
+			name: "too small step",
+			args: args{
+				ctx:   context.Background(),
+				mType: models.GaugeType,
+				mName: "metric1",
+				from:  from,
+				to:    to,
+				step:  time.Millisecond,
+			},
+			mockStore: func() store.Store {
+				return mocks.NewStore(t)
+			},
+			want: want{
+				err: ErrInvalidStep,
+			},
+		},
+		{
+			name: "something wrong",
+			args: args{
+				ctx:   context.Background(),
+				mType: models.GaugeType,
+				mName: "metric1",
+				from:  from,
+				to:    to,
+				step:  time.Minute,
+			},
+			mockStore: func() store.Store {
+				mockStore := mocks.NewStore(t)
+				mockStore.
+					On("GetMetricHistory", mock.Anything, models.GaugeType, "metric1", models.Labels(nil), from.Add(-time.Minute), to).
+					Return(nil, errors.New("something wrong"))
+				return mockStore
+			},
+			want: want{
+				err: errors.New("something wrong"),
+			},
+		},
+		{
+			name: "successfully case",
+			args: args{
+				ctx:    context.Background(),
+				labels: models.Labels{"host": "web1"},
+				mType:  models.GaugeType,
+				mName:  "metric1",
+				from:   from,
+				to:     to,
+				step:   time.Minute,
+			},
+			mockStore: func() store.Store {
+				mockStore := mocks.NewStore(t)
+				mockStore.
+					On("GetMetricHistory", mock.Anything, models.GaugeType, "metric1", models.Labels{"host": "web1"}, from.Add(-time.Minute), to).
+					Return(&models.MetricsList{
+						{Value: &v, Timestamp: &ts, MType: models.GaugeType, ID: "metric1"},
+					}, nil)
+				return mockStore
+			},
+			want: want{
+				result: []models.Bucket{
+					{Start: ts, Min: v, Max: v, Avg: v, Sum: v, Last: v, Count: 1},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mm := NewMetricsManager(test.mockStore())
+			got, err := mm.QueryMetric(test.args.ctx, test.args.mType, test.args.mName, test.args.labels, test.args.from, test.args.to, test.args.step)
+			require.Equal(t, test.want.err, err)
+			assert.Equal(t, test.want.result, got)
+		})
+	}
+}
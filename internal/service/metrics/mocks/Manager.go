@@ -8,6 +8,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	models "github.com/e1m0re/grdn/internal/models"
+
+	time "time"
 )
 
 // Manager is an autogenerated mock type for the Manager type
@@ -45,9 +47,9 @@ func (_m *Manager) GetAllMetrics(ctx context.Context) (*models.MetricsList, erro
 	return r0, r1
 }
 
-// GetMetric provides a mock function with given fields: ctx, mType, mName
-func (_m *Manager) GetMetric(ctx context.Context, mType string, mName string) (*models.Metric, error) {
-	ret := _m.Called(ctx, mType, mName)
+// GetMetric provides a mock function with given fields: ctx, mType, mName, labels
+func (_m *Manager) GetMetric(ctx context.Context, mType string, mName string, labels models.Labels) (*models.Metric, error) {
+	ret := _m.Called(ctx, mType, mName, labels)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetMetric")
@@ -55,19 +57,79 @@ func (_m *Manager) GetMetric(ctx context.Context, mType string, mName string) (*
 
 	var r0 *models.Metric
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.Metric, error)); ok {
-		return rf(ctx, mType, mName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Labels) (*models.Metric, error)); ok {
+		return rf(ctx, mType, mName, labels)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.Metric); ok {
-		r0 = rf(ctx, mType, mName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Labels) *models.Metric); ok {
+		r0 = rf(ctx, mType, mName, labels)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Metric)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, mType, mName)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, models.Labels) error); ok {
+		r1 = rf(ctx, mType, mName, labels)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMetricHistory provides a mock function with given fields: ctx, mType, mName, labels, from, to
+func (_m *Manager) GetMetricHistory(ctx context.Context, mType string, mName string, labels models.Labels, from time.Time, to time.Time) (*models.MetricsList, error) {
+	ret := _m.Called(ctx, mType, mName, labels, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMetricHistory")
+	}
+
+	var r0 *models.MetricsList
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Labels, time.Time, time.Time) (*models.MetricsList, error)); ok {
+		return rf(ctx, mType, mName, labels, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Labels, time.Time, time.Time) *models.MetricsList); ok {
+		r0 = rf(ctx, mType, mName, labels, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.MetricsList)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, models.Labels, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, mType, mName, labels, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueryMetric provides a mock function with given fields: ctx, mType, mName, labels, from, to, step
+func (_m *Manager) QueryMetric(ctx context.Context, mType string, mName string, labels models.Labels, from time.Time, to time.Time, step time.Duration) ([]models.Bucket, error) {
+	ret := _m.Called(ctx, mType, mName, labels, from, to, step)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryMetric")
+	}
+
+	var r0 []models.Bucket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Labels, time.Time, time.Time, time.Duration) ([]models.Bucket, error)); ok {
+		return rf(ctx, mType, mName, labels, from, to, step)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Labels, time.Time, time.Time, time.Duration) []models.Bucket); ok {
+		r0 = rf(ctx, mType, mName, labels, from, to, step)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Bucket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, models.Labels, time.Time, time.Time, time.Duration) error); ok {
+		r1 = rf(ctx, mType, mName, labels, from, to, step)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -111,6 +173,24 @@ func (_m *Manager) UpdateMetrics(ctx context.Context, _a1 models.MetricsList) er
 	return r0
 }
 
+// UpdateMetricsFunc provides a mock function with given fields: ctx, fn
+func (_m *Manager) UpdateMetricsFunc(ctx context.Context, fn func(context.Context) (models.MetricsList, error)) error {
+	ret := _m.Called(ctx, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateMetricsFunc")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(context.Context) (models.MetricsList, error)) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewManager creates a new instance of Manager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewManager(t interface {
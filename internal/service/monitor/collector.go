@@ -0,0 +1,51 @@
+package monitor
+
+import (
+	"context"
+
+	"github.com/e1m0re/grdn/internal/agent/config"
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+// Collector is the interface of the metrics source polled by the monitor.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.43.1 --name=Collector
+type Collector interface {
+	// Name returns name of the collector.
+	Name() string
+	// Collect polls the source and returns current values of gauges and increments of counters.
+	Collect(ctx context.Context) (models.MetricsList, error)
+}
+
+// CollectorFactory creates the collector with specified settings.
+type CollectorFactory func(cfg config.CollectorConfig) (Collector, error)
+
+// collectorsRegistry contains factories of all supported collectors.
+var collectorsRegistry = map[string]CollectorFactory{
+	config.RuntimeCollector: newRuntimeCollector,
+	config.GOPSCollector:    newGOPSCollector,
+	config.DiskCollector:    newDiskCollector,
+	config.DiskIOCollector:  newDiskIOCollector,
+	config.NetworkCollector: newNetworkCollector,
+	config.LoadCollector:    newLoadCollector,
+	config.ExecCollector:    newExecCollector,
+}
+
+// counterTracker converts cumulative counters of the OS into increments since the previous poll.
+type counterTracker map[models.CounterName]uint64
+
+// delta returns increment of the counter since the previous poll. The first poll only remembers the value.
+// If the counter was reset, the current value is the increment.
+func (ct counterTracker) delta(name models.CounterName, value uint64) (models.CounterDateType, bool) {
+	prev, ok := ct[name]
+	ct[name] = value
+	if !ok {
+		return 0, false
+	}
+
+	if value < prev {
+		return models.CounterDateType(value), true
+	}
+
+	return models.CounterDateType(value - prev), true
+}
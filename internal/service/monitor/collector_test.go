@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+// fixtureContext returns context which makes gopsutil read the fixture proc directory instead of /proc.
+func fixtureContext(t *testing.T, procDir string) context.Context {
+	root, err := filepath.Abs("testdata")
+	require.NoError(t, err)
+
+	return context.WithValue(context.Background(), common.EnvKey, common.EnvMap{
+		common.HostProcEnvKey: filepath.Join(root, procDir),
+		common.HostSysEnvKey:  filepath.Join(root, "sys"),
+		common.HostDevEnvKey:  filepath.Join(root, "dev"),
+		common.HostRunEnvKey:  filepath.Join(root, "run"),
+	})
+}
+
+// metricsMap returns values of metrics by names.
+func metricsMap(metrics models.MetricsList) map[models.MetricName]float64 {
+	result := make(map[models.MetricName]float64, len(metrics))
+	for _, metric := range metrics {
+		result[metric.ID] = metric.FloatValue()
+	}
+
+	return result
+}
+
+func TestCounterTracker_delta(t *testing.T) {
+	type want struct {
+		delta models.CounterDateType
+		ok    bool
+	}
+	tests := []struct {
+		name  string
+		value uint64
+		want  want
+	}{
+		{name: "first value", value: 100, want: want{delta: 0, ok: false}},
+		{name: "increment", value: 150, want: want{delta: 50, ok: true}},
+		{name: "no changes", value: 150, want: want{delta: 0, ok: true}},
+		{name: "counter reset", value: 20, want: want{delta: 20, ok: true}},
+	}
+
+	ct := make(counterTracker)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			delta, ok := ct.delta("metric", test.value)
+			assert.Equal(t, test.want.delta, delta)
+			assert.Equal(t, test.want.ok, ok)
+		})
+	}
+}
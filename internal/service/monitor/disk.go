@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/shirou/gopsutil/v3/disk"
+
+	"github.com/e1m0re/grdn/internal/agent/config"
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+const (
+	diskTotalPrefix       = "DiskTotal_"
+	diskUsedPrefix        = "DiskUsed_"
+	diskFreePrefix        = "DiskFree_"
+	diskUsedPercentPrefix = "DiskUsedPercent_"
+)
+
+type diskCollector struct {
+	// usage returns usage statistics of the file system mounted at the path.
+	usage func(ctx context.Context, path string) (*disk.UsageStat, error)
+}
+
+func newDiskCollector(cfg config.CollectorConfig) (Collector, error) {
+	return &diskCollector{
+		usage: disk.UsageWithContext,
+	}, nil
+}
+
+// Name returns name of the collector.
+func (dc *diskCollector) Name() string {
+	return config.DiskCollector
+}
+
+// Collect returns usage of each mounted physical partition, e.g. DiskUsedPercent_/.
+func (dc *diskCollector) Collect(ctx context.Context) (models.MetricsList, error) {
+	partitions, err := disk.PartitionsWithContext(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(models.MetricsList, 0, 4*len(partitions))
+	for _, partition := range partitions {
+		usage, err := dc.usage(ctx, partition.Mountpoint)
+		if err != nil {
+			slog.Debug("error getting disk usage",
+				slog.String("mountpoint", partition.Mountpoint),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		result = append(result,
+			newGauge(diskTotalPrefix+partition.Mountpoint, models.GaugeDateType(usage.Total)),
+			newGauge(diskUsedPrefix+partition.Mountpoint, models.GaugeDateType(usage.Used)),
+			newGauge(diskFreePrefix+partition.Mountpoint, models.GaugeDateType(usage.Free)),
+			newGauge(diskUsedPercentPrefix+partition.Mountpoint, usage.UsedPercent),
+		)
+	}
+
+	return result, nil
+}
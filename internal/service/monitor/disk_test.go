@@ -0,0 +1,44 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/agent/config"
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+func TestDiskCollector_Collect(t *testing.T) {
+	collector, err := newDiskCollector(config.CollectorConfig{Enabled: true})
+	require.NoError(t, err)
+	assert.Equal(t, config.DiskCollector, collector.Name())
+
+	// Usage of the host file systems is replaced with the stub, /mnt/missing is not available.
+	collector.(*diskCollector).usage = func(ctx context.Context, path string) (*disk.UsageStat, error) {
+		if path != "/" {
+			return nil, errors.New("no such file or directory")
+		}
+
+		return &disk.UsageStat{Path: path, Total: 1000, Used: 250, Free: 750, UsedPercent: 25}, nil
+	}
+
+	metrics, err := collector.Collect(fixtureContext(t, "proc"))
+	require.NoError(t, err)
+
+	for _, metric := range metrics {
+		assert.Equal(t, models.GaugeType, metric.MType)
+	}
+
+	// Virtual file systems are skipped as well as mount points which are not available.
+	assert.Equal(t, map[models.MetricName]float64{
+		"DiskTotal_/":       1000,
+		"DiskUsed_/":        250,
+		"DiskFree_/":        750,
+		"DiskUsedPercent_/": 25,
+	}, metricsMap(metrics))
+}
@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"context"
+
+	"github.com/shirou/gopsutil/v3/disk"
+
+	"github.com/e1m0re/grdn/internal/agent/config"
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+const (
+	diskReadBytesPrefix  = "DiskReadBytes_"
+	diskWriteBytesPrefix = "DiskWriteBytes_"
+	diskReadCountPrefix  = "DiskReadCount_"
+	diskWriteCountPrefix = "DiskWriteCount_"
+)
+
+type diskIOCollector struct {
+	counters counterTracker
+}
+
+func newDiskIOCollector(cfg config.CollectorConfig) (Collector, error) {
+	return &diskIOCollector{
+		counters: make(counterTracker),
+	}, nil
+}
+
+// Name returns name of the collector.
+func (dc *diskIOCollector) Name() string {
+	return config.DiskIOCollector
+}
+
+// Collect returns increments of read and written bytes and operations of each block device, e.g. DiskReadBytes_sda.
+// The first call returns nothing, because increments are counted since the previous call.
+func (dc *diskIOCollector) Collect(ctx context.Context) (models.MetricsList, error) {
+	stats, err := disk.IOCountersWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(models.MetricsList, 0, 4*len(stats))
+	for name, stat := range stats {
+		values := map[models.CounterName]uint64{
+			diskReadBytesPrefix + name:  stat.ReadBytes,
+			diskWriteBytesPrefix + name: stat.WriteBytes,
+			diskReadCountPrefix + name:  stat.ReadCount,
+			diskWriteCountPrefix + name: stat.WriteCount,
+		}
+
+		for metricName, value := range values {
+			if delta, ok := dc.counters.delta(metricName, value); ok {
+				result = append(result, newCounter(metricName, delta))
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,38 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/agent/config"
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+func TestDiskIOCollector_Collect(t *testing.T) {
+	collector, err := newDiskIOCollector(config.CollectorConfig{Enabled: true})
+	require.NoError(t, err)
+	assert.Equal(t, config.DiskIOCollector, collector.Name())
+
+	metrics, err := collector.Collect(fixtureContext(t, "proc"))
+	require.NoError(t, err)
+	assert.Empty(t, metrics)
+
+	metrics, err = collector.Collect(fixtureContext(t, "proc_next"))
+	require.NoError(t, err)
+	for _, metric := range metrics {
+		assert.Equal(t, models.CounterType, metric.MType)
+	}
+
+	assert.Equal(t, map[models.MetricName]float64{
+		"DiskReadBytes_sda":   100 * 512,
+		"DiskWriteBytes_sda":  400 * 512,
+		"DiskReadCount_sda":   10,
+		"DiskWriteCount_sda":  20,
+		"DiskReadBytes_sda1":  0,
+		"DiskWriteBytes_sda1": 0,
+		"DiskReadCount_sda1":  5,
+		"DiskWriteCount_sda1": 0,
+	}, metricsMap(metrics))
+}
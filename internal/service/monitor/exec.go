@@ -0,0 +1,176 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/e1m0re/grdn/internal/agent/config"
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+const (
+	defaultExecTimeout = 10 * time.Second
+	execWaitDelay      = 100 * time.Millisecond
+	// maxExecOutput is the maximum size of the command output in bytes, the rest is discarded.
+	maxExecOutput = 1 << 20
+)
+
+type execCollector struct {
+	commands []string
+	timeout  time.Duration
+}
+
+func newExecCollector(cfg config.CollectorConfig) (Collector, error) {
+	if len(cfg.Commands) == 0 {
+		return nil, fmt.Errorf("no commands specified")
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultExecTimeout
+	}
+
+	return &execCollector{
+		commands: cfg.Commands,
+		timeout:  timeout,
+	}, nil
+}
+
+// Name returns name of the collector.
+func (ec *execCollector) Name() string {
+	return config.ExecCollector
+}
+
+// Collect runs all commands concurrently and returns metrics printed by them.
+// Each line of the output has format "<name> <type> <value>" like in the /update/{mType}/{mName}/{mValue} request.
+// Failed commands, outputs exceeding maxExecOutput and malformed lines are counted by the ExecCollectorErrors counter, it is reported only if there
+// are errors.
+func (ec *execCollector) Collect(ctx context.Context) (models.MetricsList, error) {
+	var (
+		wg     sync.WaitGroup
+		mx     sync.Mutex
+		errCnt models.CounterDateType
+	)
+
+	result := make(models.MetricsList, 0)
+	for _, command := range ec.commands {
+		wg.Add(1)
+		go func(command string) {
+			defer wg.Done()
+
+			metrics, errs := ec.run(ctx, command)
+			for _, err := range errs {
+				slog.Warn("exec collector error",
+					slog.String("command", command),
+					slog.String("error", err.Error()),
+				)
+			}
+
+			mx.Lock()
+			defer mx.Unlock()
+			result = append(result, metrics...)
+			errCnt += models.CounterDateType(len(errs))
+		}(command)
+	}
+	wg.Wait()
+
+	if errCnt > 0 {
+		result = append(result, newCounter(models.ExecCollectorErrors, errCnt))
+	}
+
+	return result, nil
+}
+
+// run executes the command with timeout and parses its output. Metrics of successfully parsed lines are returned
+// even if the command failed. Only complete lines of the first maxExecOutput bytes of the output are parsed.
+func (ec *execCollector) run(ctx context.Context, command string) (models.MetricsList, []error) {
+	ctx, cancel := context.WithTimeout(ctx, ec.timeout)
+	defer cancel()
+
+	reader, writer := io.Pipe()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = writer
+	// Children of the shell may keep stdout open after the shell is killed on timeout.
+	cmd.WaitDelay = execWaitDelay
+
+	done := make(chan error, 1)
+	go func() {
+		err := cmd.Run()
+		writer.Close()
+		done <- err
+	}()
+
+	errs := make([]error, 0)
+	output, err := io.ReadAll(io.LimitReader(reader, maxExecOutput+1))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(output) > maxExecOutput {
+		errs = append(errs, fmt.Errorf("output exceeds %d bytes", maxExecOutput))
+		output = output[:bytes.LastIndexByte(output[:maxExecOutput], '\n')+1]
+	}
+	// The rest of the output is discarded, so the command is not blocked on writing.
+	_, _ = io.Copy(io.Discard, reader)
+
+	if err = <-done; err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		errs = append(errs, err)
+	}
+
+	metrics, parseErrs := parseExecOutput(bytes.NewBuffer(output))
+
+	return metrics, append(errs, parseErrs...)
+}
+
+// parseExecOutput parses lines in format "<name> <type> <value>" skipping empty lines.
+func parseExecOutput(output *bytes.Buffer) (models.MetricsList, []error) {
+	metrics := make(models.MetricsList, 0)
+	errs := make([]error, 0)
+
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			errs = append(errs, fmt.Errorf("invalid line %q", line))
+			continue
+		}
+
+		metric := &models.Metric{
+			ID:    fields[0],
+			MType: fields[1],
+		}
+
+		if metric.MType != models.GaugeType && metric.MType != models.CounterType {
+			errs = append(errs, fmt.Errorf("invalid line %q: unknown metric type", line))
+			continue
+		}
+
+		if err := metric.ValueFromString(fields[2]); err != nil {
+			errs = append(errs, fmt.Errorf("invalid line %q: %w", line, err))
+			continue
+		}
+
+		metrics = append(metrics, metric)
+	}
+
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return metrics, errs
+}
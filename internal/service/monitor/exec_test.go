@@ -0,0 +1,102 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/agent/config"
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+func TestNewExecCollector(t *testing.T) {
+	_, err := newExecCollector(config.CollectorConfig{Enabled: true})
+	require.EqualError(t, err, "no commands specified")
+
+	collector, err := newExecCollector(config.CollectorConfig{Enabled: true, Commands: []string{"true"}})
+	require.NoError(t, err)
+	assert.Equal(t, config.ExecCollector, collector.Name())
+	assert.Equal(t, defaultExecTimeout, collector.(*execCollector).timeout)
+}
+
+func TestExecCollector_Collect(t *testing.T) {
+	tests := []struct {
+		want     map[models.MetricName]float64
+		name     string
+		commands []string
+	}{
+		{
+			name:     "Successfully case",
+			commands: []string{"printf 'Users gauge 5.5\\n\\nQueueSize counter 3\\n'", "echo Jobs counter 2"},
+			want: map[models.MetricName]float64{
+				"Users":     5.5,
+				"QueueSize": 3,
+				"Jobs":      2,
+			},
+		},
+		{
+			name: "Malformed lines",
+			commands: []string{
+				"printf 'Users gauge 5\\nUsers gauge\\nUsers histogram 1\\nQueueSize counter 1.5\\n'",
+			},
+			want: map[models.MetricName]float64{
+				"Users":                    5,
+				models.ExecCollectorErrors: 3,
+			},
+		},
+		{
+			name:     "Failed command",
+			commands: []string{"echo Users gauge 5; exit 1", "unknown-command-for-exec-collector"},
+			want: map[models.MetricName]float64{
+				"Users":                    5,
+				models.ExecCollectorErrors: 2,
+			},
+		},
+		{
+			name:     "Output exceeding limit",
+			commands: []string{"yes 'Users gauge 1' | head -c 2000000"},
+			want: map[models.MetricName]float64{
+				"Users":                    1,
+				models.ExecCollectorErrors: 1,
+			},
+		},
+		{
+			name:     "Timeout",
+			commands: []string{"sleep 5"},
+			want: map[models.MetricName]float64{
+				models.ExecCollectorErrors: 1,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			collector, err := newExecCollector(config.CollectorConfig{
+				Enabled:  true,
+				Commands: test.commands,
+				Timeout:  500 * time.Millisecond,
+			})
+			require.NoError(t, err)
+
+			metrics, err := collector.Collect(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, test.want, metricsMap(metrics))
+		})
+	}
+}
+
+func Test_parseExecOutput(t *testing.T) {
+	value := 5.5
+	delta := int64(3)
+
+	metrics, errs := parseExecOutput(bytes.NewBufferString("Users gauge 5.5\nbroken\nQueueSize counter 3\n"))
+	assert.Equal(t, models.MetricsList{
+		{ID: "Users", MType: models.GaugeType, Value: &value},
+		{ID: "QueueSize", MType: models.CounterType, Delta: &delta},
+	}, metrics)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], `invalid line "broken"`)
+}
@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/mem"
+
+	"github.com/e1m0re/grdn/internal/agent/config"
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+type gopsCollector struct{}
+
+func newGOPSCollector(cfg config.CollectorConfig) (Collector, error) {
+	return &gopsCollector{}, nil
+}
+
+// Name returns name of the collector.
+func (gc *gopsCollector) Name() string {
+	return config.GOPSCollector
+}
+
+// Collect returns host memory and utilization of each CPU.
+func (gc *gopsCollector) Collect(ctx context.Context) (models.MetricsList, error) {
+	result := make(models.MetricsList, 0)
+
+	memoryInfo, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result = append(result,
+		newGauge(models.TotalMemory, models.GaugeDateType(memoryInfo.Total)),
+		newGauge(models.FreeMemory, models.GaugeDateType(memoryInfo.Free)),
+	)
+
+	percents, err := cpu.PercentWithContext(ctx, 0, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, percent := range percents {
+		result = append(result, newGauge(fmt.Sprintf("CPUutilization%d", idx), percent))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,35 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/agent/config"
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+func TestGOPSCollector_Collect(t *testing.T) {
+	collector, err := newGOPSCollector(config.CollectorConfig{Enabled: true})
+	require.NoError(t, err)
+	assert.Equal(t, config.GOPSCollector, collector.Name())
+
+	metrics, err := collector.Collect(context.Background())
+	require.NoError(t, err)
+
+	gauges := make([]models.MetricName, 0)
+	for _, metric := range metrics {
+		assert.Equal(t, models.GaugeType, metric.MType)
+		gauges = append(gauges, metric.ID)
+	}
+
+	assert.Contains(t, gauges, models.TotalMemory)
+	assert.Contains(t, gauges, models.FreeMemory)
+	for i := 0; i < runtime.NumCPU(); i++ {
+		assert.Contains(t, gauges, fmt.Sprintf("CPUutilization%d", i))
+	}
+}
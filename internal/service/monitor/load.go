@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"context"
+
+	"github.com/shirou/gopsutil/v3/load"
+
+	"github.com/e1m0re/grdn/internal/agent/config"
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+type loadCollector struct{}
+
+func newLoadCollector(cfg config.CollectorConfig) (Collector, error) {
+	return &loadCollector{}, nil
+}
+
+// Name returns name of the collector.
+func (lc *loadCollector) Name() string {
+	return config.LoadCollector
+}
+
+// Collect returns load average and count of all, running and blocked processes.
+func (lc *loadCollector) Collect(ctx context.Context) (models.MetricsList, error) {
+	avg, err := load.AvgWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	misc, err := load.MiscWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.MetricsList{
+		newGauge(models.Load1, avg.Load1),
+		newGauge(models.Load5, avg.Load5),
+		newGauge(models.Load15, avg.Load15),
+		newGauge(models.ProcessCount, models.GaugeDateType(misc.ProcsTotal)),
+		newGauge(models.ProcessRunning, models.GaugeDateType(misc.ProcsRunning)),
+		newGauge(models.ProcessBlocked, models.GaugeDateType(misc.ProcsBlocked)),
+	}, nil
+}
@@ -0,0 +1,32 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/agent/config"
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+func TestLoadCollector_Collect(t *testing.T) {
+	collector, err := newLoadCollector(config.CollectorConfig{Enabled: true})
+	require.NoError(t, err)
+	assert.Equal(t, config.LoadCollector, collector.Name())
+
+	metrics, err := collector.Collect(fixtureContext(t, "proc"))
+	require.NoError(t, err)
+	for _, metric := range metrics {
+		assert.Equal(t, models.GaugeType, metric.MType)
+	}
+
+	assert.Equal(t, map[models.MetricName]float64{
+		models.Load1:          0.52,
+		models.Load5:          0.58,
+		models.Load15:         0.59,
+		models.ProcessCount:   3,
+		models.ProcessRunning: 2,
+		models.ProcessBlocked: 1,
+	}, metricsMap(metrics))
+}
@@ -34,17 +34,12 @@ func (_m *Monitor) GetMetricsList() models.MetricsList {
 	return r0
 }
 
-// UpdateData provides a mock function with given fields:
-func (_m *Monitor) UpdateData() {
-	_m.Called()
-}
-
-// UpdateGOPS provides a mock function with given fields: ctx
-func (_m *Monitor) UpdateGOPS(ctx context.Context) error {
+// Start provides a mock function with given fields: ctx
+func (_m *Monitor) Start(ctx context.Context) error {
 	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateGOPS")
+		panic("no return value specified for Start")
 	}
 
 	var r0 error
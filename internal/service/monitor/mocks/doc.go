@@ -1,2 +1,2 @@
-// Package mocks defines mocks for Monitor service.
+// Package mocks defines mocks for Monitor service and metrics collectors.
 package mocks
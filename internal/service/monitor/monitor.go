@@ -4,134 +4,193 @@ package monitor
 import (
 	"context"
 	"fmt"
-	"math/rand"
-	"runtime"
+	"log/slog"
+	"sort"
 	"sync"
+	"time"
 
-	"github.com/shirou/gopsutil/v3/cpu"
-	"github.com/shirou/gopsutil/v3/mem"
+	"golang.org/x/sync/errgroup"
 
+	"github.com/e1m0re/grdn/internal/agent/config"
 	"github.com/e1m0re/grdn/internal/models"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2.43.1 --name=Monitor
 type Monitor interface {
-	// UpdateData collects main metrics data and updates local state.
-	UpdateData()
-	// UpdateGOPS collects add metrics data and updates local state.
-	UpdateGOPS(ctx context.Context) error
-	// GetMetricsList returns all metrics.
+	// Start polls enabled collectors on their intervals until the context is done.
+	Start(ctx context.Context) error
+	// GetMetricsList returns all metrics. Counters and histograms contain increments since the previous call, they
+	// are reset.
 	GetMetricsList() models.MetricsList
 }
 
 type MetricsState struct {
-	Gauges   map[models.GaugeName]models.GaugeDateType
-	Counters map[models.CounterName]models.CounterDateType
+	Gauges     map[models.GaugeName]models.GaugeDateType
+	Counters   map[models.CounterName]models.CounterDateType
+	Histograms map[models.HistogramName]*models.Histogram
+}
+
+type scheduledCollector struct {
+	collector Collector
+	interval  time.Duration
 }
 
 type monitor struct {
-	data MetricsState
-	mx   sync.RWMutex
+	data       MetricsState
+	now        func() time.Time
+	collectors []scheduledCollector
+	mx         sync.RWMutex
 }
 
-// NewMonitor is MetricsMonitor constructor.
-func NewMonitor() Monitor {
+// NewMonitor is MetricsMonitor constructor. It creates collectors enabled in the configuration.
+func NewMonitor(cfg *config.Config) (Monitor, error) {
+	names := make([]string, 0, len(cfg.Collectors))
+	for name := range cfg.Collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	collectors := make([]scheduledCollector, 0, len(names))
+	for _, name := range names {
+		collectorConfig := cfg.Collectors[name]
+		if !collectorConfig.Enabled {
+			continue
+		}
+
+		factory, ok := collectorsRegistry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown collector %q", name)
+		}
+
+		collector, err := factory(collectorConfig)
+		if err != nil {
+			return nil, fmt.Errorf("collector %q: %w", name, err)
+		}
+
+		interval := collectorConfig.PollInterval
+		if interval <= 0 {
+			interval = cfg.PollInterval
+		}
+
+		collectors = append(collectors, scheduledCollector{
+			collector: collector,
+			interval:  interval,
+		})
+	}
+
 	return &monitor{
 		data: MetricsState{
-			Gauges:   make(map[models.GaugeName]models.GaugeDateType),
-			Counters: make(map[models.CounterName]models.CounterDateType),
+			Gauges:     make(map[models.GaugeName]models.GaugeDateType),
+			Counters:   make(map[models.CounterName]models.CounterDateType),
+			Histograms: make(map[models.HistogramName]*models.Histogram),
 		},
-	}
+		now:        time.Now,
+		collectors: collectors,
+	}, nil
 }
 
-// UpdateData collects main metrics data and updates local state.
-func (m *monitor) UpdateData() {
-	m.mx.Lock()
-	defer m.mx.Unlock()
+// Start polls enabled collectors on their intervals until the context is done.
+func (m *monitor) Start(ctx context.Context) error {
+	grp, ctx := errgroup.WithContext(ctx)
+
+	for _, sc := range m.collectors {
+		sc := sc
+		grp.Go(func() error {
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(sc.interval):
+					err := m.collect(ctx, sc.collector)
+					if err != nil {
+						slog.Error("error collect metrics data",
+							slog.String("collector", sc.collector.Name()),
+							slog.String("error", err.Error()),
+						)
+					}
+				}
+			}
+		})
+	}
 
-	m.data.Counters[models.PollCount]++
-
-	r := rand.New(rand.NewSource(m.data.Counters[models.PollCount]))
-	m.data.Gauges[models.RandomValue] = r.Float64()
-
-	// memory metrics
-	var rtm runtime.MemStats
-
-	runtime.ReadMemStats(&rtm)
-	m.data.Gauges[models.Alloc] = models.GaugeDateType(rtm.Alloc)
-	m.data.Gauges[models.BuckHashSys] = models.GaugeDateType(rtm.BuckHashSys)
-	m.data.Gauges[models.Frees] = models.GaugeDateType(rtm.Frees)
-	m.data.Gauges[models.GCCPUFraction] = rtm.GCCPUFraction
-	m.data.Gauges[models.GCSys] = models.GaugeDateType(rtm.GCSys)
-	m.data.Gauges[models.HeapAlloc] = models.GaugeDateType(rtm.HeapAlloc)
-	m.data.Gauges[models.HeapIdle] = models.GaugeDateType(rtm.HeapIdle)
-	m.data.Gauges[models.HeapInuse] = models.GaugeDateType(rtm.HeapInuse)
-	m.data.Gauges[models.HeapObjects] = models.GaugeDateType(rtm.HeapObjects)
-	m.data.Gauges[models.HeapReleased] = models.GaugeDateType(rtm.HeapReleased)
-	m.data.Gauges[models.HeapSys] = models.GaugeDateType(rtm.HeapSys)
-	m.data.Gauges[models.LastGC] = models.GaugeDateType(rtm.LastGC)
-	m.data.Gauges[models.Lookups] = models.GaugeDateType(rtm.Lookups)
-	m.data.Gauges[models.MCacheInuse] = models.GaugeDateType(rtm.MCacheInuse)
-	m.data.Gauges[models.MCacheSys] = models.GaugeDateType(rtm.MCacheSys)
-	m.data.Gauges[models.MSpanInuse] = models.GaugeDateType(rtm.MSpanInuse)
-	m.data.Gauges[models.MSpanSys] = models.GaugeDateType(rtm.MSpanSys)
-	m.data.Gauges[models.Mallocs] = models.GaugeDateType(rtm.Mallocs)
-	m.data.Gauges[models.NextGC] = models.GaugeDateType(rtm.NextGC)
-	m.data.Gauges[models.NumForcedGC] = models.GaugeDateType(rtm.NumForcedGC)
-	m.data.Gauges[models.NumGC] = models.GaugeDateType(rtm.NumGC)
-	m.data.Gauges[models.OtherSys] = models.GaugeDateType(rtm.OtherSys)
-	m.data.Gauges[models.StackInuse] = models.GaugeDateType(rtm.StackInuse)
-	m.data.Gauges[models.StackSys] = models.GaugeDateType(rtm.StackSys)
-	m.data.Gauges[models.PauseTotalNs] = models.GaugeDateType(rtm.PauseTotalNs)
-	m.data.Gauges[models.Sys] = models.GaugeDateType(rtm.Sys)
-	m.data.Gauges[models.TotalAlloc] = models.GaugeDateType(rtm.TotalAlloc)
+	return grp.Wait()
 }
 
-// UpdateGOPS collects add metrics data and updates local state.
-func (m *monitor) UpdateGOPS(ctx context.Context) error {
-	m.mx.Lock()
-	defer m.mx.Unlock()
-
-	memoryInfo, _ := mem.VirtualMemoryWithContext(ctx)
-
-	m.data.Gauges[models.TotalMemory] = models.GaugeDateType(memoryInfo.Total)
-	m.data.Gauges[models.FreeMemory] = models.GaugeDateType(memoryInfo.Free)
-
-	percents, err := cpu.PercentWithContext(ctx, 0, true)
+// collect polls the collector and updates local state: gauges are replaced, counters are incremented and
+// observations are added to histograms.
+func (m *monitor) collect(ctx context.Context, collector Collector) error {
+	metrics, err := collector.Collect(ctx)
 	if err != nil {
 		return err
 	}
 
-	for idx, percent := range percents {
-		m.data.Gauges[fmt.Sprintf("CPUutilization%d", idx)] = percent
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	for _, metric := range metrics {
+		switch {
+		case metric.MType == models.GaugeType && metric.Value != nil:
+			m.data.Gauges[metric.ID] = *metric.Value
+		case metric.MType == models.CounterType && metric.Delta != nil:
+			m.data.Counters[metric.ID] += *metric.Delta
+		case metric.MType == models.HistogramType && metric.Histogram != nil:
+			m.data.Histograms[metric.ID] = m.data.Histograms[metric.ID].Merge(metric.Histogram)
+		}
 	}
 
 	return nil
 }
 
-// GetMetricsList returns all metrics.
+// GetMetricsList returns all metrics stamped with the time of the call. Counters and histograms contain increments
+// since the previous call, they are reset, so the server adds them to the stored ones. The time is kept by reports
+// replayed from the spool, so the server records them at the time they were taken.
 func (m *monitor) GetMetricsList() models.MetricsList {
-	m.mx.RLock()
-	defer m.mx.RUnlock()
+	m.mx.Lock()
+	defer m.mx.Unlock()
 
 	result := make(models.MetricsList, 0)
 	for key, value := range m.data.Gauges {
-		x := value
-		result = append(result, &models.Metric{
-			ID:    key,
-			MType: models.GaugeType,
-			Value: &x,
-		})
+		result = append(result, newGauge(key, value))
 	}
 
 	for key, value := range m.data.Counters {
-		x := value
-		result = append(result, &models.Metric{
-			ID:    key,
-			MType: models.CounterType,
-			Delta: &x,
-		})
+		result = append(result, newCounter(key, value))
+	}
+	clear(m.data.Counters)
+
+	for key, value := range m.data.Histograms {
+		result = append(result, newHistogram(key, value))
+	}
+	clear(m.data.Histograms)
+
+	now := m.now()
+	for _, metric := range result {
+		metric.Timestamp = &now
 	}
 
 	return result
 }
+
+func newGauge(name models.GaugeName, value models.GaugeDateType) *models.Metric {
+	return &models.Metric{
+		ID:    name,
+		MType: models.GaugeType,
+		Value: &value,
+	}
+}
+
+func newCounter(name models.CounterName, delta models.CounterDateType) *models.Metric {
+	return &models.Metric{
+		ID:    name,
+		MType: models.CounterType,
+		Delta: &delta,
+	}
+}
+
+func newHistogram(name models.HistogramName, histogram *models.Histogram) *models.Metric {
+	return &models.Metric{
+		ID:        name,
+		MType:     models.HistogramType,
+		Histogram: histogram,
+	}
+}
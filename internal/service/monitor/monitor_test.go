@@ -2,18 +2,24 @@ package monitor
 
 import (
 	"context"
-	"fmt"
-	"runtime"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"github.com/e1m0re/grdn/internal/agent/config"
 	"github.com/e1m0re/grdn/internal/models"
+	"github.com/e1m0re/grdn/internal/service/monitor/mocks"
 )
 
 func TestMetricsMonitor_GetMetricsList(t *testing.T) {
 	v1 := 8.07
 	d1 := int64(1984)
+	h1 := &models.Histogram{Bounds: []float64{0.001}, Counts: []uint64{2, 1}, Sum: 0.0075}
+	now := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
 	type fields struct {
 		data MetricsState
 	}
@@ -28,6 +34,7 @@ func TestMetricsMonitor_GetMetricsList(t *testing.T) {
 				data: MetricsState{
 					make(map[models.GaugeName]models.GaugeDateType),
 					make(map[models.CounterName]models.CounterDateType),
+					make(map[models.HistogramName]*models.Histogram),
 				},
 			},
 			want: make(models.MetricsList, 0),
@@ -42,20 +49,31 @@ func TestMetricsMonitor_GetMetricsList(t *testing.T) {
 					Counters: map[models.CounterName]models.CounterDateType{
 						"metric2": d1,
 					},
+					Histograms: map[models.HistogramName]*models.Histogram{
+						"metric3": h1,
+					},
 				},
 			},
 			want: models.MetricsList{
 				&models.Metric{
-					ID:    "metric1",
-					MType: "gauge",
-					Delta: nil,
-					Value: &v1,
+					ID:        "metric1",
+					MType:     "gauge",
+					Delta:     nil,
+					Value:     &v1,
+					Timestamp: &now,
+				},
+				&models.Metric{
+					ID:        "metric2",
+					MType:     "counter",
+					Delta:     &d1,
+					Value:     nil,
+					Timestamp: &now,
 				},
 				&models.Metric{
-					ID:    "metric2",
-					MType: "counter",
-					Delta: &d1,
-					Value: nil,
+					ID:        "metric3",
+					MType:     "histogram",
+					Histogram: h1,
+					Timestamp: &now,
 				},
 			},
 		},
@@ -64,48 +82,84 @@ func TestMetricsMonitor_GetMetricsList(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			m := &monitor{
 				data: tt.fields.data,
+				now:  func() time.Time { return now },
 			}
 
 			result := m.GetMetricsList()
 
 			assert.Equal(t, tt.want, result)
+			assert.Empty(t, m.data.Counters)
+			assert.Empty(t, m.data.Histograms)
 		})
 	}
 }
 
-func TestMetricsMonitor_UpdateGOPS(t *testing.T) {
-	type fields struct {
-		data MetricsState
-	}
+func TestMetricsMonitor_collect(t *testing.T) {
+	v1 := 8.07
+	v2 := 9.01
+	d1 := int64(2)
+	h1 := &models.Histogram{Bounds: []float64{0.001}, Counts: []uint64{2, 1}, Sum: 0.0075}
+	h2 := &models.Histogram{Bounds: []float64{0.001}, Counts: []uint64{1, 1}, Sum: 0.005}
 	type args struct {
-		ctx context.Context
+		collector func() Collector
 	}
 	type want struct {
-		err          error
-		metricsNames []models.MetricName
+		err  error
+		data MetricsState
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   want
+		want want
+		args args
+		name string
 	}{
 		{
-			name: "Successfully case",
-			fields: fields{
+			name: "Collector failed",
+			args: args{
+				collector: func() Collector {
+					mockCollector := mocks.NewCollector(t)
+					mockCollector.
+						On("Collect", mock.Anything).
+						Return(nil, errors.New("something wrong"))
+
+					return mockCollector
+				},
+			},
+			want: want{
+				err: errors.New("something wrong"),
 				data: MetricsState{
-					Gauges:   make(map[models.GaugeName]models.GaugeDateType),
-					Counters: make(map[models.CounterName]models.CounterDateType),
+					Gauges:     map[models.GaugeName]models.GaugeDateType{"metric1": v1},
+					Counters:   map[models.CounterName]models.CounterDateType{"metric2": 1},
+					Histograms: map[models.HistogramName]*models.Histogram{"metric4": h1},
 				},
 			},
+		},
+		{
+			name: "Successfully case",
 			args: args{
-				ctx: context.Background(),
+				collector: func() Collector {
+					mockCollector := mocks.NewCollector(t)
+					mockCollector.
+						On("Collect", mock.Anything).
+						Return(models.MetricsList{
+							{ID: "metric1", MType: models.GaugeType, Value: &v2},
+							{ID: "metric2", MType: models.CounterType, Delta: &d1},
+							{ID: "metric3", MType: models.CounterType, Delta: &d1},
+							{ID: "metric4", MType: models.HistogramType, Histogram: h2},
+							{ID: "metric5", MType: models.HistogramType, Histogram: h2},
+						}, nil)
+
+					return mockCollector
+				},
 			},
 			want: want{
 				err: nil,
-				metricsNames: []models.MetricName{
-					models.TotalMemory,
-					models.FreeMemory,
+				data: MetricsState{
+					Gauges:   map[models.GaugeName]models.GaugeDateType{"metric1": v2},
+					Counters: map[models.CounterName]models.CounterDateType{"metric2": 3, "metric3": 2},
+					Histograms: map[models.HistogramName]*models.Histogram{
+						"metric4": {Bounds: []float64{0.001}, Counts: []uint64{3, 2}, Sum: 0.0125},
+						"metric5": h2,
+					},
 				},
 			},
 		},
@@ -113,84 +167,120 @@ func TestMetricsMonitor_UpdateGOPS(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			m := &monitor{
-				data: test.fields.data,
+				data: MetricsState{
+					Gauges:     map[models.GaugeName]models.GaugeDateType{"metric1": v1},
+					Counters:   map[models.CounterName]models.CounterDateType{"metric2": 1},
+					Histograms: map[models.HistogramName]*models.Histogram{"metric4": h1},
+				},
 			}
-			err := m.UpdateGOPS(test.args.ctx)
+			err := m.collect(context.Background(), test.args.collector())
 			assert.Equal(t, test.want.err, err)
-			fmt.Printf("------------------------ %v", m)
-			for _, name := range test.want.metricsNames {
-				assert.Contains(t, m.data.Gauges, name)
-			}
-			for i := 0; i < runtime.NumCPU(); i++ {
-				assert.Contains(t, m.data.Gauges, fmt.Sprintf("CPUutilization%d", i))
-			}
+			assert.Equal(t, test.want.data, m.data)
 		})
 	}
 }
 
-func TestMetricsMonitor_UpdateData(t *testing.T) {
-	type fields struct {
-		data MetricsState
+func TestMetricsMonitor_Start(t *testing.T) {
+	v1 := 8.07
+	now := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+	mockCollector := mocks.NewCollector(t)
+	mockCollector.
+		On("Collect", mock.Anything).
+		Return(models.MetricsList{{ID: "metric1", MType: models.GaugeType, Value: &v1}}, nil)
+	mockFailedCollector := mocks.NewCollector(t)
+	mockFailedCollector.
+		On("Collect", mock.Anything).
+		Return(nil, errors.New("something wrong"))
+	mockFailedCollector.
+		On("Name").
+		Return("failed")
+
+	m := &monitor{
+		data: MetricsState{
+			Gauges:   make(map[models.GaugeName]models.GaugeDateType),
+			Counters: make(map[models.CounterName]models.CounterDateType),
+		},
+		now: func() time.Time { return now },
+		collectors: []scheduledCollector{
+			{collector: mockCollector, interval: 10 * time.Millisecond},
+			{collector: mockFailedCollector, interval: 10 * time.Millisecond},
+		},
 	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	require.NoError(t, m.Start(ctx))
+	assert.Equal(t, models.MetricsList{{ID: "metric1", MType: models.GaugeType, Value: &v1, Timestamp: &now}}, m.GetMetricsList())
+}
+
+func TestNewMetricsMonitor(t *testing.T) {
 	type want struct {
-		metricsGaugeNames   []models.MetricName
-		metricsCounterNames []models.MetricName
+		errMsg     string
+		collectors []string
+		intervals  []time.Duration
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		want   want
+		name string
+		cfg  *config.Config
+		want want
 	}{
 		{
-			name: "Successfully case",
-			fields: fields{
-				data: MetricsState{
-					Gauges:   make(map[models.GaugeName]models.GaugeDateType),
-					Counters: make(map[models.CounterName]models.CounterDateType),
+			name: "No collectors",
+			cfg:  &config.Config{PollInterval: time.Second},
+			want: want{
+				collectors: []string{},
+				intervals:  []time.Duration{},
+			},
+		},
+		{
+			name: "Unknown collector",
+			cfg: &config.Config{
+				PollInterval: time.Second,
+				Collectors: map[string]config.CollectorConfig{
+					"unknown": {Enabled: true},
 				},
 			},
 			want: want{
-				metricsGaugeNames:   models.MetricsGaugeNamesList,
-				metricsCounterNames: models.MetricsCounterNamesList,
+				errMsg: `unknown collector "unknown"`,
 			},
 		},
-	}
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			m := &monitor{
-				data: test.fields.data,
-			}
-			m.UpdateData()
-			for _, name := range test.want.metricsGaugeNames {
-				assert.Contains(t, m.data.Gauges, name)
-			}
-			for _, name := range test.want.metricsCounterNames {
-				assert.Contains(t, m.data.Counters, name)
-			}
-		})
-	}
-}
-
-func TestNewMetricsMonitor(t *testing.T) {
-	tests := []struct {
-		want Monitor
-		name string
-	}{
 		{
 			name: "Successfully case",
-			want: &monitor{
-				data: MetricsState{
-					Gauges:   make(map[models.GaugeName]models.GaugeDateType),
-					Counters: make(map[models.CounterName]models.CounterDateType),
+			cfg: &config.Config{
+				PollInterval: time.Second,
+				Collectors: map[string]config.CollectorConfig{
+					config.RuntimeCollector: {Enabled: true, PollInterval: 5 * time.Second},
+					config.GOPSCollector:    {Enabled: true},
+					"unknown":               {Enabled: false},
 				},
 			},
+			want: want{
+				collectors: []string{config.GOPSCollector, config.RuntimeCollector},
+				intervals:  []time.Duration{time.Second, 5 * time.Second},
+			},
 		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			m := NewMonitor()
-			assert.Equalf(t, test.want, m, "NewMetricsMonitor()")
-			assert.Implements(t, (*Monitor)(nil), m)
+			got, err := NewMonitor(test.cfg)
+			if len(test.want.errMsg) > 0 {
+				require.EqualError(t, err, test.want.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Implements(t, (*Monitor)(nil), got)
+
+			m := got.(*monitor)
+			collectors := make([]string, 0)
+			intervals := make([]time.Duration, 0)
+			for _, sc := range m.collectors {
+				collectors = append(collectors, sc.collector.Name())
+				intervals = append(intervals, sc.interval)
+			}
+			assert.Equal(t, test.want.collectors, collectors)
+			assert.Equal(t, test.want.intervals, intervals)
+			assert.Empty(t, m.GetMetricsList())
 		})
 	}
 }
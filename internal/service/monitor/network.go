@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"context"
+
+	"github.com/shirou/gopsutil/v3/net"
+
+	"github.com/e1m0re/grdn/internal/agent/config"
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+const (
+	netBytesSentPrefix   = "NetBytesSent_"
+	netBytesRecvPrefix   = "NetBytesRecv_"
+	netPacketsSentPrefix = "NetPacketsSent_"
+	netPacketsRecvPrefix = "NetPacketsRecv_"
+)
+
+type networkCollector struct {
+	counters counterTracker
+}
+
+func newNetworkCollector(cfg config.CollectorConfig) (Collector, error) {
+	return &networkCollector{
+		counters: make(counterTracker),
+	}, nil
+}
+
+// Name returns name of the collector.
+func (nc *networkCollector) Name() string {
+	return config.NetworkCollector
+}
+
+// Collect returns increments of sent and received bytes and packets of each interface, e.g. NetBytesSent_eth0.
+// The first call returns nothing, because increments are counted since the previous call.
+func (nc *networkCollector) Collect(ctx context.Context) (models.MetricsList, error) {
+	stats, err := net.IOCountersWithContext(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(models.MetricsList, 0, 4*len(stats))
+	for _, stat := range stats {
+		values := map[models.CounterName]uint64{
+			netBytesSentPrefix + stat.Name:   stat.BytesSent,
+			netBytesRecvPrefix + stat.Name:   stat.BytesRecv,
+			netPacketsSentPrefix + stat.Name: stat.PacketsSent,
+			netPacketsRecvPrefix + stat.Name: stat.PacketsRecv,
+		}
+
+		for metricName, value := range values {
+			if delta, ok := nc.counters.delta(metricName, value); ok {
+				result = append(result, newCounter(metricName, delta))
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,39 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/agent/config"
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+func TestNetworkCollector_Collect(t *testing.T) {
+	collector, err := newNetworkCollector(config.CollectorConfig{Enabled: true})
+	require.NoError(t, err)
+	assert.Equal(t, config.NetworkCollector, collector.Name())
+
+	metrics, err := collector.Collect(fixtureContext(t, "proc"))
+	require.NoError(t, err)
+	assert.Empty(t, metrics)
+
+	metrics, err = collector.Collect(fixtureContext(t, "proc_next"))
+	require.NoError(t, err)
+	for _, metric := range metrics {
+		assert.Equal(t, models.CounterType, metric.MType)
+	}
+
+	// Received counters of eth0 were reset between polls.
+	assert.Equal(t, map[models.MetricName]float64{
+		"NetBytesSent_lo":     500,
+		"NetBytesRecv_lo":     500,
+		"NetPacketsSent_lo":   5,
+		"NetPacketsRecv_lo":   5,
+		"NetBytesSent_eth0":   100000,
+		"NetBytesRecv_eth0":   300,
+		"NetPacketsSent_eth0": 100,
+		"NetPacketsRecv_eth0": 3,
+	}, metricsMap(metrics))
+}
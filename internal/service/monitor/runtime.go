@@ -0,0 +1,102 @@
+package monitor
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"time"
+
+	"github.com/e1m0re/grdn/internal/agent/config"
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+// defaultGCPauseBuckets are upper bounds of buckets of the GC pause times histogram in seconds.
+var defaultGCPauseBuckets = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1}
+
+type runtimeCollector struct {
+	buckets []float64
+	// numGC is the number of GC cycles completed by the previous poll.
+	numGC uint32
+}
+
+func newRuntimeCollector(cfg config.CollectorConfig) (Collector, error) {
+	buckets := cfg.Buckets
+	if len(buckets) == 0 {
+		buckets = defaultGCPauseBuckets
+	}
+
+	if err := models.NewHistogram(buckets).Validate(); err != nil {
+		return nil, err
+	}
+
+	return &runtimeCollector{buckets: buckets}, nil
+}
+
+// Name returns name of the collector.
+func (rc *runtimeCollector) Name() string {
+	return config.RuntimeCollector
+}
+
+// Collect returns Go runtime memory statistics, random value, histogram of GC pauses since the previous poll and
+// increments poll counter.
+func (rc *runtimeCollector) Collect(ctx context.Context) (models.MetricsList, error) {
+	var rtm runtime.MemStats
+	runtime.ReadMemStats(&rtm)
+
+	gauges := map[models.GaugeName]models.GaugeDateType{
+		models.RandomValue:   rand.Float64(),
+		models.Alloc:         models.GaugeDateType(rtm.Alloc),
+		models.BuckHashSys:   models.GaugeDateType(rtm.BuckHashSys),
+		models.Frees:         models.GaugeDateType(rtm.Frees),
+		models.GCCPUFraction: rtm.GCCPUFraction,
+		models.GCSys:         models.GaugeDateType(rtm.GCSys),
+		models.HeapAlloc:     models.GaugeDateType(rtm.HeapAlloc),
+		models.HeapIdle:      models.GaugeDateType(rtm.HeapIdle),
+		models.HeapInuse:     models.GaugeDateType(rtm.HeapInuse),
+		models.HeapObjects:   models.GaugeDateType(rtm.HeapObjects),
+		models.HeapReleased:  models.GaugeDateType(rtm.HeapReleased),
+		models.HeapSys:       models.GaugeDateType(rtm.HeapSys),
+		models.LastGC:        models.GaugeDateType(rtm.LastGC),
+		models.Lookups:       models.GaugeDateType(rtm.Lookups),
+		models.MCacheInuse:   models.GaugeDateType(rtm.MCacheInuse),
+		models.MCacheSys:     models.GaugeDateType(rtm.MCacheSys),
+		models.MSpanInuse:    models.GaugeDateType(rtm.MSpanInuse),
+		models.MSpanSys:      models.GaugeDateType(rtm.MSpanSys),
+		models.Mallocs:       models.GaugeDateType(rtm.Mallocs),
+		models.NextGC:        models.GaugeDateType(rtm.NextGC),
+		models.NumForcedGC:   models.GaugeDateType(rtm.NumForcedGC),
+		models.NumGC:         models.GaugeDateType(rtm.NumGC),
+		models.OtherSys:      models.GaugeDateType(rtm.OtherSys),
+		models.StackInuse:    models.GaugeDateType(rtm.StackInuse),
+		models.StackSys:      models.GaugeDateType(rtm.StackSys),
+		models.PauseTotalNs:  models.GaugeDateType(rtm.PauseTotalNs),
+		models.Sys:           models.GaugeDateType(rtm.Sys),
+		models.TotalAlloc:    models.GaugeDateType(rtm.TotalAlloc),
+	}
+
+	result := make(models.MetricsList, 0, len(gauges)+2)
+	for name, value := range gauges {
+		result = append(result, newGauge(name, value))
+	}
+	result = append(result, newCounter(models.PollCount, 1))
+	result = append(result, newHistogram(models.GCPause, rc.gcPauses(&rtm)))
+
+	return result, nil
+}
+
+// gcPauses returns histogram of pauses of GC cycles completed since the previous poll. The runtime keeps only the last
+// 256 pauses, older ones are lost if more cycles are completed between polls.
+func (rc *runtimeCollector) gcPauses(rtm *runtime.MemStats) *models.Histogram {
+	pauses := models.NewHistogram(rc.buckets)
+	first := rc.numGC + 1
+	if rtm.NumGC-rc.numGC > 256 {
+		first = rtm.NumGC - 255
+	}
+	// The pause of the cycle N is at PauseNs[(N+255)%256].
+	for cycle := first; cycle <= rtm.NumGC; cycle++ {
+		pauses.Observe(time.Duration(rtm.PauseNs[(cycle+255)%256]).Seconds())
+	}
+	rc.numGC = rtm.NumGC
+
+	return pauses
+}
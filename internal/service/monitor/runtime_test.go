@@ -0,0 +1,66 @@
+package monitor
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/e1m0re/grdn/internal/agent/config"
+	"github.com/e1m0re/grdn/internal/models"
+)
+
+func TestRuntimeCollector_Collect(t *testing.T) {
+	collector, err := newRuntimeCollector(config.CollectorConfig{Enabled: true})
+	require.NoError(t, err)
+	assert.Equal(t, config.RuntimeCollector, collector.Name())
+
+	metrics, err := collector.Collect(context.Background())
+	require.NoError(t, err)
+
+	gauges := make([]models.MetricName, 0)
+	counters := make(map[models.MetricName]int64)
+	histograms := make(map[models.MetricName]*models.Histogram)
+	for _, metric := range metrics {
+		switch metric.MType {
+		case models.GaugeType:
+			gauges = append(gauges, metric.ID)
+		case models.CounterType:
+			counters[metric.ID] = *metric.Delta
+		case models.HistogramType:
+			histograms[metric.ID] = metric.Histogram
+		}
+	}
+
+	assert.ElementsMatch(t, models.MetricsGaugeNamesList, gauges)
+	assert.Equal(t, map[models.MetricName]int64{models.PollCount: 1}, counters)
+	require.Contains(t, histograms, models.GCPause)
+	assert.Equal(t, defaultGCPauseBuckets, histograms[models.GCPause].Bounds)
+}
+
+func TestRuntimeCollector_gcPauses(t *testing.T) {
+	collector, err := newRuntimeCollector(config.CollectorConfig{Enabled: true, Buckets: []float64{0.001}})
+	require.NoError(t, err)
+	rc := collector.(*runtimeCollector)
+
+	var rtm runtime.MemStats
+	rtm.NumGC = 2
+	rtm.PauseNs[0] = 500000
+	rtm.PauseNs[1] = 2000000
+	assert.Equal(t, &models.Histogram{Bounds: []float64{0.001}, Counts: []uint64{1, 1}, Sum: 0.0025}, rc.gcPauses(&rtm))
+
+	rtm.NumGC = 3
+	rtm.PauseNs[2] = 100000
+	assert.Equal(t, &models.Histogram{Bounds: []float64{0.001}, Counts: []uint64{1, 0}, Sum: 0.0001}, rc.gcPauses(&rtm))
+	assert.Equal(t, uint64(0), rc.gcPauses(&rtm).Count())
+
+	rtm.NumGC = 1000
+	assert.Equal(t, uint64(256), rc.gcPauses(&rtm).Count())
+}
+
+func TestNewRuntimeCollector(t *testing.T) {
+	_, err := newRuntimeCollector(config.CollectorConfig{Enabled: true, Buckets: []float64{0.01, 0.001}})
+	require.ErrorIs(t, err, models.ErrInvalidHistogram)
+}
@@ -1,27 +1,77 @@
 package service
 
 import (
+	"crypto/tls"
+	"fmt"
+
 	"github.com/e1m0re/grdn/internal/agent/config"
+	serverConfig "github.com/e1m0re/grdn/internal/server/config"
+	"github.com/e1m0re/grdn/internal/service/alerting"
 	"github.com/e1m0re/grdn/internal/service/apiclient"
+	"github.com/e1m0re/grdn/internal/service/certs"
 	"github.com/e1m0re/grdn/internal/service/encryption"
+	"github.com/e1m0re/grdn/internal/service/identity"
+	"github.com/e1m0re/grdn/internal/service/ingest"
 	"github.com/e1m0re/grdn/internal/service/metrics"
 	"github.com/e1m0re/grdn/internal/service/monitor"
+	"github.com/e1m0re/grdn/internal/service/spool"
 	"github.com/e1m0re/grdn/internal/service/storage"
 	"github.com/e1m0re/grdn/internal/storage/store"
 )
 
 // ServerServices is servers DI-container.
 type ServerServices struct {
+	// AgentsRegistry is nil if agents are not registered.
+	AgentsRegistry identity.Registry
+	// IngestBatcher is nil if listeners of third-party protocols are disabled.
+	IngestBatcher ingest.Batcher
+	// AlertsNotifier is nil if webhooks of alerts notifications are not configured.
+	AlertsNotifier alerting.Notifier
+	AlertsManager  alerting.Manager
 	MetricsManager metrics.Manager
 	StorageService storage.Service
 }
 
 // NewServerServices is ServerServices constructor.
-func NewServerServices(s store.Store) *ServerServices {
+func NewServerServices(cfg *serverConfig.Config, s store.Store) (*ServerServices, error) {
+	rules := make([]alerting.Rule, 0)
+	if len(cfg.AlertRulesFile) > 0 {
+		var err error
+		rules, err = alerting.LoadRules(cfg.AlertRulesFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var notifier alerting.Notifier
+	if len(cfg.WebhookURLs) > 0 {
+		notifier = alerting.NewWebhookNotifier(cfg.WebhookURLs, []byte(cfg.WebhookKey))
+	}
+
+	var registry identity.Registry
+	if len(cfg.AgentsFile) > 0 {
+		var err error
+		registry, err = identity.LoadRegistry(cfg.AgentsFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	metricsManager := metrics.NewMetricsManager(s)
+
+	var batcher ingest.Batcher
+	if len(cfg.StatsDAddr) > 0 || len(cfg.GraphiteAddr) > 0 {
+		batcher = ingest.NewBatcher(metricsManager, cfg.StatsDFlush)
+	}
+
 	return &ServerServices{
-		MetricsManager: metrics.NewMetricsManager(s),
+		AgentsRegistry: registry,
+		IngestBatcher:  batcher,
+		AlertsNotifier: notifier,
+		AlertsManager:  alerting.NewManager(metricsManager, rules, cfg.AlertInterval, cfg.ReportInterval, notifier),
+		MetricsManager: metricsManager,
 		StorageService: storage.NewService(s),
-	}
+	}, nil
 }
 
 // AgentServices is agents DI-container.
@@ -29,6 +79,8 @@ type AgentServices struct {
 	APIClient apiclient.APIClient
 	Monitor   monitor.Monitor
 	Encryptor encryption.Encryptor
+	// Spool is nil if the on-disk queue of payloads is disabled.
+	Spool spool.Spool
 }
 
 // NewAgentServices is AgentServices constructor.
@@ -42,9 +94,59 @@ func NewAgentServices(cfg *config.Config) (*AgentServices, error) {
 		}
 	}
 
+	m, err := monitor.NewMonitor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var sp spool.Spool
+	if len(cfg.SpoolDir) > 0 {
+		sp, err = spool.NewSpool(cfg.SpoolDir, cfg.SpoolMaxSize, cfg.SpoolMaxAge)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := newAPIClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return &AgentServices{
-		APIClient: apiclient.NewAPIClient("http://"+cfg.ServerAddr, []byte(cfg.Key)),
-		Monitor:   monitor.NewMonitor(),
+		APIClient: client,
+		Monitor:   m,
 		Encryptor: encr,
+		Spool:     sp,
 	}, nil
 }
+
+// newAPIClient returns client of the configured transport.
+func newAPIClient(cfg *config.Config) (apiclient.APIClient, error) {
+	var tlsConfig *tls.Config
+	if cfg.TLSEnabled() {
+		var err error
+		tlsConfig, err = certs.ClientConfig(cfg.TLSCAFile, cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch cfg.Transport {
+	case config.TransportHTTP, "":
+		baseURL := "http://" + cfg.ServerAddr
+		if tlsConfig != nil {
+			baseURL = "https://" + cfg.ServerAddr
+		}
+
+		return apiclient.NewAPIClient(baseURL, cfg.AgentID, cfg.Token, cfg.KeyID, []byte(cfg.Key), cfg.VerifyResponses, tlsConfig), nil
+	case config.TransportGRPC:
+		// Payloads are protected by TLS, the server doesn't sign gRPC responses.
+		if len(cfg.PublicKeyFile) > 0 || cfg.VerifyResponses {
+			return nil, fmt.Errorf("payload encryption and responses verification are not supported by %s transport", cfg.Transport)
+		}
+
+		return apiclient.NewGRPCClient(cfg.ServerAddr, cfg.AgentID, cfg.Token, cfg.KeyID, []byte(cfg.Key), tlsConfig)
+	default:
+		return nil, fmt.Errorf("unknown transport %q", cfg.Transport)
+	}
+}
@@ -0,0 +1,2 @@
+// Package signing implements HMAC-SHA256 signing of payloads with a set of rotated keys.
+package signing
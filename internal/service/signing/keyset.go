@@ -0,0 +1,105 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// HashHeader is the name of the header containing HMAC-SHA256 sum of the payload.
+	HashHeader = "HashSHA256"
+	// KeyIDHeader is the name of the header containing ID of the key used for the sum.
+	KeyIDHeader = "HashSHA256-KeyID"
+)
+
+// ErrUnknownKey is the error returned when the key with the specified ID is missing in the key set.
+var ErrUnknownKey = errors.New("unknown sign key")
+
+// KeySet contains active signing keys by their IDs. The key with empty ID is the legacy key used when ID is not
+// specified. Several keys are active during the rotation, so payloads signed with old and new keys are accepted.
+type KeySet map[string][]byte
+
+// Sum returns HMAC-SHA256 sum of the data encoded in base64.
+func Sum(key, data []byte) string {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// Sum returns sum of the data made with the key with the specified ID.
+func (ks KeySet) Sum(keyID string, data []byte) (string, error) {
+	key, ok := ks[keyID]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+
+	return Sum(key, data), nil
+}
+
+// Verify checks the sum of the data made with the key with the specified ID.
+func (ks KeySet) Verify(keyID string, data []byte, sum string) bool {
+	expected, err := ks.Sum(keyID, data)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal([]byte(expected), []byte(sum))
+}
+
+// Add adds keys of other set replacing keys with the same IDs.
+func (ks KeySet) Add(other KeySet) {
+	for id, key := range other {
+		ks[id] = key
+	}
+}
+
+// ParseKeys parses comma separated list of keys in format "<id>:<secret>". Secret can't contain commas.
+func ParseKeys(value string) (KeySet, error) {
+	result := make(KeySet)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		id, secret, ok := strings.Cut(item, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("invalid sign key %q: expected format is <id>:<secret>", item)
+		}
+
+		result[id] = []byte(secret)
+	}
+
+	return result, nil
+}
+
+// LoadKeys reads keys from JSON file containing object with keys IDs as names and secrets as values.
+func LoadKeys(path string) (KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]string)
+	if err = json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("invalid sign keys file %s: %w", path, err)
+	}
+
+	result := make(KeySet, len(keys))
+	for id, secret := range keys {
+		if id == "" || secret == "" {
+			return nil, fmt.Errorf("invalid sign keys file %s: empty key id or secret", path)
+		}
+
+		result[id] = []byte(secret)
+	}
+
+	return result, nil
+}
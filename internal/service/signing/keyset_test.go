@@ -0,0 +1,153 @@
+package signing
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySet_Verify(t *testing.T) {
+	keys := KeySet{
+		"":        []byte("secret key"),
+		"2024-04": []byte("old secret"),
+		"2024-05": []byte("new secret"),
+	}
+	data := []byte("request body")
+
+	type args struct {
+		keyID string
+		sum   string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "legacy key",
+			args: args{keyID: "", sum: "LtF60KMiLpS1xdCaUmFOtdvGucz6Y/T+MNI3vtciKHQ="},
+			want: true,
+		},
+		{
+			name: "old key during rotation",
+			args: args{keyID: "2024-04", sum: Sum([]byte("old secret"), data)},
+			want: true,
+		},
+		{
+			name: "new key during rotation",
+			args: args{keyID: "2024-05", sum: Sum([]byte("new secret"), data)},
+			want: true,
+		},
+		{
+			name: "sum made with other key",
+			args: args{keyID: "2024-05", sum: Sum([]byte("old secret"), data)},
+			want: false,
+		},
+		{
+			name: "unknown key",
+			args: args{keyID: "2024-03", sum: Sum([]byte("old secret"), data)},
+			want: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, keys.Verify(test.args.keyID, data, test.args.sum))
+		})
+	}
+
+	_, err := keys.Sum("2024-03", data)
+	require.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestParseKeys(t *testing.T) {
+	type want struct {
+		keys     KeySet
+		hasError bool
+	}
+	tests := []struct {
+		name  string
+		value string
+		want  want
+	}{
+		{
+			name:  "empty value",
+			value: "",
+			want:  want{keys: KeySet{}},
+		},
+		{
+			name:  "successfully case",
+			value: "2024-04:old secret, 2024-05:new:secret,",
+			want: want{keys: KeySet{
+				"2024-04": []byte("old secret"),
+				"2024-05": []byte("new:secret"),
+			}},
+		},
+		{
+			name:  "key without id",
+			value: "2024-04:old secret,secret",
+			want:  want{hasError: true},
+		},
+		{
+			name:  "empty secret",
+			value: "2024-04:",
+			want:  want{hasError: true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys, err := ParseKeys(test.value)
+			assert.Equal(t, test.want.hasError, err != nil)
+			assert.Equal(t, test.want.keys, keys)
+		})
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	type want struct {
+		keys     KeySet
+		hasError bool
+	}
+	tests := []struct {
+		name    string
+		content string
+		want    want
+	}{
+		{
+			name:    "successfully case",
+			content: `{"2024-04": "old secret", "2024-05": "new secret"}`,
+			want: want{keys: KeySet{
+				"2024-04": []byte("old secret"),
+				"2024-05": []byte("new secret"),
+			}},
+		},
+		{
+			name:    "invalid json",
+			content: `["old secret"]`,
+			want:    want{hasError: true},
+		},
+		{
+			name:    "empty secret",
+			content: `{"2024-04": ""}`,
+			want:    want{hasError: true},
+		},
+	}
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("keys%d.json", i))
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0600))
+
+			keys, err := LoadKeys(path)
+			assert.Equal(t, test.want.hasError, err != nil)
+			assert.Equal(t, test.want.keys, keys)
+		})
+	}
+
+	_, err := LoadKeys(filepath.Join(dir, "missing.json"))
+	require.Error(t, err)
+}
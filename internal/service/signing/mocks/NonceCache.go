@@ -0,0 +1,46 @@
+// Code generated by mockery v2.43.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// NonceCache is an autogenerated mock type for the NonceCache type
+type NonceCache struct {
+	mock.Mock
+}
+
+// Add provides a mock function with given fields: nonce, expiresAt
+func (_m *NonceCache) Add(nonce string, expiresAt time.Time) bool {
+	ret := _m.Called(nonce, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Add")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, time.Time) bool); ok {
+		r0 = rf(nonce, expiresAt)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// NewNonceCache creates a new instance of NonceCache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewNonceCache(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *NonceCache {
+	mock := &NonceCache{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
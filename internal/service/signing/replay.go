@@ -0,0 +1,178 @@
+package signing
+
+import (
+	"container/heap"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// TimestampHeader is the name of the header containing unix time of the request signing.
+	TimestampHeader = "HashSHA256-Timestamp"
+	// NonceHeader is the name of the header containing random nonce of the request.
+	NonceHeader = "HashSHA256-Nonce"
+
+	nonceSize = 16
+)
+
+// SignedPayload returns data covered by the sum of the request with timestamp and nonce.
+func SignedPayload(timestamp, nonce string, body []byte) []byte {
+	result := make([]byte, 0, len(timestamp)+len(nonce)+len(body)+2)
+	result = append(result, timestamp...)
+	result = append(result, '\n')
+	result = append(result, nonce...)
+	result = append(result, '\n')
+
+	return append(result, body...)
+}
+
+// NewNonce returns random nonce encoded in hex.
+func NewNonce() (string, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(nonce), nil
+}
+
+// FormatTimestamp formats time for the TimestampHeader.
+func FormatTimestamp(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+// ParseTimestamp parses value of the TimestampHeader.
+func ParseTimestamp(value string) (time.Time, error) {
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(sec, 0), nil
+}
+
+// CheckReplay checks that the timestamp is within the allowed skew and the nonce has not been seen yet.
+func CheckReplay(nonces NonceCache, timestamp, nonce string, maxSkew time.Duration) bool {
+	signedAt, err := ParseTimestamp(timestamp)
+	if err != nil {
+		slog.Warn("invalid request timestamp", slog.String("timestamp", timestamp))
+		return false
+	}
+
+	skew := time.Since(signedAt)
+	if skew > maxSkew || skew < -maxSkew {
+		slog.Warn("request timestamp is out of allowed skew", slog.String("skew", skew.String()))
+		return false
+	}
+
+	// The nonce is remembered while the request timestamp is within the allowed skew.
+	if !nonces.Add(nonce, signedAt.Add(maxSkew)) {
+		slog.Warn("replayed request or request over nonces cache limit is rejected", slog.String("nonce", nonce))
+		return false
+	}
+
+	return true
+}
+
+// NonceCache is the interface of the cache of nonces seen by the server.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.43.1 --name=NonceCache
+type NonceCache interface {
+	// Add remembers the nonce until the expiration time. Returns false if the nonce has been seen already or it can't
+	// be remembered.
+	Add(nonce string, expiresAt time.Time) bool
+}
+
+// nonceItem is the nonce remembered until the expiration time.
+type nonceItem struct {
+	expiresAt time.Time
+	nonce     string
+	index     int
+}
+
+// nonceHeap is the min-heap of nonces by expiration time.
+type nonceHeap []*nonceItem
+
+func (h nonceHeap) Len() int { return len(h) }
+
+func (h nonceHeap) Less(i, j int) bool { return h[i].expiresAt.Before(h[j].expiresAt) }
+
+func (h nonceHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *nonceHeap) Push(x any) {
+	item := x.(*nonceItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *nonceHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+
+	return item
+}
+
+type nonceCache struct {
+	nonces map[string]*nonceItem
+	now    func() time.Time
+	// expiry contains nonces ordered by expiration time to evict expired ones. Client clocks are skewed, so the order
+	// of addition is not the order of expiration.
+	expiry nonceHeap
+	size   int
+	mx     sync.Mutex
+}
+
+// NewNonceCache returns in-memory cache of nonces limited by size. Expired nonces are evicted, nonces which are not
+// expired yet are never evicted, so new nonces are rejected while the cache is full.
+func NewNonceCache(size int) NonceCache {
+	return &nonceCache{
+		nonces: make(map[string]*nonceItem, size),
+		expiry: make(nonceHeap, 0, size),
+		size:   size,
+		now:    time.Now,
+	}
+}
+
+// Add remembers the nonce until the expiration time. Returns false if the nonce has been seen already or the cache is
+// full of nonces which are not expired.
+func (nc *nonceCache) Add(nonce string, expiresAt time.Time) bool {
+	nc.mx.Lock()
+	defer nc.mx.Unlock()
+
+	now := nc.now()
+	if item, ok := nc.nonces[nonce]; ok {
+		if now.Before(item.expiresAt) {
+			return false
+		}
+
+		item.expiresAt = expiresAt
+		heap.Fix(&nc.expiry, item.index)
+		return true
+	}
+
+	for len(nc.expiry) > 0 && !now.Before(nc.expiry[0].expiresAt) {
+		item := heap.Pop(&nc.expiry).(*nonceItem)
+		delete(nc.nonces, item.nonce)
+	}
+
+	// Evicting of the nonce which is not expired would allow to replay its request.
+	if len(nc.expiry) >= nc.size {
+		return false
+	}
+
+	item := &nonceItem{nonce: nonce, expiresAt: expiresAt}
+	heap.Push(&nc.expiry, item)
+	nc.nonces[nonce] = item
+
+	return true
+}
@@ -0,0 +1,114 @@
+package signing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedPayload(t *testing.T) {
+	payload := SignedPayload("1712840400", "0a1b", []byte("request body"))
+	assert.Equal(t, []byte("1712840400\n0a1b\nrequest body"), payload)
+}
+
+func TestNewNonce(t *testing.T) {
+	first, err := NewNonce()
+	require.NoError(t, err)
+	second, err := NewNonce()
+	require.NoError(t, err)
+
+	assert.Len(t, first, 2*nonceSize)
+	assert.NotEqual(t, first, second)
+}
+
+func TestParseTimestamp(t *testing.T) {
+	now := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+
+	got, err := ParseTimestamp(FormatTimestamp(now))
+	require.NoError(t, err)
+	assert.True(t, now.Equal(got))
+
+	_, err = ParseTimestamp("yesterday")
+	require.Error(t, err)
+}
+
+func TestCheckReplay(t *testing.T) {
+	nonces := NewNonceCache(10)
+	now := FormatTimestamp(time.Now())
+
+	assert.True(t, CheckReplay(nonces, now, "0a1b", time.Minute))
+	assert.False(t, CheckReplay(nonces, now, "0a1b", time.Minute))
+	assert.False(t, CheckReplay(nonces, "yesterday", "0a1c", time.Minute))
+	assert.False(t, CheckReplay(nonces, FormatTimestamp(time.Now().Add(-time.Hour)), "0a1d", time.Minute))
+	assert.False(t, CheckReplay(nonces, FormatTimestamp(time.Now().Add(time.Hour)), "0a1e", time.Minute))
+}
+
+func Test_nonceCache_Add(t *testing.T) {
+	now := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+
+	type add struct {
+		expiresAt time.Time
+		now       time.Time
+		nonce     string
+		want      bool
+	}
+	tests := []struct {
+		name string
+		adds []add
+		size int
+	}{
+		{
+			name: "repeated nonce",
+			size: 10,
+			adds: []add{
+				{nonce: "a", expiresAt: now.Add(time.Minute), now: now, want: true},
+				{nonce: "b", expiresAt: now.Add(time.Minute), now: now, want: true},
+				{nonce: "a", expiresAt: now.Add(time.Minute), now: now.Add(time.Second), want: false},
+			},
+		},
+		{
+			name: "expired nonce",
+			size: 10,
+			adds: []add{
+				{nonce: "a", expiresAt: now.Add(time.Minute), now: now, want: true},
+				{nonce: "a", expiresAt: now.Add(3 * time.Minute), now: now.Add(2 * time.Minute), want: true},
+				{nonce: "a", expiresAt: now.Add(3 * time.Minute), now: now.Add(2 * time.Minute), want: false},
+			},
+		},
+		{
+			name: "full cache rejects new nonces until expiration",
+			size: 2,
+			adds: []add{
+				{nonce: "a", expiresAt: now.Add(time.Minute), now: now, want: true},
+				{nonce: "b", expiresAt: now.Add(time.Minute), now: now, want: true},
+				{nonce: "c", expiresAt: now.Add(time.Minute), now: now, want: false},
+				{nonce: "a", expiresAt: now.Add(time.Minute), now: now, want: false},
+				{nonce: "c", expiresAt: now.Add(2 * time.Minute), now: now.Add(time.Minute), want: true},
+				{nonce: "a", expiresAt: now.Add(2 * time.Minute), now: now.Add(time.Minute), want: true},
+			},
+		},
+		{
+			name: "nonces are evicted by expiration time",
+			size: 2,
+			adds: []add{
+				{nonce: "a", expiresAt: now.Add(3 * time.Minute), now: now, want: true},
+				{nonce: "b", expiresAt: now.Add(time.Minute), now: now, want: true},
+				{nonce: "c", expiresAt: now.Add(3 * time.Minute), now: now.Add(time.Minute), want: true},
+				{nonce: "a", expiresAt: now.Add(3 * time.Minute), now: now.Add(time.Minute), want: false},
+				{nonce: "b", expiresAt: now.Add(3 * time.Minute), now: now.Add(2 * time.Minute), want: false},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cache := NewNonceCache(test.size).(*nonceCache)
+			for _, a := range test.adds {
+				cache.now = func() time.Time { return a.now }
+				assert.Equal(t, a.want, cache.Add(a.nonce, a.expiresAt), a.nonce)
+				assert.LessOrEqual(t, len(cache.nonces), test.size)
+			}
+		})
+	}
+}
@@ -0,0 +1,2 @@
+// Package spool implements persistent queue of agent payloads which failed to be sent to the server.
+package spool
@@ -0,0 +1,82 @@
+// Code generated by mockery v2.43.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Spool is an autogenerated mock type for the Spool type
+type Spool struct {
+	mock.Mock
+}
+
+// Len provides a mock function with given fields:
+func (_m *Spool) Len() int {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Len")
+	}
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
+// Push provides a mock function with given fields: payload
+func (_m *Spool) Push(payload []byte) error {
+	ret := _m.Called(payload)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Push")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte) error); ok {
+		r0 = rf(payload)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Replay provides a mock function with given fields: ctx, send
+func (_m *Spool) Replay(ctx context.Context, send func([]byte) error) error {
+	ret := _m.Called(ctx, send)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Replay")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func([]byte) error) error); ok {
+		r0 = rf(ctx, send)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewSpool creates a new instance of Spool. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSpool(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Spool {
+	mock := &Spool{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,251 @@
+package spool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const segmentExt = ".seg"
+
+var (
+	// ErrDirNotSpecified is the error returned when the directory parameter passed in NewSpool is blank.
+	ErrDirNotSpecified = errors.New("spool directory cannot be empty")
+	// ErrPayloadTooLarge is the error returned when the payload exceeds the maximum size of the spool.
+	ErrPayloadTooLarge = errors.New("payload is larger than spool max size")
+)
+
+// Spool is the interface of the persistent queue of payloads.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.43.1 --name=Spool
+type Spool interface {
+	// Push persists the payload at the end of the queue. The oldest payloads are dropped if the queue is full.
+	Push(payload []byte) error
+	// Replay sends persisted payloads in order and removes sent ones. It stops at the first failed payload.
+	Replay(ctx context.Context, send func(payload []byte) error) error
+	// Len returns count of persisted payloads.
+	Len() int
+}
+
+type segment struct {
+	modTime time.Time
+	seq     uint64
+	size    int64
+}
+
+type spool struct {
+	now      func() time.Time
+	dir      string
+	segments []segment
+	maxSize  int64
+	maxAge   time.Duration
+	size     int64
+	nextSeq  uint64
+	mx       sync.Mutex
+}
+
+// NewSpool opens the spool in the directory creating it if necessary. Payloads persisted before are restored.
+// Zero maxSize or maxAge means no limit.
+func NewSpool(dir string, maxSize int64, maxAge time.Duration) (Spool, error) {
+	if len(dir) == 0 {
+		return nil, ErrDirNotSpecified
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	s := &spool{
+		dir:     dir,
+		maxSize: maxSize,
+		maxAge:  maxAge,
+		now:     time.Now,
+	}
+
+	if err := s.restore(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// restore loads list of segments from the directory. Unfinished temporary files are removed.
+func (s *spool) restore() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+
+		if strings.HasSuffix(name, ".tmp") {
+			_ = os.Remove(filepath.Join(s.dir, name))
+			continue
+		}
+
+		if !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		s.segments = append(s.segments, segment{seq: seq, size: info.Size(), modTime: info.ModTime()})
+		s.size += info.Size()
+		if seq >= s.nextSeq {
+			s.nextSeq = seq + 1
+		}
+	}
+
+	sort.Slice(s.segments, func(i, j int) bool {
+		return s.segments[i].seq < s.segments[j].seq
+	})
+
+	return nil
+}
+
+func (s *spool) segmentPath(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, segmentExt))
+}
+
+// Push persists the payload at the end of the queue. The oldest payloads are dropped if the queue is full.
+func (s *spool) Push(payload []byte) error {
+	size := int64(len(payload))
+	if s.maxSize > 0 && size > s.maxSize {
+		return ErrPayloadTooLarge
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.dropExpired()
+	for s.maxSize > 0 && len(s.segments) > 0 && s.size+size > s.maxSize {
+		slog.Warn("spool is full, the oldest payload is dropped", slog.String("dir", s.dir))
+		s.dropFirst()
+	}
+
+	seq := s.nextSeq
+	path := s.segmentPath(seq)
+
+	// The payload is written to the temporary file first, so the partially written segment is never replayed.
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, payload, 0600); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	s.nextSeq++
+	s.segments = append(s.segments, segment{seq: seq, size: size, modTime: s.now()})
+	s.size += size
+
+	return nil
+}
+
+// Replay sends persisted payloads in order and removes sent ones. It stops at the first failed payload. The spool is
+// not locked while the payload is sent, so slow sends don't block Push and Len. Replay must not be called
+// concurrently.
+func (s *spool) Replay(ctx context.Context, send func(payload []byte) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		seq, payload, ok := s.head()
+		if !ok {
+			return nil
+		}
+
+		if err := send(payload); err != nil {
+			return err
+		}
+
+		s.mx.Lock()
+		s.dropSent(seq)
+		s.mx.Unlock()
+	}
+}
+
+// head returns the oldest payload with its sequence number, broken segments are dropped. Returns false if the spool
+// is empty.
+func (s *spool) head() (uint64, []byte, bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.dropExpired()
+	for len(s.segments) > 0 {
+		seq := s.segments[0].seq
+		payload, err := os.ReadFile(s.segmentPath(seq))
+		if err == nil {
+			return seq, payload, true
+		}
+
+		slog.Error("broken spool segment is dropped", slog.String("error", err.Error()))
+		s.dropFirst()
+	}
+
+	return 0, nil, false
+}
+
+// Len returns count of persisted payloads.
+func (s *spool) Len() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	return len(s.segments)
+}
+
+// dropExpired removes segments older than max age.
+func (s *spool) dropExpired() {
+	if s.maxAge <= 0 {
+		return
+	}
+
+	deadline := s.now().Add(-s.maxAge)
+	for len(s.segments) > 0 && s.segments[0].modTime.Before(deadline) {
+		slog.Warn("expired payload is dropped from spool", slog.String("dir", s.dir))
+		s.dropFirst()
+	}
+}
+
+// dropSent removes the sent segment. It is missing if Push has dropped it from the full spool while it was sent.
+func (s *spool) dropSent(seq uint64) {
+	if len(s.segments) > 0 && s.segments[0].seq == seq {
+		s.dropFirst()
+	}
+}
+
+// dropFirst removes the oldest segment.
+func (s *spool) dropFirst() {
+	first := s.segments[0]
+	err := os.Remove(s.segmentPath(first.seq))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Error("error removing spool segment", slog.String("error", err.Error()))
+	}
+
+	s.segments = s.segments[1:]
+	s.size -= first.size
+}
@@ -0,0 +1,148 @@
+package spool
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replayAll returns all payloads of the spool in order.
+func replayAll(t *testing.T, s Spool) []string {
+	result := make([]string, 0)
+	err := s.Replay(context.Background(), func(payload []byte) error {
+		result = append(result, string(payload))
+		return nil
+	})
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestNewSpool(t *testing.T) {
+	_, err := NewSpool("", 0, 0)
+	require.ErrorIs(t, err, ErrDirNotSpecified)
+
+	dir := filepath.Join(t.TempDir(), "spool")
+	s, err := NewSpool(dir, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, s.Len())
+	assert.DirExists(t, dir)
+}
+
+func TestSpool_PushReplay(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 0, 0)
+	require.NoError(t, err)
+
+	for _, payload := range []string{"first", "second", "third"} {
+		require.NoError(t, s.Push([]byte(payload)))
+	}
+	assert.Equal(t, 3, s.Len())
+
+	sent := make([]string, 0)
+	errSomethingWrong := errors.New("something wrong")
+	err = s.Replay(context.Background(), func(payload []byte) error {
+		if string(payload) == "second" {
+			return errSomethingWrong
+		}
+		sent = append(sent, string(payload))
+		return nil
+	})
+	require.ErrorIs(t, err, errSomethingWrong)
+	assert.Equal(t, []string{"first"}, sent)
+	assert.Equal(t, 2, s.Len())
+
+	require.NoError(t, s.Push([]byte("fourth")))
+	assert.Equal(t, []string{"second", "third", "fourth"}, replayAll(t, s))
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestSpool_Restore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewSpool(dir, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, s.Push([]byte("first")))
+	require.NoError(t, s.Push([]byte("second")))
+
+	// Unfinished write and foreign files are ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000005.seg.tmp"), []byte("broken"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "readme.txt"), []byte("readme"), 0600))
+
+	restored, err := NewSpool(dir, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, restored.Len())
+	assert.NoFileExists(t, filepath.Join(dir, "00000000000000000005.seg.tmp"))
+
+	require.NoError(t, restored.Push([]byte("third")))
+	assert.Equal(t, []string{"first", "second", "third"}, replayAll(t, restored))
+}
+
+func TestSpool_MaxSize(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 10, 0)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, s.Push([]byte("payload is too large")), ErrPayloadTooLarge)
+
+	require.NoError(t, s.Push([]byte("1111")))
+	require.NoError(t, s.Push([]byte("2222")))
+	require.NoError(t, s.Push([]byte("3333")))
+	assert.Equal(t, []string{"2222", "3333"}, replayAll(t, s))
+}
+
+func TestSpool_MaxAge(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewSpool(dir, 0, time.Hour)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Push([]byte("old")))
+	require.NoError(t, s.Push([]byte("new")))
+
+	sp := s.(*spool)
+	sp.segments[0].modTime = time.Now().Add(-2 * time.Hour)
+
+	assert.Equal(t, []string{"new"}, replayAll(t, s))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestSpool_ReplayCanceled(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, s.Push([]byte("first")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = s.Replay(ctx, func(payload []byte) error {
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, s.Len())
+}
+
+func TestSpool_ReplayUnlocked(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 10, 0)
+	require.NoError(t, err)
+	require.NoError(t, s.Push([]byte("1111")))
+	require.NoError(t, s.Push([]byte("2222")))
+
+	sent := make([]string, 0)
+	err = s.Replay(context.Background(), func(payload []byte) error {
+		sent = append(sent, string(payload))
+		if string(payload) == "1111" {
+			// The spool is available while the payload is sent, the full spool drops the payload being sent.
+			assert.Equal(t, 2, s.Len())
+			require.NoError(t, s.Push([]byte("3333")))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1111", "2222", "3333"}, sent)
+	assert.Equal(t, 0, s.Len())
+}
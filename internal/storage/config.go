@@ -16,4 +16,8 @@ type Config struct {
 
 	// Interval of autosave in-memory store
 	Interval time.Duration
+
+	// HistoryRetention is the period during which metrics samples are kept.
+	// If zero, samples are kept forever.
+	HistoryRetention time.Duration
 }
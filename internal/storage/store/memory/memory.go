@@ -4,27 +4,32 @@ import (
 	"context"
 	"encoding/json"
 	"os"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/e1m0re/grdn/internal/models"
-	"github.com/e1m0re/grdn/internal/utils"
 )
 
 // Store that leverages RAM.
 type Store struct {
 	metrics map[string]models.Metric
+	history map[string][]models.Metric
 
-	filePath string
-	syncMode bool
+	filePath         string
+	historyRetention time.Duration
+	syncMode         bool
 	sync.RWMutex
 }
 
 // NewStore creates a new in-memory store.
-func NewStore(ctx context.Context, filePath string, syncMode bool) (*Store, error) {
+func NewStore(ctx context.Context, filePath string, syncMode bool, historyRetention time.Duration) (*Store, error) {
 	store := &Store{
-		metrics:  make(map[string]models.Metric),
-		syncMode: syncMode,
-		filePath: filePath,
+		metrics:          make(map[string]models.Metric),
+		history:          make(map[string][]models.Metric),
+		syncMode:         syncMode,
+		filePath:         filePath,
+		historyRetention: historyRetention,
 	}
 
 	var err error
@@ -35,13 +40,18 @@ func NewStore(ctx context.Context, filePath string, syncMode bool) (*Store, erro
 	return store, err
 }
 
-func (s *Store) genMetricKey(m models.MetricName, t models.MetricType) string {
-	return utils.GetMD5Hash(t + m)
+// metricKey returns the key of the metric identified by its type, name and labels.
+func (s *Store) metricKey(t models.MetricType, m models.MetricName, labels models.Labels) string {
+	return t + "\x00" + m + "\x00" + labels.String()
 }
 
 // Clear removes all data in storage.
 func (s *Store) Clear(ctx context.Context) error {
+	s.RWMutex.Lock()
+	defer s.RWMutex.Unlock()
+
 	s.metrics = make(map[string]models.Metric)
+	s.history = make(map[string][]models.Metric)
 	return nil
 }
 
@@ -59,10 +69,16 @@ func (s *Store) GetAllMetrics(ctx context.Context) (*models.MetricsList, error)
 	i := 0
 	for _, metric := range s.metrics {
 		result[i] = &models.Metric{
-			Value: metric.Value,
-			Delta: metric.Delta,
-			MType: metric.MType,
-			ID:    metric.ID,
+			Value:     metric.Value,
+			Delta:     metric.Delta,
+			Timestamp: metric.Timestamp,
+			LastSeen:  metric.LastSeen,
+			Histogram: metric.Histogram,
+			Summary:   metric.Summary,
+			Labels:    metric.Labels,
+			MType:     metric.MType,
+			ID:        metric.ID,
+			AgentID:   metric.AgentID,
 		}
 
 		i++
@@ -71,12 +87,12 @@ func (s *Store) GetAllMetrics(ctx context.Context) (*models.MetricsList, error)
 	return &result, nil
 }
 
-// GetMetric returns an object Metric.
-func (s *Store) GetMetric(ctx context.Context, mType models.MetricType, mName string) (*models.Metric, error) {
+// GetMetric returns an object Metric with exactly the specified labels.
+func (s *Store) GetMetric(ctx context.Context, mType models.MetricType, mName string, labels models.Labels) (*models.Metric, error) {
 	s.RWMutex.RLock()
 	defer s.RWMutex.RUnlock()
 
-	key := s.genMetricKey(mName, mType)
+	key := s.metricKey(mType, mName, labels)
 	metric, ok := s.metrics[key]
 	if !ok {
 		return nil, nil
@@ -85,6 +101,25 @@ func (s *Store) GetMetric(ctx context.Context, mType models.MetricType, mName st
 	return &metric, nil
 }
 
+// GetMetricHistory returns samples of the metric recorded in the time window [from, to], ordered by time.
+func (s *Store) GetMetricHistory(ctx context.Context, mType models.MetricType, mName string, labels models.Labels, from, to time.Time) (*models.MetricsList, error) {
+	s.RWMutex.RLock()
+	defer s.RWMutex.RUnlock()
+
+	samples := s.history[s.metricKey(mType, mName, labels)]
+	start := sort.Search(len(samples), func(i int) bool {
+		return !samples[i].Timestamp.Before(from)
+	})
+
+	result := make(models.MetricsList, 0)
+	for i := start; i < len(samples) && !samples[i].Timestamp.After(to); i++ {
+		sample := samples[i]
+		result = append(result, &sample)
+	}
+
+	return &result, nil
+}
+
 // Ping checks the connection to the storage.
 func (s *Store) Ping(ctx context.Context) error {
 	return nil
@@ -104,6 +139,7 @@ func (s *Store) Restore(ctx context.Context) error {
 	}
 
 	s.metrics = make(map[string]models.Metric, len(metrics))
+	s.history = make(map[string][]models.Metric, len(metrics))
 
 	return s.UpdateMetrics(ctx, metrics)
 }
@@ -130,10 +166,19 @@ func (s *Store) Save(ctx context.Context) error {
 
 // UpdateMetrics performs batch updates of result values in the store.
 func (s *Store) UpdateMetrics(ctx context.Context, metrics models.MetricsList) error {
+	s.RWMutex.Lock()
+	now := time.Now()
 	for _, metric := range metrics {
-		key := s.genMetricKey(metric.ID, metric.MType)
-		s.metrics[key] = *metric
+		m := *metric
+		if m.Timestamp == nil {
+			m.Timestamp = &now
+		}
+
+		key := s.metricKey(m.MType, m.ID, m.Labels)
+		s.metrics[key] = m
+		s.appendSample(key, m, now)
 	}
+	s.RWMutex.Unlock()
 
 	if s.syncMode {
 		err := s.Save(ctx)
@@ -143,3 +188,24 @@ func (s *Store) UpdateMetrics(ctx context.Context, metrics models.MetricsList) e
 
 	return nil
 }
+
+// appendSample adds the sample to the metric history keeping it ordered by time and drops expired samples.
+func (s *Store) appendSample(key string, sample models.Metric, now time.Time) {
+	samples := s.history[key]
+	idx := sort.Search(len(samples), func(i int) bool {
+		return samples[i].Timestamp.After(*sample.Timestamp)
+	})
+	samples = append(samples, models.Metric{})
+	copy(samples[idx+1:], samples[idx:])
+	samples[idx] = sample
+
+	if s.historyRetention > 0 {
+		expired := now.Add(-s.historyRetention)
+		idx = sort.Search(len(samples), func(i int) bool {
+			return !samples[i].Timestamp.Before(expired)
+		})
+		samples = samples[idx:]
+	}
+
+	s.history[key] = samples
+}
@@ -56,10 +56,11 @@ func TestStore_Clear(t *testing.T) {
 	}
 }
 
-func TestStore_genMetricKey(t *testing.T) {
+func TestStore_metricKey(t *testing.T) {
 	type args struct {
-		m models.MetricName
-		t models.MetricType
+		labels models.Labels
+		t      models.MetricType
+		m      models.MetricName
 	}
 	type want struct {
 		key string
@@ -72,48 +73,49 @@ func TestStore_genMetricKey(t *testing.T) {
 		{
 			name: "Empty metric name",
 			args: args{
-				m: "",
 				t: models.CounterType,
+				m: "",
 			},
 			want: want{
-				key: "886bb73b3156b0aa24aac99d2de0b238",
+				key: "counter\x00\x00",
 			},
 		},
 		{
 			name: "Empty metric type",
 			args: args{
-				m: "metric 1",
 				t: "",
+				m: "metric 1",
 			},
 			want: want{
-				key: "65dba30d4a32b317a4ffebd2f077a49b",
+				key: "\x00metric 1\x00",
 			},
 		},
 		{
 			name: "Successfully case for counter metric",
 			args: args{
-				m: "metric 1",
 				t: models.CounterType,
+				m: "metric 1",
 			},
 			want: want{
-				key: "c917499366554154b438bb8259183adc",
+				key: "counter\x00metric 1\x00",
 			},
 		},
 		{
-			name: "Successfully case for gauge metric",
+			name: "Successfully case for gauge metric with labels",
 			args: args{
-				m: "metric 1",
-				t: models.GaugeType,
+				labels: models.Labels{"host": "web1", "cpu": "0"},
+				t:      models.GaugeType,
+				m:      "metric 1",
 			},
 			want: want{
-				key: "9e646d6d5855fbdadcaab202f1748505",
+				key: "gauge\x00metric 1\x00{cpu=\"0\",host=\"web1\"}",
 			},
 		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			s := &Store{}
-			got := s.genMetricKey(test.args.m, test.args.t)
+			got := s.metricKey(test.args.t, test.args.m, test.args.labels)
 			assert.Equal(t, test.want.key, got)
 		})
 	}
@@ -175,9 +177,10 @@ func TestStore_GetMetric(t *testing.T) {
 		metrics map[string]models.Metric
 	}
 	type args struct {
-		ctx   context.Context
-		mType models.MetricType
-		mName string
+		ctx    context.Context
+		labels models.Labels
+		mType  models.MetricType
+		mName  string
 	}
 	type want struct {
 		metric *models.Metric
@@ -204,11 +207,62 @@ func TestStore_GetMetric(t *testing.T) {
 				err:    nil,
 			},
 		},
+		{
+			name: "Other labels",
+			fields: fields{
+				metrics: map[string]models.Metric{
+					"counter\x00metric 1\x00{host=\"web1\"}": {
+						Delta:  &delta,
+						Labels: models.Labels{"host": "web1"},
+						MType:  models.CounterType,
+						ID:     "metric 1",
+					},
+				},
+			},
+			args: args{
+				ctx:    context.Background(),
+				labels: models.Labels{"host": "web2"},
+				mType:  models.CounterType,
+				mName:  "metric 1",
+			},
+			want: want{
+				metric: nil,
+				err:    nil,
+			},
+		},
+		{
+			name: "Successfully case (labels)",
+			fields: fields{
+				metrics: map[string]models.Metric{
+					"counter\x00metric 1\x00{host=\"web1\"}": {
+						Delta:  &delta,
+						Labels: models.Labels{"host": "web1"},
+						MType:  models.CounterType,
+						ID:     "metric 1",
+					},
+				},
+			},
+			args: args{
+				ctx:    context.Background(),
+				labels: models.Labels{"host": "web1"},
+				mType:  models.CounterType,
+				mName:  "metric 1",
+			},
+			want: want{
+				metric: &models.Metric{
+					Delta:  &delta,
+					Labels: models.Labels{"host": "web1"},
+					MType:  models.CounterType,
+					ID:     "metric 1",
+				},
+				err: nil,
+			},
+		},
 		{
 			name: "Successfully case",
 			fields: fields{
 				metrics: map[string]models.Metric{
-					"c917499366554154b438bb8259183adc": {
+					"counter\x00metric 1\x00": {
 						Value: nil,
 						Delta: &delta,
 						MType: models.CounterType,
@@ -237,7 +291,7 @@ func TestStore_GetMetric(t *testing.T) {
 			s := &Store{
 				metrics: test.fields.metrics,
 			}
-			got, err := s.GetMetric(test.args.ctx, test.args.mType, test.args.mName)
+			got, err := s.GetMetric(test.args.ctx, test.args.mType, test.args.mName, test.args.labels)
 			assert.Equal(t, test.want.metric, got)
 			assert.Equal(t, test.want.err, err)
 		})
@@ -290,6 +344,16 @@ func TestStore_GetAllMetrics(t *testing.T) {
 						MType: models.GaugeType,
 						ID:    "metric 2",
 					},
+					"metric3": {
+						Histogram: &models.Histogram{Bounds: []float64{0.1}, Counts: []uint64{1, 2}, Sum: 4.5},
+						MType:     models.HistogramType,
+						ID:        "metric 3",
+					},
+					"metric4": {
+						Summary: &models.Summary{Quantiles: []models.Quantile{{Quantile: 0.5, Value: 0.2}}, Count: 3, Sum: 1.25},
+						MType:   models.SummaryType,
+						ID:      "metric 4",
+					},
 				},
 			},
 			args: args{
@@ -309,6 +373,16 @@ func TestStore_GetAllMetrics(t *testing.T) {
 						MType: models.GaugeType,
 						ID:    "metric 2",
 					},
+					"metric 3": {
+						Histogram: &models.Histogram{Bounds: []float64{0.1}, Counts: []uint64{1, 2}, Sum: 4.5},
+						MType:     models.HistogramType,
+						ID:        "metric 3",
+					},
+					"metric 4": {
+						Summary: &models.Summary{Quantiles: []models.Quantile{{Quantile: 0.5, Value: 0.2}}, Count: 3, Sum: 1.25},
+						MType:   models.SummaryType,
+						ID:      "metric 4",
+					},
 				},
 				err: nil,
 			},
@@ -335,6 +409,7 @@ func TestStore_UpdateMetrics(t *testing.T) {
 	dNew := int64(100)
 	vOld := float64(50.05)
 	vNew := float64(100.1)
+	ts := time.Date(2024, 4, 11, 13, 52, 24, 0, time.UTC)
 	type fields struct {
 		metrics  map[string]models.Metric
 		filePath string
@@ -365,32 +440,36 @@ func TestStore_UpdateMetrics(t *testing.T) {
 				ctx: context.Background(),
 				metrics: models.MetricsList{
 					{
-						Value: &vNew,
-						Delta: nil,
-						MType: models.CounterType,
-						ID:    "metric 1",
+						Value:     &vNew,
+						Delta:     nil,
+						Timestamp: &ts,
+						MType:     models.CounterType,
+						ID:        "metric 1",
 					},
 					{
-						Value: nil,
-						Delta: &dNew,
-						MType: models.GaugeType,
-						ID:    "metric 2",
+						Value:     nil,
+						Delta:     &dNew,
+						Timestamp: &ts,
+						MType:     models.GaugeType,
+						ID:        "metric 2",
 					},
 				},
 			},
 			want: want{
 				metrics: map[string]models.Metric{
-					"c917499366554154b438bb8259183adc": {
-						Value: &vNew,
-						Delta: nil,
-						MType: models.CounterType,
-						ID:    "metric 1",
+					"counter\x00metric 1\x00": {
+						Value:     &vNew,
+						Delta:     nil,
+						Timestamp: &ts,
+						MType:     models.CounterType,
+						ID:        "metric 1",
 					},
-					"bab0d7e52057f00e3cf6b851bf72f4c1": {
-						Value: nil,
-						Delta: &dNew,
-						MType: models.GaugeType,
-						ID:    "metric 2",
+					"gauge\x00metric 2\x00": {
+						Value:     nil,
+						Delta:     &dNew,
+						Timestamp: &ts,
+						MType:     models.GaugeType,
+						ID:        "metric 2",
 					},
 				},
 				err: nil,
@@ -400,13 +479,13 @@ func TestStore_UpdateMetrics(t *testing.T) {
 			name: "Update empty list without sync",
 			fields: fields{
 				metrics: map[string]models.Metric{
-					"c917499366554154b438bb8259183adc": {
+					"counter\x00metric 1\x00": {
 						Value: &vOld,
 						Delta: nil,
 						MType: models.CounterType,
 						ID:    "metric 1",
 					},
-					"bab0d7e52057f00e3cf6b851bf72f4c1": {
+					"gauge\x00metric 2\x00": {
 						Value: nil,
 						Delta: &dOld,
 						MType: models.GaugeType,
@@ -422,13 +501,13 @@ func TestStore_UpdateMetrics(t *testing.T) {
 			},
 			want: want{
 				metrics: map[string]models.Metric{
-					"c917499366554154b438bb8259183adc": {
+					"counter\x00metric 1\x00": {
 						Value: &vOld,
 						Delta: nil,
 						MType: models.CounterType,
 						ID:    "metric 1",
 					},
-					"bab0d7e52057f00e3cf6b851bf72f4c1": {
+					"gauge\x00metric 2\x00": {
 						Value: nil,
 						Delta: &dOld,
 						MType: models.GaugeType,
@@ -442,13 +521,13 @@ func TestStore_UpdateMetrics(t *testing.T) {
 			name: "Successfully case with sync mode",
 			fields: fields{
 				metrics: map[string]models.Metric{
-					"c917499366554154b438bb8259183adc": {
+					"counter\x00metric 1\x00": {
 						Value: &vOld,
 						Delta: nil,
 						MType: models.CounterType,
 						ID:    "metric 1",
 					},
-					"bab0d7e52057f00e3cf6b851bf72f4c1": {
+					"gauge\x00metric 2\x00": {
 						Value: nil,
 						Delta: &dOld,
 						MType: models.GaugeType,
@@ -462,32 +541,36 @@ func TestStore_UpdateMetrics(t *testing.T) {
 				ctx: context.Background(),
 				metrics: models.MetricsList{
 					{
-						Value: &vNew,
-						Delta: nil,
-						MType: models.CounterType,
-						ID:    "metric 1",
+						Value:     &vNew,
+						Delta:     nil,
+						Timestamp: &ts,
+						MType:     models.CounterType,
+						ID:        "metric 1",
 					},
 					{
-						Value: nil,
-						Delta: &dNew,
-						MType: models.GaugeType,
-						ID:    "metric 2",
+						Value:     nil,
+						Delta:     &dNew,
+						Timestamp: &ts,
+						MType:     models.GaugeType,
+						ID:        "metric 2",
 					},
 				},
 			},
 			want: want{
 				metrics: map[string]models.Metric{
-					"c917499366554154b438bb8259183adc": {
-						Value: &vNew,
-						Delta: nil,
-						MType: models.CounterType,
-						ID:    "metric 1",
+					"counter\x00metric 1\x00": {
+						Value:     &vNew,
+						Delta:     nil,
+						Timestamp: &ts,
+						MType:     models.CounterType,
+						ID:        "metric 1",
 					},
-					"bab0d7e52057f00e3cf6b851bf72f4c1": {
-						Value: nil,
-						Delta: &dNew,
-						MType: models.GaugeType,
-						ID:    "metric 2",
+					"gauge\x00metric 2\x00": {
+						Value:     nil,
+						Delta:     &dNew,
+						Timestamp: &ts,
+						MType:     models.GaugeType,
+						ID:        "metric 2",
 					},
 				},
 				err: nil,
@@ -498,6 +581,7 @@ func TestStore_UpdateMetrics(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			s := &Store{
 				metrics:  test.fields.metrics,
+				history:  make(map[string][]models.Metric),
 				syncMode: test.fields.syncMode,
 				filePath: test.fields.filePath,
 			}
@@ -531,13 +615,13 @@ func TestStore_Save(t *testing.T) {
 			name: "successfully case",
 			fields: fields{
 				metrics: map[string]models.Metric{
-					"9e646d6d5855fbdadcaab202f1748505": {
+					"gauge\x00metric 1\x00": {
 						Value: &value,
 						Delta: nil,
 						MType: models.GaugeType,
 						ID:    "metric 1",
 					},
-					"3e0673a56ff12916a6293fa5a1bfc2db": {
+					"counter\x00metric 2\x00": {
 						Value: nil,
 						Delta: &delta,
 						MType: models.CounterType,
@@ -576,6 +660,7 @@ func TestStore_Save(t *testing.T) {
 }
 
 func TestStore_Restore(t *testing.T) {
+	ts := time.Date(2024, 4, 11, 13, 52, 24, 0, time.UTC)
 	type fields struct {
 		metrics  map[string]models.Metric
 		filePath string
@@ -599,7 +684,7 @@ func TestStore_Restore(t *testing.T) {
 			fields: fields{
 				metrics:  make(map[string]models.Metric),
 				filePath: "/tmp/TestStore_Restore.bac",
-				content:  []byte("[{\"delta\":100,\"type\":\"counter\",\"id\":\"metric 2\"},{\"value\":100.1,\"type\":\"gauge\",\"id\":\"metric 1\"}]"),
+				content:  []byte("[{\"delta\":100,\"timestamp\":\"2024-04-11T13:52:24Z\",\"type\":\"counter\",\"id\":\"metric 2\"},{\"value\":100.1,\"timestamp\":\"2024-04-11T13:52:24Z\",\"type\":\"gauge\",\"id\":\"metric 1\"},{\"histogram\":{\"bounds\":[0.1],\"counts\":[1,2],\"sum\":4.5},\"timestamp\":\"2024-04-11T13:52:24Z\",\"type\":\"histogram\",\"id\":\"metric 3\"}]"),
 			},
 			args: args{
 				ctx: context.Background(),
@@ -607,15 +692,23 @@ func TestStore_Restore(t *testing.T) {
 			want: want{
 				err: nil,
 				metrics: map[string]models.Metric{
-					"9e646d6d5855fbdadcaab202f1748505": {
-						Value: &value,
-						MType: models.GaugeType,
-						ID:    "metric 1",
+					"gauge\x00metric 1\x00": {
+						Value:     &value,
+						Timestamp: &ts,
+						MType:     models.GaugeType,
+						ID:        "metric 1",
 					},
-					"3e0673a56ff12916a6293fa5a1bfc2db": {
-						Delta: &delta,
-						MType: models.CounterType,
-						ID:    "metric 2",
+					"counter\x00metric 2\x00": {
+						Delta:     &delta,
+						Timestamp: &ts,
+						MType:     models.CounterType,
+						ID:        "metric 2",
+					},
+					"histogram\x00metric 3\x00": {
+						Histogram: &models.Histogram{Bounds: []float64{0.1}, Counts: []uint64{1, 2}, Sum: 4.5},
+						Timestamp: &ts,
+						MType:     models.HistogramType,
+						ID:        "metric 3",
 					},
 				},
 			},
@@ -679,7 +772,7 @@ func TestNewStore(t *testing.T) {
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			got, err := NewStore(test.args.ctx, test.args.filePath, test.args.syncMode)
+			got, err := NewStore(test.args.ctx, test.args.filePath, test.args.syncMode, time.Hour)
 			require.Equal(t, test.want.err, err)
 			//assert.Implements(t, (*store.Store)(nil), got)
 			assert.Equal(t, test.want.str.metrics, got.metrics)
@@ -688,3 +781,126 @@ func TestNewStore(t *testing.T) {
 		})
 	}
 }
+
+func TestStore_GetMetricHistory(t *testing.T) {
+	ts := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+	ts1 := ts.Add(time.Minute)
+	ts2 := ts.Add(2 * time.Minute)
+	ts3 := ts.Add(3 * time.Minute)
+	v1 := float64(1)
+	v2 := float64(2)
+	v3 := float64(3)
+	type args struct {
+		ctx    context.Context
+		from   time.Time
+		to     time.Time
+		labels models.Labels
+		mType  models.MetricType
+		mName  string
+	}
+	type want struct {
+		metrics *models.MetricsList
+		err     error
+	}
+	tests := []struct {
+		args args
+		want want
+		name string
+	}{
+		{
+			name: "Unknown metric",
+			args: args{
+				ctx:   context.Background(),
+				mType: models.GaugeType,
+				mName: "metric 2",
+				from:  ts,
+				to:    ts3,
+			},
+			want: want{
+				metrics: &models.MetricsList{},
+				err:     nil,
+			},
+		},
+		{
+			name: "Other labels",
+			args: args{
+				ctx:    context.Background(),
+				labels: models.Labels{"host": "web1"},
+				mType:  models.GaugeType,
+				mName:  "metric 1",
+				from:   ts,
+				to:     ts3,
+			},
+			want: want{
+				metrics: &models.MetricsList{},
+				err:     nil,
+			},
+		},
+		{
+			name: "Empty window",
+			args: args{
+				ctx:   context.Background(),
+				mType: models.GaugeType,
+				mName: "metric 1",
+				from:  ts3.Add(time.Second),
+				to:    ts3.Add(time.Minute),
+			},
+			want: want{
+				metrics: &models.MetricsList{},
+				err:     nil,
+			},
+		},
+		{
+			name: "Successfully case",
+			args: args{
+				ctx:   context.Background(),
+				mType: models.GaugeType,
+				mName: "metric 1",
+				from:  ts1,
+				to:    ts2,
+			},
+			want: want{
+				metrics: &models.MetricsList{
+					{Value: &v1, Timestamp: &ts1, MType: models.GaugeType, ID: "metric 1"},
+					{Value: &v2, Timestamp: &ts2, MType: models.GaugeType, ID: "metric 1"},
+				},
+				err: nil,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := NewStore(context.Background(), "", false, 0)
+			require.Nil(t, err)
+			// samples are deliberately sent out of order
+			err = s.UpdateMetrics(context.Background(), models.MetricsList{
+				{Value: &v2, Timestamp: &ts2, MType: models.GaugeType, ID: "metric 1"},
+				{Value: &v1, Timestamp: &ts1, MType: models.GaugeType, ID: "metric 1"},
+				{Value: &v3, Timestamp: &ts3, MType: models.GaugeType, ID: "metric 1"},
+				{Value: &v3, Timestamp: &ts2, Labels: models.Labels{"host": "web2"}, MType: models.GaugeType, ID: "metric 1"},
+			})
+			require.Nil(t, err)
+
+			got, err := s.GetMetricHistory(test.args.ctx, test.args.mType, test.args.mName, test.args.labels, test.args.from, test.args.to)
+			assert.Equal(t, test.want.err, err)
+			assert.Equal(t, test.want.metrics, got)
+		})
+	}
+}
+
+func TestStore_appendSample(t *testing.T) {
+	now := time.Now()
+	expired := now.Add(-2 * time.Hour)
+	actual := now.Add(-time.Minute)
+	v := float64(1)
+
+	s := &Store{
+		history:          make(map[string][]models.Metric),
+		historyRetention: time.Hour,
+	}
+	s.appendSample("key", models.Metric{Value: &v, Timestamp: &expired, MType: models.GaugeType, ID: "metric 1"}, now)
+	s.appendSample("key", models.Metric{Value: &v, Timestamp: &actual, MType: models.GaugeType, ID: "metric 1"}, now)
+
+	require.Len(t, s.history["key"], 1)
+	assert.Equal(t, &actual, s.history["key"][0].Timestamp)
+}
@@ -7,6 +7,8 @@ import (
 
 	models "github.com/e1m0re/grdn/internal/models"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // Store is an autogenerated mock type for the Store type
@@ -80,9 +82,9 @@ func (_m *Store) GetAllMetrics(ctx context.Context) (*models.MetricsList, error)
 	return r0, r1
 }
 
-// GetMetric provides a mock function with given fields: ctx, mType, mName
-func (_m *Store) GetMetric(ctx context.Context, mType string, mName string) (*models.Metric, error) {
-	ret := _m.Called(ctx, mType, mName)
+// GetMetric provides a mock function with given fields: ctx, mType, mName, labels
+func (_m *Store) GetMetric(ctx context.Context, mType string, mName string, labels models.Labels) (*models.Metric, error) {
+	ret := _m.Called(ctx, mType, mName, labels)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetMetric")
@@ -90,19 +92,49 @@ func (_m *Store) GetMetric(ctx context.Context, mType string, mName string) (*mo
 
 	var r0 *models.Metric
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.Metric, error)); ok {
-		return rf(ctx, mType, mName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Labels) (*models.Metric, error)); ok {
+		return rf(ctx, mType, mName, labels)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.Metric); ok {
-		r0 = rf(ctx, mType, mName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Labels) *models.Metric); ok {
+		r0 = rf(ctx, mType, mName, labels)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Metric)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, mType, mName)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, models.Labels) error); ok {
+		r1 = rf(ctx, mType, mName, labels)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMetricHistory provides a mock function with given fields: ctx, mType, mName, labels, from, to
+func (_m *Store) GetMetricHistory(ctx context.Context, mType string, mName string, labels models.Labels, from time.Time, to time.Time) (*models.MetricsList, error) {
+	ret := _m.Called(ctx, mType, mName, labels, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMetricHistory")
+	}
+
+	var r0 *models.MetricsList
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Labels, time.Time, time.Time) (*models.MetricsList, error)); ok {
+		return rf(ctx, mType, mName, labels, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Labels, time.Time, time.Time) *models.MetricsList); ok {
+		r0 = rf(ctx, mType, mName, labels, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.MetricsList)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, models.Labels, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, mType, mName, labels, from, to)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
@@ -22,13 +23,14 @@ var (
 
 // Store that leverages a database.
 type Store struct {
-	db     *sqlx.DB
-	driver string
-	path   string
+	db               *sqlx.DB
+	driver           string
+	path             string
+	historyRetention time.Duration
 }
 
 // NewStore initializes the database and creates the schema if it doesn't already exist in the path specified.
-func NewStore(driver string, path string) (*Store, error) {
+func NewStore(driver string, path string, historyRetention time.Duration) (*Store, error) {
 	if len(driver) == 0 {
 		return nil, ErrDatabaseDriverNotSpecified
 	}
@@ -37,8 +39,9 @@ func NewStore(driver string, path string) (*Store, error) {
 	}
 
 	store := &Store{
-		driver: driver,
-		path:   path,
+		driver:           driver,
+		path:             path,
+		historyRetention: historyRetention,
 	}
 
 	var err error
@@ -83,6 +86,11 @@ func (s *Store) migrate() error {
 // Clear removes all data in storage.
 func (s *Store) Clear(ctx context.Context) error {
 	_, err := s.db.ExecContext(ctx, "DELETE FROM metrics WHERE id > 0")
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, "DELETE FROM metrics_history WHERE id > 0")
 	return err
 }
 
@@ -94,17 +102,17 @@ func (s *Store) Close() error {
 // GetAllMetrics returns the list of all metrics.
 func (s *Store) GetAllMetrics(ctx context.Context) (*models.MetricsList, error) {
 	metrics := make(models.MetricsList, 0)
-	err := s.db.SelectContext(ctx, &metrics, "SELECT name, type, delta, value FROM metrics")
+	err := s.db.SelectContext(ctx, &metrics, "SELECT name, type, labels, delta, value, histogram, summary, timestamp, last_seen, agent_id FROM metrics")
 	if err != nil {
 		return nil, err
 	}
 	return &metrics, err
 }
 
-// GetMetric returns an object Metric.
-func (s *Store) GetMetric(ctx context.Context, mType models.MetricType, mName string) (*models.Metric, error) {
+// GetMetric returns an object Metric with exactly the specified labels.
+func (s *Store) GetMetric(ctx context.Context, mType models.MetricType, mName string, labels models.Labels) (*models.Metric, error) {
 	var metric models.Metric
-	err := s.db.GetContext(ctx, &metric, `SELECT name, type, delta, value FROM metrics WHERE name = $1 AND type = $2`, mName, mType)
+	err := s.db.GetContext(ctx, &metric, `SELECT name, type, labels, delta, value, histogram, summary, timestamp, last_seen, agent_id FROM metrics WHERE name = $1 AND type = $2 AND labels = $3`, mName, mType, labels)
 	switch {
 	case errors.Is(err, sql.ErrNoRows):
 		return nil, nil
@@ -115,6 +123,16 @@ func (s *Store) GetMetric(ctx context.Context, mType models.MetricType, mName st
 	}
 }
 
+// GetMetricHistory returns samples of the metric recorded in the time window [from, to], ordered by time.
+func (s *Store) GetMetricHistory(ctx context.Context, mType models.MetricType, mName string, labels models.Labels, from, to time.Time) (*models.MetricsList, error) {
+	metrics := make(models.MetricsList, 0)
+	err := s.db.SelectContext(ctx, &metrics, `SELECT name, type, labels, delta, value, histogram, summary, timestamp FROM metrics_history WHERE name = $1 AND type = $2 AND labels = $3 AND timestamp BETWEEN $4 AND $5 ORDER BY timestamp`, mName, mType, labels, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &metrics, err
+}
+
 // Ping checks the connection to the storage.
 func (s *Store) Ping(ctx context.Context) error {
 	return s.db.Ping()
@@ -141,14 +159,41 @@ func (s *Store) UpdateMetrics(ctx context.Context, metrics models.MetricsList) e
 		return err
 	}
 
-	stmt, err := tx.PrepareContext(ctx, `INSERT INTO metrics (name, type, delta, value) VALUES ($1, $2, $3, $4) ON CONFLICT(name, type) DO UPDATE SET delta = $3, value = $4`)
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO metrics (name, type, labels, delta, value, histogram, summary, timestamp, last_seen, agent_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT(name, type, labels) DO UPDATE SET delta = $4, value = $5, histogram = $6, summary = $7, timestamp = $8, last_seen = $9, agent_id = $10`)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
+	historyStmt, err := tx.PrepareContext(ctx, `INSERT INTO metrics_history (name, type, labels, delta, value, histogram, summary, timestamp) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`)
+	if err != nil {
+		return err
+	}
+	defer historyStmt.Close()
+
+	now := time.Now()
 	for _, metric := range metrics {
-		_, err = stmt.ExecContext(ctx, metric.ID, metric.MType, metric.Delta, metric.Value)
+		timestamp := now
+		if metric.Timestamp != nil {
+			timestamp = *metric.Timestamp
+		}
+
+		_, err = stmt.ExecContext(ctx, metric.ID, metric.MType, metric.Labels, metric.Delta, metric.Value, metric.Histogram, metric.Summary, timestamp, metric.LastSeen, metric.AgentID)
+		if err != nil {
+			rollbackErr := tx.Rollback()
+			return errors.Join(err, rollbackErr)
+		}
+
+		_, err = historyStmt.ExecContext(ctx, metric.ID, metric.MType, metric.Labels, metric.Delta, metric.Value, metric.Histogram, metric.Summary, timestamp)
+		if err != nil {
+			rollbackErr := tx.Rollback()
+			return errors.Join(err, rollbackErr)
+		}
+	}
+
+	// Expired samples are found by the index of metrics_history on timestamp.
+	if s.historyRetention > 0 {
+		_, err = tx.ExecContext(ctx, `DELETE FROM metrics_history WHERE timestamp < $1`, now.Add(-s.historyRetention))
 		if err != nil {
 			rollbackErr := tx.Rollback()
 			return errors.Join(err, rollbackErr)
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -19,10 +20,10 @@ var (
 )
 
 func TestNewStore(t *testing.T) {
-	if _, err := NewStore("", ""); !errors.Is(err, ErrDatabaseDriverNotSpecified) {
+	if _, err := NewStore("", "", 0); !errors.Is(err, ErrDatabaseDriverNotSpecified) {
 		t.Error("expected error due to blank driver parameter ")
 	}
-	if _, err := NewStore("pgx", ""); !errors.Is(err, ErrPathNotSpecified) {
+	if _, err := NewStore("pgx", "", 0); !errors.Is(err, ErrPathNotSpecified) {
 		t.Error("expected error due to blank path parameter ")
 	}
 }
@@ -105,7 +106,7 @@ func TestStore_GetAllMetrics(t *testing.T) {
 			},
 			mock: func() {
 				mock.
-					ExpectQuery("SELECT name, type, delta, value FROM metrics").
+					ExpectQuery("SELECT name, type, labels, delta, value, histogram, summary, timestamp, last_seen, agent_id FROM metrics").
 					WillReturnError(errors.New("something wrong"))
 			},
 		},
@@ -121,7 +122,7 @@ func TestStore_GetAllMetrics(t *testing.T) {
 			mock: func() {
 				rows := sqlxmock.NewRows(make([]string, 0))
 				mock.
-					ExpectQuery("SELECT name, type, delta, value FROM metrics").
+					ExpectQuery("SELECT name, type, labels, delta, value, histogram, summary, timestamp, last_seen, agent_id FROM metrics").
 					WillReturnRows(rows)
 			},
 		},
@@ -152,7 +153,7 @@ func TestStore_GetAllMetrics(t *testing.T) {
 					AddRow("metric 1", "counter", 100, nil).
 					AddRow("metric 2", "gauge", nil, 100.1)
 				mock.
-					ExpectQuery("SELECT name, type, delta, value FROM metrics").
+					ExpectQuery("SELECT name, type, labels, delta, value, histogram, summary, timestamp, last_seen, agent_id FROM metrics").
 					WillReturnRows(rows)
 			},
 		},
@@ -177,9 +178,10 @@ func TestStore_GetMetric(t *testing.T) {
 	s := Store{db: db}
 
 	type args struct {
-		ctx   context.Context
-		mType models.MetricType
-		mName string
+		ctx    context.Context
+		labels models.Labels
+		mType  models.MetricType
+		mName  string
 	}
 	type want struct {
 		err    error
@@ -204,7 +206,7 @@ func TestStore_GetMetric(t *testing.T) {
 			},
 			mock: func() {
 				mock.
-					ExpectQuery("^SELECT name, type, delta, value FROM metrics WHERE name = \\$1 AND type = \\$2$").
+					ExpectQuery("^SELECT name, type, labels, delta, value, histogram, summary, timestamp, last_seen, agent_id FROM metrics WHERE name = \\$1 AND type = \\$2 AND labels = \\$3$").
 					WillReturnError(errors.New("something wrong"))
 			},
 		},
@@ -221,31 +223,59 @@ func TestStore_GetMetric(t *testing.T) {
 			},
 			mock: func() {
 				mock.
-					ExpectQuery("^SELECT name, type, delta, value FROM metrics WHERE name = \\$1 AND type = \\$2$").
+					ExpectQuery("^SELECT name, type, labels, delta, value, histogram, summary, timestamp, last_seen, agent_id FROM metrics WHERE name = \\$1 AND type = \\$2 AND labels = \\$3$").
 					WillReturnError(sql.ErrNoRows)
 			},
 		},
 		{
 			name: "successfully case",
+			args: args{
+				ctx:    context.Background(),
+				labels: models.Labels{"host": "web1"},
+				mType:  models.GaugeType,
+				mName:  "metric 1",
+			},
+			want: want{
+				err: nil,
+				metric: &models.Metric{
+					Value:   &value,
+					Delta:   nil,
+					Labels:  models.Labels{"host": "web1"},
+					MType:   models.GaugeType,
+					ID:      "metric 1",
+					AgentID: "web-1",
+				},
+			},
+			mock: func() {
+				rows := sqlxmock.NewRows([]string{"name", "type", "labels", "delta", "value", "agent_id"}).
+					AddRow("metric 1", "gauge", []byte(`{"host": "web1"}`), nil, 100.1, "web-1")
+				mock.
+					ExpectQuery("^SELECT name, type, labels, delta, value, histogram, summary, timestamp, last_seen, agent_id FROM metrics WHERE name = \\$1 AND type = \\$2 AND labels = \\$3$").
+					WithArgs("metric 1", models.GaugeType, `{"host":"web1"}`).
+					WillReturnRows(rows)
+			},
+		},
+		{
+			name: "successfully case (histogram)",
 			args: args{
 				ctx:   context.Background(),
-				mType: models.CounterType,
-				mName: "metric 1",
+				mType: models.HistogramType,
+				mName: "latency",
 			},
 			want: want{
 				err: nil,
 				metric: &models.Metric{
-					Value: &value,
-					Delta: nil,
-					MType: models.GaugeType,
-					ID:    "metric 1",
+					Histogram: &models.Histogram{Bounds: []float64{0.1}, Counts: []uint64{1, 2}, Sum: 4.5},
+					MType:     models.HistogramType,
+					ID:        "latency",
 				},
 			},
 			mock: func() {
-				rows := sqlxmock.NewRows([]string{"name", "type", "delta", "value"}).
-					AddRow("metric 1", "gauge", nil, 100.1)
+				rows := sqlxmock.NewRows([]string{"name", "type", "labels", "delta", "value", "histogram", "summary", "agent_id"}).
+					AddRow("latency", "histogram", []byte(`{}`), nil, nil, []byte(`{"bounds": [0.1], "counts": [1, 2], "sum": 4.5}`), nil, "")
 				mock.
-					ExpectQuery("^SELECT name, type, delta, value FROM metrics WHERE name = \\$1 AND type = \\$2$").
+					ExpectQuery("^SELECT name, type, labels, delta, value, histogram, summary, timestamp, last_seen, agent_id FROM metrics WHERE name = \\$1 AND type = \\$2 AND labels = \\$3$").
+					WithArgs("latency", models.HistogramType, "{}").
 					WillReturnRows(rows)
 			},
 		},
@@ -253,12 +283,16 @@ func TestStore_GetMetric(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			test.mock()
-			got, err := s.GetMetric(test.args.ctx, test.args.mType, test.args.mName)
+			got, err := s.GetMetric(test.args.ctx, test.args.mType, test.args.mName, test.args.labels)
 			require.Equal(t, test.want.err, err)
 			if test.want.metric != nil {
 				assert.Equal(t, test.want.metric.ID, got.ID)
 				assert.Equal(t, test.want.metric.MType, got.MType)
-				assert.Equal(t, *test.want.metric.Value, *got.Value)
+				assert.Equal(t, test.want.metric.Value, got.Value)
+				assert.Equal(t, test.want.metric.Histogram, got.Histogram)
+				assert.Equal(t, test.want.metric.Summary, got.Summary)
+				assert.Equal(t, test.want.metric.Labels, got.Labels)
+				assert.Equal(t, test.want.metric.AgentID, got.AgentID)
 			} else {
 				assert.Nil(t, got)
 			}
@@ -266,6 +300,107 @@ func TestStore_GetMetric(t *testing.T) {
 	}
 }
 
+func TestStore_GetMetricHistory(t *testing.T) {
+	const query = "^SELECT name, type, labels, delta, value, histogram, summary, timestamp FROM metrics_history WHERE name = \\$1 AND type = \\$2 AND labels = \\$3 AND timestamp BETWEEN \\$4 AND \\$5 ORDER BY timestamp$"
+
+	db, mock, err := sqlxmock.Newx()
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	s := Store{db: db}
+
+	from := time.Date(2024, 4, 11, 13, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	ts1 := from.Add(time.Minute)
+	ts2 := from.Add(2 * time.Minute)
+	v2 := float64(200.2)
+
+	type args struct {
+		ctx   context.Context
+		from  time.Time
+		to    time.Time
+		mType models.MetricType
+		mName string
+	}
+	type want struct {
+		err     error
+		metrics *models.MetricsList
+	}
+	tests := []struct {
+		mock func()
+		args args
+		want want
+		name string
+	}{
+		{
+			name: "something wrong",
+			args: args{
+				ctx:   context.Background(),
+				mType: models.GaugeType,
+				mName: "metric 1",
+				from:  from,
+				to:    to,
+			},
+			want: want{
+				err:     errors.New("something wrong"),
+				metrics: nil,
+			},
+			mock: func() {
+				mock.
+					ExpectQuery(query).
+					WithArgs("metric 1", models.GaugeType, "{}", from, to).
+					WillReturnError(errors.New("something wrong"))
+			},
+		},
+		{
+			name: "successfully case",
+			args: args{
+				ctx:   context.Background(),
+				mType: models.GaugeType,
+				mName: "metric 1",
+				from:  from,
+				to:    to,
+			},
+			want: want{
+				err: nil,
+				metrics: &models.MetricsList{
+					{
+						ID:        "metric 1",
+						MType:     models.GaugeType,
+						Value:     &value,
+						Timestamp: &ts1,
+					},
+					{
+						ID:        "metric 1",
+						MType:     models.GaugeType,
+						Value:     &v2,
+						Timestamp: &ts2,
+					},
+				},
+			},
+			mock: func() {
+				rows := sqlxmock.NewRows([]string{"name", "type", "delta", "value", "timestamp"}).
+					AddRow("metric 1", "gauge", nil, 100.1, ts1).
+					AddRow("metric 1", "gauge", nil, 200.2, ts2)
+				mock.
+					ExpectQuery(query).
+					WithArgs("metric 1", models.GaugeType, "{}", from, to).
+					WillReturnRows(rows)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+			got, err := s.GetMetricHistory(test.args.ctx, test.args.mType, test.args.mName, nil, test.args.from, test.args.to)
+			require.Equal(t, test.want.err, err)
+			assert.Equal(t, test.want.metrics, got)
+		})
+	}
+}
+
 func TestStore_Clear(t *testing.T) {
 	db, mock, err := sqlxmock.Newx()
 	if err != nil {
@@ -307,6 +442,9 @@ func TestStore_Clear(t *testing.T) {
 				mock.
 					ExpectExec("DELETE FROM metrics WHERE id > 0").
 					WillReturnResult(sqlxmock.NewResult(0, 0))
+				mock.
+					ExpectExec("DELETE FROM metrics_history WHERE id > 0").
+					WillReturnResult(sqlxmock.NewResult(0, 0))
 			},
 			args: args{
 				ctx: context.Background(),
@@ -397,6 +535,10 @@ func TestStore_Save(t *testing.T) {
 }
 
 func TestStore_UpdateMetrics(t *testing.T) {
+	const (
+		updateQuery  = "^INSERT INTO metrics \\(name, type, labels, delta, value, histogram, summary, timestamp, last_seen, agent_id\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7, \\$8, \\$9, \\$10\\) ON CONFLICT\\(name, type, labels\\) DO UPDATE SET delta = \\$4, value = \\$5, histogram = \\$6, summary = \\$7, timestamp = \\$8, last_seen = \\$9, agent_id = \\$10$"
+		historyQuery = "^INSERT INTO metrics_history \\(name, type, labels, delta, value, histogram, summary, timestamp\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7, \\$8\\)$"
+	)
 	db, mock, err := sqlxmock.Newx()
 	if err != nil {
 		panic(err)
@@ -453,7 +595,7 @@ func TestStore_UpdateMetrics(t *testing.T) {
 					ExpectBegin().
 					WillReturnError(nil)
 				mock.
-					ExpectPrepare("^INSERT INTO metrics \\(name, type, delta, value\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) ON CONFLICT\\(name, type\\) DO UPDATE SET delta = \\$3, value = \\$4$").
+					ExpectPrepare(updateQuery).
 					WillReturnError(errors.New("something wrong"))
 			},
 			args: args{
@@ -473,10 +615,13 @@ func TestStore_UpdateMetrics(t *testing.T) {
 					ExpectBegin().
 					WillReturnError(nil)
 				mock.
-					ExpectPrepare("^INSERT INTO metrics \\(name, type, delta, value\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) ON CONFLICT\\(name, type\\) DO UPDATE SET delta = \\$3, value = \\$4$").
+					ExpectPrepare(updateQuery).
 					WillReturnError(nil)
 				mock.
-					ExpectExec("^INSERT INTO metrics \\(name, type, delta, value\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) ON CONFLICT\\(name, type\\) DO UPDATE SET delta = \\$3, value = \\$4$").
+					ExpectPrepare(historyQuery).
+					WillReturnError(nil)
+				mock.
+					ExpectExec(updateQuery).
 					WillReturnError(errors.New("something wrong"))
 				mock.
 					ExpectRollback().
@@ -503,10 +648,13 @@ func TestStore_UpdateMetrics(t *testing.T) {
 					ExpectBegin().
 					WillReturnError(nil)
 				mock.
-					ExpectPrepare("^INSERT INTO metrics \\(name, type, delta, value\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) ON CONFLICT\\(name, type\\) DO UPDATE SET delta = \\$3, value = \\$4$").
+					ExpectPrepare(updateQuery).
+					WillReturnError(nil)
+				mock.
+					ExpectPrepare(historyQuery).
 					WillReturnError(nil)
 				mock.
-					ExpectExec("^INSERT INTO metrics \\(name, type, delta, value\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) ON CONFLICT\\(name, type\\) DO UPDATE SET delta = \\$3, value = \\$4$").
+					ExpectExec(updateQuery).
 					WillReturnError(errors.New("something wrong"))
 				mock.
 					ExpectRollback().
@@ -533,10 +681,16 @@ func TestStore_UpdateMetrics(t *testing.T) {
 					ExpectBegin().
 					WillReturnError(nil)
 				mock.
-					ExpectPrepare("^INSERT INTO metrics \\(name, type, delta, value\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) ON CONFLICT\\(name, type\\) DO UPDATE SET delta = \\$3, value = \\$4$").
+					ExpectPrepare(updateQuery).
 					WillReturnError(nil)
 				mock.
-					ExpectExec("^INSERT INTO metrics \\(name, type, delta, value\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) ON CONFLICT\\(name, type\\) DO UPDATE SET delta = \\$3, value = \\$4$").
+					ExpectPrepare(historyQuery).
+					WillReturnError(nil)
+				mock.
+					ExpectExec(updateQuery).
+					WillReturnResult(sqlxmock.NewResult(1, 1))
+				mock.
+					ExpectExec(historyQuery).
 					WillReturnResult(sqlxmock.NewResult(1, 1))
 				mock.
 					ExpectCommit().
@@ -563,10 +717,16 @@ func TestStore_UpdateMetrics(t *testing.T) {
 					ExpectBegin().
 					WillReturnError(nil)
 				mock.
-					ExpectPrepare("^INSERT INTO metrics \\(name, type, delta, value\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) ON CONFLICT\\(name, type\\) DO UPDATE SET delta = \\$3, value = \\$4$").
+					ExpectPrepare(updateQuery).
 					WillReturnError(nil)
 				mock.
-					ExpectExec("^INSERT INTO metrics \\(name, type, delta, value\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) ON CONFLICT\\(name, type\\) DO UPDATE SET delta = \\$3, value = \\$4$").
+					ExpectPrepare(historyQuery).
+					WillReturnError(nil)
+				mock.
+					ExpectExec(updateQuery).
+					WillReturnResult(sqlxmock.NewResult(1, 1))
+				mock.
+					ExpectExec(historyQuery).
 					WillReturnResult(sqlxmock.NewResult(1, 1))
 				mock.
 					ExpectCommit().
@@ -586,6 +746,45 @@ func TestStore_UpdateMetrics(t *testing.T) {
 				err: nil,
 			},
 		},
+		{
+			name: "histogram is stored as JSON",
+			mock: func() {
+				histogram := `{"bounds":[0.1],"counts":[1,2],"sum":4.5}`
+				mock.
+					ExpectBegin().
+					WillReturnError(nil)
+				mock.
+					ExpectPrepare(updateQuery).
+					WillReturnError(nil)
+				mock.
+					ExpectPrepare(historyQuery).
+					WillReturnError(nil)
+				mock.
+					ExpectExec(updateQuery).
+					WithArgs("latency", models.HistogramType, "{}", nil, nil, histogram, nil, sqlxmock.AnyArg(), nil, "").
+					WillReturnResult(sqlxmock.NewResult(1, 1))
+				mock.
+					ExpectExec(historyQuery).
+					WithArgs("latency", models.HistogramType, "{}", nil, nil, histogram, nil, sqlxmock.AnyArg()).
+					WillReturnResult(sqlxmock.NewResult(1, 1))
+				mock.
+					ExpectCommit().
+					WillReturnError(nil)
+			},
+			args: args{
+				ctx: context.Background(),
+				metrics: models.MetricsList{
+					&models.Metric{
+						Histogram: &models.Histogram{Bounds: []float64{0.1}, Counts: []uint64{1, 2}, Sum: 4.5},
+						ID:        "latency",
+						MType:     models.HistogramType,
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
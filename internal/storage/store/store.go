@@ -27,8 +27,11 @@ type Store interface {
 	// GetAllMetrics returns the list of all metrics.
 	GetAllMetrics(ctx context.Context) (*models.MetricsList, error)
 
-	// GetMetric returns an object Metric. Returns nil,nil if metric not found.
-	GetMetric(ctx context.Context, mType models.MetricType, mName string) (*models.Metric, error)
+	// GetMetric returns an object Metric with exactly the specified labels. Returns nil,nil if metric not found.
+	GetMetric(ctx context.Context, mType models.MetricType, mName string, labels models.Labels) (*models.Metric, error)
+
+	// GetMetricHistory returns samples of the metric recorded in the time window [from, to], ordered by time.
+	GetMetricHistory(ctx context.Context, mType models.MetricType, mName string, labels models.Labels, from, to time.Time) (*models.MetricsList, error)
 
 	// Ping checks the connection to the storage.
 	Ping(ctx context.Context) error
@@ -57,11 +60,11 @@ func NewStore(ctx context.Context, cfg *storage.Config) (Store, error) {
 	)
 	switch cfg.Type {
 	case storage.TypePostgres:
-		store, err = sql.NewStore("pgx", cfg.Path)
+		store, err = sql.NewStore("pgx", cfg.Path, cfg.HistoryRetention)
 	case storage.TypeMemory:
 		fallthrough
 	default:
-		store, _ = memory.NewStore(ctx, cfg.Path, cfg.SyncMode)
+		store, _ = memory.NewStore(ctx, cfg.Path, cfg.SyncMode, cfg.HistoryRetention)
 		go autoSave(ctx, store, cfg.Interval)
 	}
 
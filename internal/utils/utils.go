@@ -20,6 +20,18 @@ func RetryFunc(ctx context.Context, retryableFunc retry.RetryableFunc) error {
 	)
 }
 
+// RetryFuncWithBackoff re-calls the specified method up to attempts times doubling the delay after each failed call.
+func RetryFuncWithBackoff(ctx context.Context, retryableFunc retry.RetryableFunc, attempts uint, delay time.Duration) error {
+	return retry.Do(
+		retryableFunc,
+		retry.Attempts(attempts),
+		retry.Delay(delay),
+		retry.DelayType(retry.BackOffDelay),
+		retry.LastErrorOnly(true),
+		retry.Context(ctx),
+	)
+}
+
 // GetMD5Hash returns md5-sum of string.
 func GetMD5Hash(text string) string {
 	hash := md5.Sum([]byte(text))
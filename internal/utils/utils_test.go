@@ -2,9 +2,11 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func ExampleRetryFunc() {
@@ -17,6 +19,53 @@ func ExampleRetryFunc() {
 	}
 }
 
+func TestRetryFuncWithBackoff(t *testing.T) {
+	type args struct {
+		failures int
+		attempts uint
+	}
+	type want struct {
+		err   error
+		calls int
+	}
+	errSomethingWrong := errors.New("something wrong")
+	tests := []struct {
+		want want
+		name string
+		args args
+	}{
+		{
+			name: "Successfully case without retries",
+			args: args{failures: 0, attempts: 3},
+			want: want{calls: 1},
+		},
+		{
+			name: "Successfully case after retries",
+			args: args{failures: 2, attempts: 3},
+			want: want{calls: 3},
+		},
+		{
+			name: "All attempts failed",
+			args: args{failures: 5, attempts: 3},
+			want: want{calls: 3, err: errSomethingWrong},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			err := RetryFuncWithBackoff(context.Background(), func() error {
+				calls++
+				if calls <= test.args.failures {
+					return errSomethingWrong
+				}
+				return nil
+			}, test.args.attempts, time.Millisecond)
+			assert.Equal(t, test.want.err, err)
+			assert.Equal(t, test.want.calls, calls)
+		})
+	}
+}
+
 func TestGetMD5Hash(t *testing.T) {
 	type args struct {
 		text string